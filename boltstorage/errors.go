@@ -0,0 +1,7 @@
+package boltstorage
+
+import "errors"
+
+var (
+	ErrSchemaVersion = errors.New("database schema is newer than supported")
+)
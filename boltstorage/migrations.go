@@ -0,0 +1,109 @@
+package boltstorage
+
+import (
+	"encoding/binary"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	metaBucket       = []byte("meta")
+	schemaVersionKey = []byte("schema_version")
+)
+
+// Migration upgrades the schema by one version inside the migration transaction
+type Migration func(tx *bolt.Tx) error
+
+// migrations[i] upgrades schema version i to i+1, append new migrations to the end and never
+// change released ones
+var migrations = []Migration{
+	createBuckets,
+	createContactsBucket,
+	createSettingsBucket,
+	createBalancesBucket,
+}
+
+// SchemaVersion returns the schema version of the opened database
+func (s *Storage) SchemaVersion() (uint64, error) {
+	var version uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		version = schemaVersion(tx)
+		return nil
+	})
+	return version, err
+}
+
+// migrate applies missing migrations, each in its own transaction together with the version bump.
+// Databases written by newer versions are refused.
+func (s *Storage) migrate() error {
+	for {
+		done := false
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			version := schemaVersion(tx)
+			if version > uint64(len(s.migrations)) {
+				return fmt.Errorf("%w: version %d, supported %d", ErrSchemaVersion, version, len(s.migrations))
+			}
+			if version == uint64(len(s.migrations)) {
+				done = true
+				return nil
+			}
+			if err := s.migrations[version](tx); err != nil {
+				return fmt.Errorf("migrate schema to version %d: %w", version+1, err)
+			}
+			return setSchemaVersion(tx, version+1)
+		})
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+func schemaVersion(tx *bolt.Tx) uint64 {
+	bucket := tx.Bucket(metaBucket)
+	if bucket == nil {
+		return 0
+	}
+	value := bucket.Get(schemaVersionKey)
+	if len(value) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+func setSchemaVersion(tx *bolt.Tx, version uint64) error {
+	bucket, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, version)
+	return bucket.Put(schemaVersionKey, value)
+}
+
+// createBuckets is version 1 schema
+func createBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{accountsBucket, addressesBucket, labelsBucket, transactionsBucket, cursorsBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createContactsBucket is version 2 schema adding the address book
+func createContactsBucket(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(contactsBucket)
+	return err
+}
+
+// createSettingsBucket is version 3 schema adding the spending policy MAC
+func createSettingsBucket(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(settingsBucket)
+	return err
+}
+
+// createBalancesBucket is version 4 schema adding last known balances
+func createBalancesBucket(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(balancesBucket)
+	return err
+}
@@ -0,0 +1,284 @@
+package boltstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mcmx73/easytron/wallet"
+	bolt "go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// wallet.Storage kept in a single bbolt database file
+
+var (
+	accountsBucket     = []byte("accounts")
+	addressesBucket    = []byte("addresses")
+	labelsBucket       = []byte("labels")
+	transactionsBucket = []byte("transactions")
+	cursorsBucket      = []byte("sync_cursors")
+	contactsBucket     = []byte("contacts")
+	settingsBucket     = []byte("settings")
+	balancesBucket     = []byte("balances")
+
+	policyMacKey = []byte("policy_mac")
+)
+
+const (
+	keySeparator = "/"
+	openTimeout  = time.Second
+)
+
+type WithOption func(*Storage)
+
+// WithMigrations replaces schema migrations, used by tests
+func WithMigrations(migrations []Migration) WithOption {
+	return func(s *Storage) {
+		s.migrations = migrations
+	}
+}
+
+// Open opens or creates the database file and migrates it to the current schema version. The file
+// is locked, a second daemon on the same file fails to open it after a timeout.
+func Open(path string, options ...WithOption) (*Storage, error) {
+	s := &Storage{migrations: migrations}
+	for _, opt := range options {
+		opt(s)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	s.db = db
+	if err = s.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+type Storage struct {
+	db         *bolt.DB
+	migrations []Migration
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) SaveAccount(account *wallet.Account) error {
+	return s.put(accountsBucket, storageKey(account.CoinId, account.Address), account)
+}
+
+func (s *Storage) DeleteAccount(coinId wallet.CoinId, address string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(accountsBucket).Delete(storageKey(coinId, address))
+	})
+}
+
+func (s *Storage) Accounts() ([]*wallet.Account, error) {
+	var accounts []*wallet.Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(accountsBucket).ForEach(func(_, value []byte) error {
+			account := &wallet.Account{}
+			if err := json.Unmarshal(value, account); err != nil {
+				return err
+			}
+			accounts = append(accounts, account)
+			return nil
+		})
+	})
+	return accounts, err
+}
+
+func (s *Storage) SaveAddress(address *wallet.DiscoveredAddress) error {
+	return s.put(addressesBucket, storageKey(address.CoinId, address.Address), address)
+}
+
+// Addresses returns derived addresses of the coin ordered by account and index
+func (s *Storage) Addresses(coinId wallet.CoinId) ([]*wallet.DiscoveredAddress, error) {
+	var addresses []*wallet.DiscoveredAddress
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := storageKey(coinId, "")
+		cursor := tx.Bucket(addressesBucket).Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			address := &wallet.DiscoveredAddress{}
+			if err := json.Unmarshal(value, address); err != nil {
+				return err
+			}
+			addresses = append(addresses, address)
+		}
+		return nil
+	})
+	wallet.SortAddresses(addresses)
+	return addresses, err
+}
+
+func (s *Storage) SetLabel(address, label string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(labelsBucket)
+		if label == "" {
+			return bucket.Delete([]byte(address))
+		}
+		return bucket.Put([]byte(address), []byte(label))
+	})
+}
+
+func (s *Storage) Labels() (map[string]string, error) {
+	labels := make(map[string]string)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(labelsBucket).ForEach(func(key, value []byte) error {
+			labels[string(key)] = string(value)
+			return nil
+		})
+	})
+	return labels, err
+}
+
+func (s *Storage) SaveContact(contact *wallet.Contact) error {
+	return s.put(contactsBucket, []byte(contact.Id), contact)
+}
+
+func (s *Storage) DeleteContact(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(contactsBucket).Delete([]byte(id))
+	})
+}
+
+func (s *Storage) Contacts() ([]*wallet.Contact, error) {
+	var contacts []*wallet.Contact
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(contactsBucket).ForEach(func(_, value []byte) error {
+			contact := &wallet.Contact{}
+			if err := json.Unmarshal(value, contact); err != nil {
+				return err
+			}
+			contacts = append(contacts, contact)
+			return nil
+		})
+	})
+	return contacts, err
+}
+
+// SaveTransactions keeps transactions of every address in a nested bucket keyed by hash
+func (s *Storage) SaveTransactions(coinId wallet.CoinId, address string, transactions []*wallet.Transaction) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(transactionsBucket).CreateBucketIfNotExists(storageKey(coinId, address))
+		if err != nil {
+			return err
+		}
+		for _, transaction := range transactions {
+			data, err := json.Marshal(transaction)
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put([]byte(transaction.Hash), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Storage) Transactions(coinId wallet.CoinId, address string) ([]*wallet.Transaction, error) {
+	var transactions []*wallet.Transaction
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(transactionsBucket).Bucket(storageKey(coinId, address))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			transaction := &wallet.Transaction{}
+			if err := json.Unmarshal(value, transaction); err != nil {
+				return err
+			}
+			transactions = append(transactions, transaction)
+			return nil
+		})
+	})
+	wallet.SortTransactions(transactions)
+	return transactions, err
+}
+
+func (s *Storage) DeleteTransaction(coinId wallet.CoinId, address, hash string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(transactionsBucket).Bucket(storageKey(coinId, address))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(hash))
+	})
+}
+
+func (s *Storage) SetSyncCursor(coinId wallet.CoinId, address, cursor string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cursorsBucket).Put(storageKey(coinId, address), []byte(cursor))
+	})
+}
+
+func (s *Storage) SyncCursor(coinId wallet.CoinId, address string) (string, error) {
+	var cursor string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor = string(tx.Bucket(cursorsBucket).Get(storageKey(coinId, address)))
+		return nil
+	})
+	return cursor, err
+}
+
+func (s *Storage) SetBalances(coinId wallet.CoinId, address string, balances map[string]wallet.Amount) error {
+	if balances == nil {
+		balances = map[string]wallet.Amount{}
+	}
+	return s.put(balancesBucket, storageKey(coinId, address), balances)
+}
+
+func (s *Storage) Balances(coinId wallet.CoinId, address string) (map[string]wallet.Amount, error) {
+	var balances map[string]wallet.Amount
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(balancesBucket).Get(storageKey(coinId, address))
+		if value == nil {
+			return nil
+		}
+		return json.Unmarshal(value, &balances)
+	})
+	return balances, err
+}
+
+func (s *Storage) SetPolicyMac(mac []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if len(mac) == 0 {
+			return tx.Bucket(settingsBucket).Delete(policyMacKey)
+		}
+		return tx.Bucket(settingsBucket).Put(policyMacKey, mac)
+	})
+}
+
+func (s *Storage) PolicyMac() ([]byte, error) {
+	var mac []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(settingsBucket).Get(policyMacKey); value != nil {
+			mac = append([]byte(nil), value...)
+		}
+		return nil
+	})
+	return mac, err
+}
+
+func (s *Storage) put(bucket, key []byte, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, data)
+	})
+}
+
+// storageKey is "<coin id>/<address>", coin ids don't contain the separator
+func storageKey(coinId wallet.CoinId, address string) []byte {
+	return []byte(string(coinId) + keySeparator + address)
+}
@@ -0,0 +1,140 @@
+package boltstorage
+
+import (
+	"errors"
+	"github.com/mcmx73/easytron/wallet"
+	bolt "go.etcd.io/bbolt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStorageReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet", "wallet.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := &wallet.Account{CoinId: "trx", Address: "TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY", Label: "cold", WatchOnly: true}
+	if err = s.SaveAccount(account); err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range []uint32{1, 0} {
+		address := &wallet.DiscoveredAddress{CoinId: "eth", Account: 0, Index: index, Address: string(rune('a' + index))}
+		if err = s.SaveAddress(address); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.SetLabel("0xabc", "exchange"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.SaveContact(&wallet.Contact{Id: "1", Name: "Alice", Tags: []string{"friends"}}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	transactions := []*wallet.Transaction{
+		{Hash: "old", CreatedAt: now.Add(-time.Hour), Amount: wallet.NewAmountFromUint64(1500000, 6)},
+		{Hash: "new", CreatedAt: now, Amount: wallet.NewAmountFromUint64(1, 6)},
+	}
+	if err = s.SaveTransactions("trx", account.Address, transactions); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.SetSyncCursor("trx", account.Address, "123"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.SetBalances("trx", account.Address, map[string]wallet.Amount{"trx": wallet.NewAmountFromUint64(2500000, 6)}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.SetPolicyMac([]byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	accounts, err := s.Accounts()
+	if err != nil || len(accounts) != 1 || *accounts[0] != *account {
+		t.Errorf("accounts %v, %v", accounts, err)
+	}
+	addresses, err := s.Addresses("eth")
+	if err != nil || len(addresses) != 2 || addresses[0].Index != 0 {
+		t.Errorf("addresses %v, %v", addresses, err)
+	}
+	if addresses, _ = s.Addresses("et"); len(addresses) != 0 {
+		t.Errorf("addresses of other coin %v", addresses)
+	}
+	if labels, _ := s.Labels(); labels["0xabc"] != "exchange" {
+		t.Errorf("labels %v", labels)
+	}
+	if contacts, _ := s.Contacts(); len(contacts) != 1 || contacts[0].Name != "Alice" || contacts[0].Tags[0] != "friends" {
+		t.Errorf("contacts %v", contacts)
+	}
+	cached, err := s.Transactions("trx", account.Address)
+	if err != nil || len(cached) != 2 || cached[0].Hash != "new" || cached[1].Amount.String() != "1.5" {
+		t.Errorf("transactions %v, %v", cached, err)
+	}
+	if cursor, _ := s.SyncCursor("trx", account.Address); cursor != "123" {
+		t.Errorf("cursor %q", cursor)
+	}
+	if balances, _ := s.Balances("trx", account.Address); balances["trx"].String() != "2.5" {
+		t.Errorf("balances %v", balances)
+	}
+	if balances, _ := s.Balances("trx", "TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZy"); balances != nil {
+		t.Errorf("balances %v of never refreshed address", balances)
+	}
+	if mac, _ := s.PolicyMac(); string(mac) != "\x01\x02\x03" {
+		t.Errorf("policy mac %x", mac)
+	}
+	if err = s.SetPolicyMac(nil); err != nil {
+		t.Fatal(err)
+	}
+	if mac, _ := s.PolicyMac(); mac != nil {
+		t.Errorf("policy mac %x after delete", mac)
+	}
+	if err = s.DeleteTransaction("trx", account.Address, "old"); err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ = s.Transactions("trx", account.Address); len(cached) != 1 || cached[0].Hash != "new" {
+		t.Errorf("transactions %v after delete", cached)
+	}
+	if err = s.DeleteAccount("trx", account.Address); err != nil {
+		t.Fatal(err)
+	}
+	if accounts, _ = s.Accounts(); len(accounts) != 0 {
+		t.Errorf("accounts %v after delete", accounts)
+	}
+}
+
+func TestStorageMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := s.SchemaVersion(); version != uint64(len(migrations)) {
+		t.Errorf("version %d, want %d", version, len(migrations))
+	}
+	_ = s.Close()
+
+	applied := false
+	upgraded := append(append([]Migration(nil), migrations...), func(tx *bolt.Tx) error {
+		applied = true
+		_, err := tx.CreateBucket([]byte("notes"))
+		return err
+	})
+	if s, err = Open(path, WithMigrations(upgraded)); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := s.SchemaVersion(); !applied || version != uint64(len(upgraded)) {
+		t.Errorf("version %d, migration applied %v", version, applied)
+	}
+	_ = s.Close()
+
+	if _, err = Open(path); !errors.Is(err, ErrSchemaVersion) {
+		t.Errorf("err %v, want %v", err, ErrSchemaVersion)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build darwin || linux
+
+package secret
+
+import "syscall"
+
+// alloc maps anonymous pages for the secret and locks them in RAM. When mmap
+// fails the bytes go to the heap, when mlock fails, e.g. over RLIMIT_MEMLOCK,
+// the pages are used unlocked.
+func alloc(size int) (data []byte, mapped, locked bool) {
+	if size == 0 {
+		return []byte{}, false, false
+	}
+	data, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, size), false, false
+	}
+	return data, true, syscall.Mlock(data) == nil
+}
+
+func free(data []byte, mapped, locked bool) {
+	if locked {
+		syscall.Munlock(data)
+	}
+	if mapped {
+		syscall.Munmap(data)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || linux)
+
+package secret
+
+// alloc keeps the secret on the heap, memory locking is not supported here
+func alloc(size int) (data []byte, mapped, locked bool) {
+	return make([]byte, size), false, false
+}
+
+func free(data []byte, mapped, locked bool) {}
@@ -0,0 +1,167 @@
+// Package secret keeps private keys, seeds and mnemonics in buffers which are
+// locked in memory where the platform allows it, zeroed on release and never
+// printed or marshaled by accident.
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	ErrNotExportable = errors.New("secret is not exportable, use Export")
+	ErrDestroyed     = errors.New("secret is destroyed")
+)
+
+// Buffer holds secret bytes. Bytes are kept outside of the Go heap and locked
+// in RAM when the platform supports it, so the garbage collector never copies
+// them and they are not swapped out. A Buffer must not be copied by value.
+// Unreachable buffers are destroyed by the garbage collector, access the bytes
+// with Use so the buffer stays alive and is not destroyed meanwhile.
+type Buffer struct {
+	mux    sync.RWMutex
+	data   []byte
+	mapped bool
+	locked bool
+}
+
+// New allocates zeroed buffer of size bytes
+func New(size int) *Buffer {
+	b := &Buffer{}
+	b.data, b.mapped, b.locked = alloc(size)
+	runtime.SetFinalizer(b, (*Buffer).Destroy)
+	return b
+}
+
+// FromBytes moves src into new buffer, src is zeroed
+func FromBytes(src []byte) *Buffer {
+	b := New(len(src))
+	copy(b.data, src)
+	Wipe(src)
+	return b
+}
+
+// FromString copies s into new buffer. Go strings are immutable and can not be
+// wiped, prefer FromBytes when the secret is available as bytes.
+func FromString(s string) *Buffer {
+	b := New(len(s))
+	copy(b.data, s)
+	return b
+}
+
+// Use calls fn with the secret bytes without copying. Destroy waits until fn returns,
+// fn must not retain the slice or call other methods of the buffer.
+func (b *Buffer) Use(fn func(data []byte) error) error {
+	if b == nil {
+		return ErrDestroyed
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	if b.data == nil {
+		return ErrDestroyed
+	}
+	err := fn(b.data)
+	runtime.KeepAlive(b)
+	return err
+}
+
+// Len returns the size of the secret, zero after Destroy
+func (b *Buffer) Len() int {
+	if b == nil {
+		return 0
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return len(b.data)
+}
+
+// Locked reports whether the bytes are locked in RAM
+func (b *Buffer) Locked() bool {
+	if b == nil {
+		return false
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return b.locked
+}
+
+// Destroyed reports whether the buffer was released
+func (b *Buffer) Destroyed() bool {
+	if b == nil {
+		return true
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return b.data == nil
+}
+
+// Export returns a copy of the secret for an explicit export, like a backup or
+// an encrypted key file. The caller owns the copy and should Wipe it.
+func (b *Buffer) Export() []byte {
+	var exported []byte
+	_ = b.Use(func(data []byte) error {
+		exported = append([]byte{}, data...)
+		return nil
+	})
+	return exported
+}
+
+// Destroy zeroes and releases the bytes, it is safe to call more than once
+func (b *Buffer) Destroy() {
+	if b == nil {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.data == nil {
+		return
+	}
+	Wipe(b.data)
+	free(b.data, b.mapped, b.locked)
+	b.data = nil
+	runtime.SetFinalizer(b, nil)
+}
+
+func (b *Buffer) String() string {
+	return redacted
+}
+
+func (b *Buffer) GoString() string {
+	return redacted
+}
+
+// Format prints the buffer as redacted for every verb
+func (b *Buffer) Format(f fmt.State, verb rune) {
+	f.Write([]byte(redacted))
+}
+
+// MarshalJSON always fails, secrets are written to JSON only after Export
+func (b *Buffer) MarshalJSON() ([]byte, error) {
+	return nil, ErrNotExportable
+}
+
+// MarshalText always fails, secrets are written as text only after Export
+func (b *Buffer) MarshalText() ([]byte, error) {
+	return nil, ErrNotExportable
+}
+
+// Wipe zeroes b
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
+
+// WipeStrings clears the words of a mnemonic. Words of generated mnemonics point
+// to the static wordlist, so clearing the slice drops the only copy of their order.
+func WipeStrings(s []string) {
+	for i := range s {
+		s[i] = ""
+	}
+	runtime.KeepAlive(s)
+}
@@ -0,0 +1,102 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBuffer(t *testing.T) {
+	src := []byte("correct horse battery staple")
+	want := append([]byte{}, src...)
+	b := FromBytes(src)
+	if !bytes.Equal(src, make([]byte, len(src))) {
+		t.Error("source is not wiped")
+	}
+	if exported := b.Export(); !bytes.Equal(exported, want) || b.Len() != len(want) {
+		t.Fatalf("buffer holds %q", exported)
+	}
+	exported := b.Export()
+	exported[0] = 'x'
+	if b.data[0] != want[0] {
+		t.Error("export is not a copy")
+	}
+	errUse := errors.New("use")
+	if err := b.Use(func(data []byte) error {
+		if !bytes.Equal(data, want) {
+			t.Errorf("use got %q", data)
+		}
+		return errUse
+	}); err != errUse {
+		t.Errorf("err %v, want %v", err, errUse)
+	}
+	data := b.data
+	using, destroyed := make(chan struct{}), make(chan struct{})
+	go func() {
+		<-using
+		b.Destroy()
+		close(destroyed)
+	}()
+	_ = b.Use(func(data []byte) error {
+		close(using)
+		select {
+		case <-destroyed:
+			t.Error("buffer is destroyed while in use")
+		case <-time.After(20 * time.Millisecond):
+		}
+		return nil
+	})
+	<-destroyed
+	b.Destroy()
+	if !b.Destroyed() || b.data != nil || b.Export() != nil {
+		t.Error("buffer is not released")
+	}
+	if err := b.Use(func([]byte) error { return nil }); !errors.Is(err, ErrDestroyed) {
+		t.Errorf("err %v, want %v", err, ErrDestroyed)
+	}
+	if b.mapped {
+		return
+	}
+	if !bytes.Equal(data, make([]byte, len(data))) {
+		t.Error("heap buffer is not wiped")
+	}
+}
+
+func TestBufferRedacted(t *testing.T) {
+	b := FromString("secret-seed")
+	defer b.Destroy()
+	wrapper := struct {
+		Name string
+		Seed *Buffer
+	}{"wallet", b}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%x", "%q"} {
+		if out := fmt.Sprintf(format, b); out != redacted {
+			t.Errorf("%s prints %s", format, out)
+		}
+		if out := fmt.Sprintf(format, wrapper); bytes.Contains([]byte(out), []byte("secret-seed")) {
+			t.Errorf("%s of struct prints %s", format, out)
+		}
+	}
+	if _, err := json.Marshal(wrapper); !errors.Is(err, ErrNotExportable) {
+		t.Errorf("expected ErrNotExportable, got %v", err)
+	}
+	if _, err := json.Marshal(map[*Buffer]int{b: 1}); err == nil {
+		t.Error("buffer is marshaled as map key")
+	}
+}
+
+func TestWipe(t *testing.T) {
+	b := []byte{1, 2, 3}
+	Wipe(b)
+	if !bytes.Equal(b, []byte{0, 0, 0}) {
+		t.Errorf("wiped %v", b)
+	}
+	words := []string{"abandon", "about"}
+	WipeStrings(words)
+	if words[0] != "" || words[1] != "" {
+		t.Errorf("wiped %v", words)
+	}
+}
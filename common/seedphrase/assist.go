@@ -0,0 +1,266 @@
+package seedphrase
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/mcmx73/easytron/common/secret"
+	"golang.org/x/text/unicode/norm"
+	"sort"
+	"strings"
+)
+
+// Mnemonic entry assistance for restore screens: prefix completion, typo suggestions
+// and checksum corrections
+
+// WordError points to the invalid word of the mnemonic looked up in the Language wordlist.
+// The word itself is not included in the message, errors may end up in logs.
+type WordError struct {
+	Index    int
+	Word     string
+	Language Language
+	Err      error
+}
+
+func (e *WordError) Error() string {
+	return fmt.Sprintf("word %d: %v", e.Index+1, e.Err)
+}
+
+func (e *WordError) Unwrap() error {
+	return e.Err
+}
+
+// WordCorrection replaces the word at Index of the mnemonic
+type WordCorrection struct {
+	Index int    `json:"index"`
+	Word  string `json:"word"`
+}
+
+// CompleteWord returns the word of the language starting with prefix. BIP39 words are unique
+// in their first 4 letters, shorter prefixes are completed when only one word matches.
+func CompleteWord(prefix string, language Language) (string, error) {
+	list, err := wordlistOf(language)
+	if err != nil {
+		return "", err
+	}
+	return list.complete(prefix)
+}
+
+func (l *wordlist) complete(prefix string) (string, error) {
+	prefix = norm.NFKD.String(strings.ToLower(prefix))
+	if index, ok := l.index[prefix]; ok {
+		return l.words[index], nil
+	}
+	if prefix == "" {
+		return "", ErrInvalidWord
+	}
+	var completion string
+	for _, word := range l.words {
+		if strings.HasPrefix(word, prefix) {
+			if completion != "" {
+				return "", ErrAmbiguousPrefix
+			}
+			completion = word
+		}
+	}
+	if completion == "" {
+		return "", ErrInvalidWord
+	}
+	return completion, nil
+}
+
+// CompleteMnemonic completes prefixes of the mnemonic words and detects the language. The
+// language with valid checksum is preferred when words complete in several wordlists.
+// Otherwise *WordError points to the first word which can't be completed.
+func CompleteMnemonic(mnemonic []string) ([]string, Language, error) {
+	var found []string
+	var foundList *wordlist
+	var wordErr *WordError
+	for _, list := range wordlists {
+		completed := make([]string, len(mnemonic))
+		var err *WordError
+		for i, word := range mnemonic {
+			full, completeErr := list.complete(word)
+			if completeErr != nil {
+				err = &WordError{Index: i, Word: word, Language: list.language, Err: completeErr}
+				break
+			}
+			completed[i] = full
+		}
+		if err != nil {
+			if wordErr == nil || err.Index > wordErr.Index {
+				wordErr = err
+			}
+			continue
+		}
+		if validateMnemonicWordCount(completed) == nil && checksumValid(completed, list) {
+			return completed, list.language, nil
+		}
+		if found == nil {
+			found, foundList = completed, list
+		}
+	}
+	if found == nil {
+		return nil, "", wordErr
+	}
+	return found, foundList.language, nil
+}
+
+// SuggestWords returns up to limit words of the language nearest to the mistyped word by edit
+// distance, words starting with it come first
+func SuggestWords(word string, language Language, limit int) ([]string, error) {
+	list, err := wordlistOf(language)
+	if err != nil {
+		return nil, err
+	}
+	typed := []rune(norm.NFKD.String(strings.ToLower(word)))
+	type candidate struct {
+		word     string
+		distance int
+	}
+	candidates := make([]candidate, 0, len(list.words))
+	for _, w := range list.words {
+		distance := editDistance(typed, []rune(w))
+		if strings.HasPrefix(w, string(typed)) {
+			distance = 0
+		}
+		candidates = append(candidates, candidate{w, distance})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	if limit > len(candidates) {
+		limit = len(candidates)
+	}
+	suggestions := make([]string, limit)
+	for i := range suggestions {
+		suggestions[i] = candidates[i].word
+	}
+	return suggestions, nil
+}
+
+// ChecksumCorrections returns up to limit single word substitutions which make the checksum of
+// the mnemonic valid. About 1 of 2^(words/3) substitutions passes, so candidates nearest
+// to the replaced word by edit distance come first.
+func ChecksumCorrections(mnemonic []string, language Language, limit int) ([]WordCorrection, error) {
+	list, err := wordlistOf(language)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateMnemonicWordCount(mnemonic); err != nil {
+		return nil, err
+	}
+	indexes, err := list.indexes(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeIndexes(indexes)
+	type candidate struct {
+		WordCorrection
+		distance int
+	}
+	var candidates []candidate
+	for i, original := range indexes {
+		typed := []rune(list.words[original])
+		for index := range list.words {
+			indexes[i] = uint16(index)
+			if uint16(index) != original && indexesChecksumValid(indexes) {
+				word := list.words[index]
+				candidates = append(candidates, candidate{WordCorrection{i, word}, editDistance(typed, []rune(word))})
+			}
+		}
+		indexes[i] = original
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	if limit > len(candidates) {
+		limit = len(candidates)
+	}
+	corrections := make([]WordCorrection, limit)
+	for i := range corrections {
+		corrections[i] = candidates[i].WordCorrection
+	}
+	return corrections, nil
+}
+
+func (l *wordlist) indexes(mnemonic []string) ([]uint16, error) {
+	indexes := make([]uint16, len(mnemonic))
+	for i, word := range mnemonic {
+		index, ok := l.lookup(word)
+		if !ok {
+			wipeIndexes(indexes)
+			return nil, &WordError{Index: i, Word: word, Language: l.language, Err: ErrInvalidWord}
+		}
+		indexes[i] = index
+	}
+	return indexes, nil
+}
+
+func checksumValid(mnemonic []string, list *wordlist) bool {
+	indexes, err := list.indexes(mnemonic)
+	if err != nil {
+		return false
+	}
+	defer wipeIndexes(indexes)
+	return indexesChecksumValid(indexes)
+}
+
+// indexesChecksumValid packs 11-bit word indexes and checks the trailing words/3 checksum bits
+func indexesChecksumValid(indexes []uint16) bool {
+	var packed [33]byte
+	defer secret.Wipe(packed[:])
+	for i, index := range indexes {
+		for bit := 0; bit < 11; bit++ {
+			if index&(1<<uint(10-bit)) != 0 {
+				offset := i*11 + bit
+				packed[offset/8] |= 0x80 >> uint(offset%8)
+			}
+		}
+	}
+	checksumBits := len(indexes) / 3
+	entropyLength := len(indexes) * 4 / 3
+	hash := sha256.Sum256(packed[:entropyLength])
+	defer secret.Wipe(hash[:])
+	return hash[0]>>uint(8-checksumBits) == packed[entropyLength]>>uint(8-checksumBits)
+}
+
+func wipeIndexes(indexes []uint16) {
+	for i := range indexes {
+		indexes[i] = 0
+	}
+}
+
+// editDistance is optimal string alignment distance of rune slices, Levenshtein distance
+// counting swapped adjacent letters as one edit
+func editDistance(a, b []rune) int {
+	rows := [3][]int{make([]int, len(b)+1), make([]int, len(b)+1), make([]int, len(b)+1)}
+	for j := range rows[1] {
+		rows[1][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		beforePrevious, previous, current := rows[0], rows[1], rows[2]
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = minInt(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		rows[0], rows[1], rows[2] = previous, current, beforePrevious
+	}
+	return rows[1][len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
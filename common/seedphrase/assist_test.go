@@ -0,0 +1,94 @@
+package seedphrase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompleteWord(t *testing.T) {
+	tests := []struct {
+		prefix string
+		word   string
+		err    error
+	}{
+		{"aban", "abandon", nil},
+		{"ABOU", "about", nil},
+		{"act", "act", nil},
+		{"ab", "", ErrAmbiguousPrefix},
+		{"qqq", "", ErrInvalidWord},
+		{"", "", ErrInvalidWord},
+	}
+	for _, test := range tests {
+		word, err := CompleteWord(test.prefix, LanguageEnglish)
+		if word != test.word || !errors.Is(err, test.err) {
+			t.Errorf("%q: got %q, %v, want %q, %v", test.prefix, word, err, test.word, test.err)
+		}
+	}
+}
+
+func TestCompleteMnemonic(t *testing.T) {
+	mnemonic, language, err := CompleteMnemonic(strings.Fields("lega winn than year wave saus wort usef legal winn than yell"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if language != LanguageEnglish {
+		t.Errorf("language %s", language)
+	}
+	if joined := strings.Join(mnemonic, " "); joined != "legal winner thank year wave sausage worth useful legal winner thank yellow" {
+		t.Errorf("mnemonic %s", joined)
+	}
+	_, _, err = CompleteMnemonic(strings.Fields("legal winner thank qqqq wave"))
+	var wordErr *WordError
+	if !errors.As(err, &wordErr) || wordErr.Index != 3 || !errors.Is(err, ErrInvalidWord) {
+		t.Errorf("err %v, want invalid word 4", err)
+	}
+}
+
+func TestMnemonicWordError(t *testing.T) {
+	_, err := Mnemonic2Bytes(strings.Fields("legal winner thank year wave sausage wroth useful legal winner thank yellow"))
+	var wordErr *WordError
+	if !errors.As(err, &wordErr) || wordErr.Index != 6 || wordErr.Word != "wroth" {
+		t.Fatalf("err %v, want invalid word 7", err)
+	}
+	if strings.Contains(err.Error(), "wroth") {
+		t.Errorf("error message %q contains the word", err)
+	}
+	suggestions, err := SuggestWords(wordErr.Word, LanguageEnglish, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 3 || suggestions[0] != "worth" {
+		t.Errorf("suggestions %q, want worth first", suggestions)
+	}
+	if suggestions, _ = SuggestWords("sausag", LanguageEnglish, 1); suggestions[0] != "sausage" {
+		t.Errorf("suggestions %q, want sausage", suggestions)
+	}
+}
+
+func TestChecksumCorrections(t *testing.T) {
+	mnemonic := strings.Fields("legal winner thank year wave sausage worth useful legal winner thank yellow")
+	mnemonic[11] = "yard"
+	if err := ValidateMnemonic(mnemonic); !errors.Is(err, ErrInvalidChecksum) {
+		t.Fatalf("err %v, want %v", err, ErrInvalidChecksum)
+	}
+	corrections, err := ChecksumCorrections(mnemonic, LanguageEnglish, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, correction := range corrections {
+		corrected := append([]string(nil), mnemonic...)
+		corrected[correction.Index] = correction.Word
+		if err := ValidateMnemonic(corrected); err != nil {
+			t.Fatalf("correction %v: %v", correction, err)
+		}
+		found = found || correction == WordCorrection{11, "yellow"}
+	}
+	if !found {
+		t.Error("yellow is not proposed")
+	}
+	if limited, _ := ChecksumCorrections(mnemonic, LanguageEnglish, 3); len(limited) != 3 {
+		t.Errorf("got %d corrections, want 3", len(limited))
+	}
+}
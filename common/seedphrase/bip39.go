@@ -0,0 +1,100 @@
+package seedphrase
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"github.com/mcmx73/easytron/common/secret"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// BIP39 mnemonic and seed derivation
+// See https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+
+const (
+	seedIterations = 2048
+	seedLength     = 64
+	seedSaltPrefix = "mnemonic"
+)
+
+// NewEntropy returns fresh random entropy for the mnemonic of 12, 15, 18, 21 or 24 words,
+// the caller should wipe it with secret.Wipe
+func NewEntropy(wordsCount int) ([]byte, error) {
+	entropyBitLength := wordsCount * 11 * 32 / 33
+	if wordsCount%3 != 0 {
+		return nil, ErrInvalidMnemonicWordCount
+	}
+	if err := validateBytesBitSize(entropyBitLength); err != nil {
+		return nil, ErrInvalidMnemonicWordCount
+	}
+	entropy := make([]byte, entropyBitLength/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic generates BIP39 mnemonic of wordsCount words from fresh entropy. The words point
+// to the static wordlist, clear the mnemonic with secret.WipeStrings after use.
+func NewMnemonic(wordsCount int) ([]string, error) {
+	entropy, err := NewEntropy(wordsCount)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(entropy)
+	return Bytes2Mnemonic(entropy)
+}
+
+// NewMnemonicInLanguage generates BIP39 mnemonic of wordsCount words of the language
+func NewMnemonicInLanguage(wordsCount int, language Language) ([]string, error) {
+	entropy, err := NewEntropy(wordsCount)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(entropy)
+	return Bytes2MnemonicInLanguage(entropy, language)
+}
+
+// ValidateMnemonic checks word count, words and checksum of the mnemonic
+func ValidateMnemonic(mnemonic []string) error {
+	entropy, err := Mnemonic2Bytes(mnemonic)
+	secret.Wipe(entropy)
+	return err
+}
+
+// MnemonicToSeed validates the mnemonic and derives 64-byte BIP39 seed from it
+func MnemonicToSeed(mnemonic []string, passphrase string) (*secret.Buffer, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	return NewSeed(mnemonic, passphrase), nil
+}
+
+// NewSeed derives 64-byte seed with PBKDF2-HMAC-SHA512 from NFKD normalized mnemonic sentence
+// and "mnemonic" + passphrase salt. The mnemonic is not validated. The sentence is built
+// in byte slices wiped after use, the seed is returned in secret buffer.
+func NewSeed(mnemonic []string, passphrase string) *secret.Buffer {
+	sentence := joinWords(mnemonic)
+	defer secret.Wipe(sentence)
+	normalized := norm.NFKD.Bytes(sentence)
+	defer secret.Wipe(normalized)
+	salt := norm.NFKD.Bytes([]byte(seedSaltPrefix + passphrase))
+	defer secret.Wipe(salt)
+	return secret.FromBytes(pbkdf2.Key(normalized, salt, seedIterations, seedLength, sha512.New))
+}
+
+// joinWords joins words with spaces into one allocation
+func joinWords(words []string) []byte {
+	size := 0
+	for _, word := range words {
+		size += len(word) + 1
+	}
+	sentence := make([]byte, 0, size)
+	for i, word := range words {
+		if i > 0 {
+			sentence = append(sentence, ' ')
+		}
+		sentence = append(sentence, word...)
+	}
+	return sentence
+}
@@ -1,8 +1,25 @@
 package seedphrase
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"github.com/mcmx73/easytron/common/secret"
+)
 
+// Bytes2Mnemonic encodes data as English words
 func Bytes2Mnemonic(data []byte) ([]string, error) {
+	return bytes2Mnemonic(data, wordlists[0])
+}
+
+// Bytes2MnemonicInLanguage encodes data as words of the language
+func Bytes2MnemonicInLanguage(data []byte, language Language) ([]string, error) {
+	list, err := wordlistOf(language)
+	if err != nil {
+		return nil, err
+	}
+	return bytes2Mnemonic(data, list)
+}
+
+func bytes2Mnemonic(data []byte, list *wordlist) ([]string, error) {
 	dataBitLength := len(data) * 8
 	checksumBitLength := dataBitLength / 32
 	sentenceLength := (dataBitLength + checksumBitLength) / 11
@@ -12,6 +29,7 @@ func Bytes2Mnemonic(data []byte) ([]string, error) {
 	}
 	mnemonic := make([]string, 0, sentenceLength)
 	dataSignedBits := addChecksumBits(data)
+	defer secret.Wipe(dataSignedBits)
 	if len(dataSignedBits)%11 != 0 {
 		return nil, ErrBytesLengthInvalid
 	}
@@ -20,7 +38,8 @@ func Bytes2Mnemonic(data []byte) ([]string, error) {
 		indexBits, dataSignedBits = popBitsFromBitArray(dataSignedBits, 11)
 		indexBits = bitArrayToBytes(padBitsArray(indexBits, 16))
 		index := binary.BigEndian.Uint16(indexBits)
-		mnemonic = append(mnemonic, words[index])
+		secret.Wipe(indexBits)
+		mnemonic = append(mnemonic, list.words[index])
 	}
 	return mnemonic, nil
 }
@@ -2,6 +2,7 @@ package seedphrase
 
 import (
 	"crypto/sha256"
+	"github.com/mcmx73/easytron/common/secret"
 )
 
 // validateBytesBitSize ensures that data bit size is the correct size for being a
@@ -36,18 +37,21 @@ func uint16ToBitArray(index uint16) []uint8 {
 	return indexBits
 }
 
-// Add to data the first bits of the result of sha256(data)
+// Add to data the first len(data)/4 bits of the result of sha256(data)
 func addChecksumBits(data []byte) []uint8 {
 	// Get first byte of sha256
 	hash := getSha256Hash(data)
 	firstChecksumByte := hash[0]
 	checksumBitLength := uint(len(data) / 4)
 	dataBits := bytesToBitArray(data)
+	defer secret.Wipe(dataBits)
 	checksumBits := bytesToBitArray([]byte{firstChecksumByte})
 	if len(checksumBits) > int(checksumBitLength) {
-		checksumBits = checksumBits[int(checksumBitLength):]
+		checksumBits = checksumBits[:int(checksumBitLength)]
 	}
-	dataSignedBits := addBitsToBitArray(dataBits, checksumBits)
+	dataSignedBits := make([]uint8, 0, len(dataBits)+len(checksumBits))
+	dataSignedBits = addBitsToBitArray(dataSignedBits, dataBits)
+	dataSignedBits = addBitsToBitArray(dataSignedBits, checksumBits)
 	return dataSignedBits
 }
 
@@ -56,8 +60,10 @@ func validateChecksumBits(dataBits []uint8) error {
 	dataBitLength := len(dataBits)
 	checksumBitLength := dataBitLength / 32
 	dataBits, checksumBits := shiftBitsFromBitArray(dataBits, checksumBitLength)
-	checksumByte := getSha256Hash(bitArrayToBytes(dataBits))[0]
-	_, calculatedChecksumBits := shiftBitsFromBitArray(bytesToBitArray([]byte{checksumByte}), checksumBitLength)
+	data := bitArrayToBytes(dataBits)
+	defer secret.Wipe(data)
+	checksumByte := getSha256Hash(data)[0]
+	calculatedChecksumBits, _ := popBitsFromBitArray(bytesToBitArray([]byte{checksumByte}), checksumBitLength)
 	for i, b := range checksumBits {
 		if b != calculatedChecksumBits[i] {
 			return ErrInvalidChecksum
@@ -71,9 +77,10 @@ func getSha256Hash(data []byte) []byte {
 	return hasher.Sum(nil)
 }
 
-// bytesToBitArray converts a byte slice to a bit array.
+// bytesToBitArray converts a byte slice to a bit array. Arrays are allocated once,
+// so no copies of secret bits are left behind by append.
 func bytesToBitArray(data []byte) []uint8 {
-	var out []uint8
+	out := make([]uint8, 0, len(data)*8)
 	for _, b := range data {
 		for i := 0; i < 8; i++ {
 			bit := (b >> uint(7-i)) & 1
@@ -84,7 +91,7 @@ func bytesToBitArray(data []byte) []uint8 {
 }
 
 func bitArrayToBytes(bitArray []uint8) []byte {
-	var out []byte
+	out := make([]byte, 0, len(bitArray)/8)
 	for i := 0; i < len(bitArray); i += 8 {
 		var b uint8
 		for j := 0; j < 8; j++ {
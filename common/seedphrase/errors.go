@@ -8,4 +8,16 @@ var (
 	ErrBytesLengthInvalid       = errors.New("bytes array must be multiple of 32")
 	ErrInvalidChecksum          = errors.New("invalid checksum")
 	ErrInvalidWord              = errors.New("invalid word")
+	ErrUnknownLanguage          = errors.New("unknown wordlist language")
+	ErrAmbiguousPrefix          = errors.New("prefix matches several words")
+
+	ErrInvalidShare        = errors.New("invalid share")
+	ErrInvalidPadding      = errors.New("invalid share padding")
+	ErrInvalidShareDigest  = errors.New("invalid share digest, shares do not match")
+	ErrInsufficientShares  = errors.New("insufficient number of shares")
+	ErrShareMismatch       = errors.New("shares do not match")
+	ErrInvalidThreshold    = errors.New("invalid share threshold")
+	ErrInvalidMasterSecret = errors.New("master secret must be at least 128 bits and even number of bytes")
+	ErrInvalidPassphrase   = errors.New("passphrase must consist of printable ASCII characters")
+	ErrInvalidIterations   = errors.New("iteration exponent must be between 0 and 15")
 )
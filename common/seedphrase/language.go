@@ -0,0 +1,140 @@
+package seedphrase
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+// Language of BIP39 wordlist
+type Language string
+
+const (
+	LanguageEnglish            Language = "english"
+	LanguageChineseSimplified  Language = "chinese_simplified"
+	LanguageChineseTraditional Language = "chinese_traditional"
+	LanguageCzech              Language = "czech"
+	LanguageFrench             Language = "french"
+	LanguageItalian            Language = "italian"
+	LanguageJapanese           Language = "japanese"
+	LanguageKorean             Language = "korean"
+	LanguageSpanish            Language = "spanish"
+
+	ideographicSpace = "　"
+)
+
+type wordlist struct {
+	language Language
+	words    *[2048]string
+	index    map[string]uint16
+}
+
+// wordlists in detection order, English first as the most common
+var wordlists = []*wordlist{
+	{LanguageEnglish, &words, wordsIndex},
+	{LanguageChineseSimplified, &chineseSimplifiedWords, chineseSimplifiedWordsIndex},
+	{LanguageChineseTraditional, &chineseTraditionalWords, chineseTraditionalWordsIndex},
+	{LanguageCzech, &czechWords, czechWordsIndex},
+	{LanguageFrench, &frenchWords, frenchWordsIndex},
+	{LanguageItalian, &italianWords, italianWordsIndex},
+	{LanguageJapanese, &japaneseWords, japaneseWordsIndex},
+	{LanguageKorean, &koreanWords, koreanWordsIndex},
+	{LanguageSpanish, &spanishWords, spanishWordsIndex},
+}
+
+// Languages returns languages of supported wordlists
+func Languages() []Language {
+	languages := make([]Language, len(wordlists))
+	for i, list := range wordlists {
+		languages[i] = list.language
+	}
+	return languages
+}
+
+func wordlistOf(language Language) (*wordlist, error) {
+	for _, list := range wordlists {
+		if list.language == language {
+			return list, nil
+		}
+	}
+	return nil, ErrUnknownLanguage
+}
+
+// lookup returns index of NFKD normalized word, the lists are stored normalized
+func (l *wordlist) lookup(word string) (uint16, bool) {
+	index, ok := l.index[norm.NFKD.String(word)]
+	return index, ok
+}
+
+func (l *wordlist) contains(mnemonic []string) bool {
+	return l.matches(mnemonic) == len(mnemonic)
+}
+
+func (l *wordlist) matches(mnemonic []string) int {
+	count := 0
+	for _, word := range mnemonic {
+		if _, ok := l.lookup(word); ok {
+			count++
+		}
+	}
+	return count
+}
+
+// DetectLanguage returns language of the wordlist containing all words of the mnemonic. Lists
+// share some words, e.g. Chinese simplified and traditional, then the checksum decides.
+func DetectLanguage(mnemonic []string) (Language, error) {
+	list, err := detectWordlist(mnemonic)
+	if err != nil {
+		return "", err
+	}
+	return list.language, nil
+}
+
+// detectWordlist returns *WordError for the first word missing in the list matching most words
+func detectWordlist(mnemonic []string) (*wordlist, error) {
+	var candidates []*wordlist
+	for _, list := range wordlists {
+		if list.contains(mnemonic) {
+			candidates = append(candidates, list)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nearestWordlistError(mnemonic)
+	}
+	for _, list := range candidates[:len(candidates)-1] {
+		if _, err := mnemonic2Bytes(mnemonic, list); err == nil {
+			return list, nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+func nearestWordlistError(mnemonic []string) error {
+	nearest, nearestMatches := wordlists[0], -1
+	for _, list := range wordlists {
+		if matches := list.matches(mnemonic); matches > nearestMatches {
+			nearest, nearestMatches = list, matches
+		}
+	}
+	for i, word := range mnemonic {
+		if _, ok := nearest.lookup(word); !ok {
+			return &WordError{Index: i, Word: word, Language: nearest.language, Err: ErrInvalidWord}
+		}
+	}
+	return ErrInvalidWord
+}
+
+// NormalizeMnemonic splits entered phrase into NFKD normalized lowercase words. Ideographic spaces
+// of Japanese phrases and repeated whitespace separate words like single spaces.
+func NormalizeMnemonic(phrase string) []string {
+	phrase = strings.ReplaceAll(phrase, ideographicSpace, " ")
+	return strings.Fields(strings.ToLower(norm.NFKD.String(phrase)))
+}
+
+// JoinMnemonic joins words of the language for display, Japanese words are separated with
+// ideographic spaces
+func JoinMnemonic(mnemonic []string, language Language) string {
+	if language == LanguageJapanese {
+		return strings.Join(mnemonic, ideographicSpace)
+	}
+	return strings.Join(mnemonic, " ")
+}
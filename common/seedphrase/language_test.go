@@ -0,0 +1,80 @@
+package seedphrase
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLanguagesRoundTrip(t *testing.T) {
+	for _, language := range Languages() {
+		mnemonic, err := NewMnemonicInLanguage(24, language)
+		if err != nil {
+			t.Fatalf("%s: %v", language, err)
+		}
+		detected, err := DetectLanguage(mnemonic)
+		if err != nil {
+			t.Fatalf("%s: %v", language, err)
+		}
+		if detected != language {
+			t.Errorf("detected %s, want %s", detected, language)
+		}
+		if err = ValidateMnemonic(mnemonic); err != nil {
+			t.Errorf("%s: %v", language, err)
+		}
+		parsed := NormalizeMnemonic(JoinMnemonic(mnemonic, language))
+		if len(parsed) != len(mnemonic) {
+			t.Errorf("%s: parsed %d words", language, len(parsed))
+		}
+	}
+}
+
+// Test vector from https://github.com/bip32JP/bip32JP.github.io/blob/master/test_JP_BIP39.json
+func TestJapaneseMnemonic(t *testing.T) {
+	entropy, _ := hex.DecodeString("00000000000000000000000000000000")
+	mnemonic, err := Bytes2MnemonicInLanguage(entropy, LanguageJapanese)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phrase := "あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら"
+	parsed := NormalizeMnemonic(phrase)
+	if joined := JoinMnemonic(mnemonic, LanguageJapanese); joined != JoinMnemonic(parsed, LanguageJapanese) {
+		t.Fatalf("mnemonic %s, want %s", joined, phrase)
+	}
+	if joined := JoinMnemonic(mnemonic, LanguageJapanese); !strings.Contains(joined, ideographicSpace) {
+		t.Errorf("mnemonic %q is not separated with ideographic spaces", joined)
+	}
+	if language, err := DetectLanguage(parsed); err != nil || language != LanguageJapanese {
+		t.Fatalf("detected %s, %v", language, err)
+	}
+	seed, err := MnemonicToSeed(parsed, "㍍ガバヴァぱばぐゞちぢ十人十色")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer seed.Destroy()
+	want := "a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55"
+	if got := hex.EncodeToString(seed.Export()); got != want {
+		t.Errorf("seed %s, want %s", got, want)
+	}
+}
+
+func TestNormalizeMnemonic(t *testing.T) {
+	// "élève" typed precomposed and upper case matches the decomposed French list entry
+	words := NormalizeMnemonic("  Élève\télève  ")
+	if len(words) != 2 || words[0] != words[1] {
+		t.Fatalf("words %q", words)
+	}
+	if _, ok := frenchWordsIndex[words[0]]; !ok {
+		t.Errorf("%q not found in French wordlist", words[0])
+	}
+}
+
+func TestDetectLanguageUnknown(t *testing.T) {
+	if _, err := DetectLanguage([]string{"abandon", "あいこくしん"}); !errors.Is(err, ErrInvalidWord) {
+		t.Errorf("err %v, want %v", err, ErrInvalidWord)
+	}
+	if _, err := Bytes2MnemonicInLanguage(make([]byte, 16), "portuguese"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("err %v, want %v", err, ErrUnknownLanguage)
+	}
+}
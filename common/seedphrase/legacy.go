@@ -0,0 +1,15 @@
+package seedphrase
+
+// Legacy backups encode raw 256-bit private key bytes as words of the BIP39 list.
+// Such phrase is not a BIP39 mnemonic: other wallets derive different keys from it,
+// so it is kept only to restore keys from existing easytron backups.
+
+// LegacyKeyToMnemonic encodes private key bytes as legacy backup phrase
+func LegacyKeyToMnemonic(privateKey []byte) ([]string, error) {
+	return Bytes2Mnemonic(privateKey)
+}
+
+// LegacyMnemonicToKey restores private key bytes from legacy backup phrase
+func LegacyMnemonicToKey(mnemonic []string) ([]byte, error) {
+	return Mnemonic2Bytes(mnemonic)
+}
@@ -1,17 +1,33 @@
 package seedphrase
 
+import "github.com/mcmx73/easytron/common/secret"
+
+// Mnemonic2Bytes decodes words of any supported language, see DetectLanguage
 func Mnemonic2Bytes(mnemonic []string) (data []byte, err error) {
+	if err = validateMnemonicWordCount(mnemonic); err != nil {
+		return nil, err
+	}
+	list, err := detectWordlist(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	return mnemonic2Bytes(mnemonic, list)
+}
+
+func mnemonic2Bytes(mnemonic []string, list *wordlist) (data []byte, err error) {
 	if err = validateMnemonicWordCount(mnemonic); err != nil {
 		return nil, err
 	}
 	bitsArray := make([]uint8, 0, len(mnemonic)*11)
-	for _, word := range mnemonic {
-		index, ok := wordsIndex[word]
+	defer secret.Wipe(bitsArray[:cap(bitsArray)])
+	for i, word := range mnemonic {
+		index, ok := list.lookup(word)
 		if !ok {
-			return nil, ErrInvalidWord
+			return nil, &WordError{Index: i, Word: word, Language: list.language, Err: ErrInvalidWord}
 		}
-		indexBits := uint16ToBitArray(index)[5:]
-		bitsArray = addBitsToBitArray(bitsArray, indexBits)
+		indexBits := uint16ToBitArray(index)
+		bitsArray = addBitsToBitArray(bitsArray, indexBits[5:])
+		secret.Wipe(indexBits)
 	}
 	if err = validateChecksumBits(bitsArray); err != nil {
 		return nil, err
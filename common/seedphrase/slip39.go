@@ -0,0 +1,542 @@
+package seedphrase
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/mcmx73/easytron/common/secret"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// SLIP-39 Shamir's secret sharing for mnemonic codes
+// See https://github.com/satoshilabs/slips/blob/master/slip-0039.md
+//
+// The master secret is encrypted with the passphrase, split into groups with group threshold and
+// each group share is split into member shares with member threshold. Any group threshold groups,
+// each with member threshold shares, recover the master secret.
+
+const (
+	slip39RadixBits          = 10
+	slip39IdentifierBits     = 15
+	slip39IterationExpBits   = 4
+	slip39ChecksumWords      = 3
+	slip39MetadataWords      = 7
+	slip39MinStrengthBits    = 128
+	slip39MinWords           = slip39MetadataWords + (slip39MinStrengthBits+slip39RadixBits-1)/slip39RadixBits
+	slip39MaxShareCount      = 16
+	slip39BaseIterationCount = 10000
+	slip39RoundCount         = 4
+	slip39SecretIndex        = 255
+	slip39DigestIndex        = 254
+	slip39DigestLength       = 4
+
+	slip39Customization           = "shamir"
+	slip39ExtendableCustomization = "shamir_extendable"
+)
+
+// Slip39Group is member threshold and member count of a share group
+type Slip39Group struct {
+	MemberThreshold int
+	MemberCount     int
+}
+
+type WithSlip39Option func(*slip39Options)
+
+type slip39Options struct {
+	passphrase        string
+	iterationExponent int
+	extendable        bool
+}
+
+// WithSlip39Passphrase encrypts the master secret with the passphrase of printable ASCII characters
+func WithSlip39Passphrase(passphrase string) WithSlip39Option {
+	return func(o *slip39Options) {
+		o.passphrase = passphrase
+	}
+}
+
+// WithSlip39IterationExponent sets PBKDF2 iteration count to 10000 * 2^exponent, 1 by default
+func WithSlip39IterationExponent(exponent int) WithSlip39Option {
+	return func(o *slip39Options) {
+		o.iterationExponent = exponent
+	}
+}
+
+// WithSlip39Extendable sets extendable backup flag, enabled by default. Extendable backups allow to
+// create more shares of the same master secret and passphrase later.
+func WithSlip39Extendable(extendable bool) WithSlip39Option {
+	return func(o *slip39Options) {
+		o.extendable = extendable
+	}
+}
+
+// GenerateSlip39Shares splits the master secret of at least 128 bits into groups of member shares.
+// Result holds mnemonics of every group in groups order.
+func GenerateSlip39Shares(masterSecret []byte, groupThreshold int, groups []Slip39Group, options ...WithSlip39Option) ([][][]string, error) {
+	o := &slip39Options{iterationExponent: 1, extendable: true}
+	for _, opt := range options {
+		opt(o)
+	}
+	if len(masterSecret)*8 < slip39MinStrengthBits || len(masterSecret)%2 != 0 {
+		return nil, ErrInvalidMasterSecret
+	}
+	if err := validateSlip39Passphrase(o.passphrase); err != nil {
+		return nil, err
+	}
+	if o.iterationExponent < 0 || o.iterationExponent >= 1<<slip39IterationExpBits {
+		return nil, ErrInvalidIterations
+	}
+	if groupThreshold < 1 || groupThreshold > len(groups) || len(groups) > slip39MaxShareCount {
+		return nil, fmt.Errorf("%w: group threshold %d of %d groups", ErrInvalidThreshold, groupThreshold, len(groups))
+	}
+	for _, group := range groups {
+		if group.MemberThreshold < 1 || group.MemberThreshold > group.MemberCount || group.MemberCount > slip39MaxShareCount {
+			return nil, fmt.Errorf("%w: member threshold %d of %d members", ErrInvalidThreshold, group.MemberThreshold, group.MemberCount)
+		}
+		if group.MemberThreshold == 1 && group.MemberCount > 1 {
+			return nil, fmt.Errorf("%w: use 1-of-1 group instead of 1-of-%d", ErrInvalidThreshold, group.MemberCount)
+		}
+	}
+
+	var identifierBytes [2]byte
+	if _, err := rand.Read(identifierBytes[:]); err != nil {
+		return nil, err
+	}
+	identifier := binary.BigEndian.Uint16(identifierBytes[:]) & (1<<slip39IdentifierBits - 1)
+	encrypted := slip39Encrypt(masterSecret, []byte(o.passphrase), o.iterationExponent, identifier, o.extendable)
+	defer secret.Wipe(encrypted)
+
+	groupShares, err := shamirSplit(groupThreshold, len(groups), encrypted)
+	if err != nil {
+		return nil, err
+	}
+	mnemonics := make([][][]string, len(groups))
+	for i, group := range groups {
+		memberShares, err := shamirSplit(group.MemberThreshold, group.MemberCount, groupShares[i].value)
+		secret.Wipe(groupShares[i].value)
+		if err != nil {
+			return nil, err
+		}
+		for _, memberShare := range memberShares {
+			share := &slip39Share{
+				identifier:        identifier,
+				extendable:        o.extendable,
+				iterationExponent: o.iterationExponent,
+				groupIndex:        i,
+				groupThreshold:    groupThreshold,
+				groupCount:        len(groups),
+				memberIndex:       int(memberShare.x),
+				memberThreshold:   group.MemberThreshold,
+				value:             memberShare.value,
+			}
+			mnemonics[i] = append(mnemonics[i], share.mnemonic())
+			secret.Wipe(memberShare.value)
+		}
+	}
+	return mnemonics, nil
+}
+
+// RecoverSlip39Secret combines shares of group threshold groups, member threshold shares of each,
+// and decrypts the master secret with the passphrase
+func RecoverSlip39Secret(mnemonics [][]string, passphrase string) (*secret.Buffer, error) {
+	if len(mnemonics) == 0 {
+		return nil, ErrInsufficientShares
+	}
+	if err := validateSlip39Passphrase(passphrase); err != nil {
+		return nil, err
+	}
+	var common *slip39Share
+	groups := make(map[int][]*slip39Share)
+	for _, mnemonic := range mnemonics {
+		share, err := decodeSlip39Share(mnemonic)
+		if err != nil {
+			return nil, err
+		}
+		if common == nil {
+			common = share
+		} else if !share.sameSet(common) {
+			return nil, fmt.Errorf("%w: shares belong to different backups", ErrShareMismatch)
+		}
+		group, err := addMemberShare(groups[share.groupIndex], share)
+		if err != nil {
+			return nil, err
+		}
+		groups[share.groupIndex] = group
+	}
+	if len(groups) < common.groupThreshold {
+		return nil, fmt.Errorf("%w: %d of %d groups", ErrInsufficientShares, len(groups), common.groupThreshold)
+	}
+	if len(groups) > common.groupThreshold {
+		return nil, fmt.Errorf("%w: %d groups given, exactly %d required", ErrShareMismatch, len(groups), common.groupThreshold)
+	}
+
+	groupShares := make([]shamirShare, 0, len(groups))
+	for groupIndex, members := range groups {
+		memberThreshold := members[0].memberThreshold
+		if len(members) < memberThreshold {
+			return nil, fmt.Errorf("%w: %d of %d shares of group %d", ErrInsufficientShares, len(members), memberThreshold, groupIndex+1)
+		}
+		if len(members) > memberThreshold {
+			return nil, fmt.Errorf("%w: %d shares of group %d given, exactly %d required", ErrShareMismatch, len(members), groupIndex+1, memberThreshold)
+		}
+		memberShares := make([]shamirShare, len(members))
+		for i, member := range members {
+			memberShares[i] = shamirShare{x: byte(member.memberIndex), value: member.value}
+		}
+		value, err := shamirRecover(memberThreshold, memberShares)
+		if err != nil {
+			return nil, err
+		}
+		groupShares = append(groupShares, shamirShare{x: byte(groupIndex), value: value})
+	}
+	encrypted, err := shamirRecover(common.groupThreshold, groupShares)
+	for _, groupShare := range groupShares {
+		secret.Wipe(groupShare.value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(encrypted)
+	return secret.FromBytes(slip39Decrypt(encrypted, []byte(passphrase), common.iterationExponent, common.identifier, common.extendable)), nil
+}
+
+// ValidateSlip39Share checks words, length, padding and checksum of a single share
+func ValidateSlip39Share(mnemonic []string) error {
+	share, err := decodeSlip39Share(mnemonic)
+	if err == nil {
+		secret.Wipe(share.value)
+	}
+	return err
+}
+
+// addMemberShare adds share to its group, repeated shares are ignored
+func addMemberShare(group []*slip39Share, share *slip39Share) ([]*slip39Share, error) {
+	for _, member := range group {
+		if member.memberThreshold != share.memberThreshold {
+			return nil, fmt.Errorf("%w: member thresholds of group %d differ", ErrShareMismatch, share.groupIndex+1)
+		}
+		if member.memberIndex == share.memberIndex {
+			if bytes.Equal(member.value, share.value) {
+				return group, nil
+			}
+			return nil, fmt.Errorf("%w: different shares with member index %d", ErrShareMismatch, share.memberIndex+1)
+		}
+	}
+	return append(group, share), nil
+}
+
+func validateSlip39Passphrase(passphrase string) error {
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] < 32 || passphrase[i] > 126 {
+			return ErrInvalidPassphrase
+		}
+	}
+	return nil
+}
+
+type slip39Share struct {
+	identifier        uint16
+	extendable        bool
+	iterationExponent int
+	groupIndex        int
+	groupThreshold    int
+	groupCount        int
+	memberIndex       int
+	memberThreshold   int
+	value             []byte
+}
+
+// sameSet reports whether shares come from the same split of the same secret
+func (s *slip39Share) sameSet(other *slip39Share) bool {
+	return s.identifier == other.identifier &&
+		s.extendable == other.extendable &&
+		s.iterationExponent == other.iterationExponent &&
+		s.groupThreshold == other.groupThreshold &&
+		s.groupCount == other.groupCount &&
+		len(s.value) == len(other.value)
+}
+
+func (s *slip39Share) customization() string {
+	if s.extendable {
+		return slip39ExtendableCustomization
+	}
+	return slip39Customization
+}
+
+// mnemonic encodes identifier, flags, group and member parameters, the value and RS1024 checksum
+func (s *slip39Share) mnemonic() []string {
+	var extendable uint32
+	if s.extendable {
+		extendable = 1
+	}
+	header := uint32(s.identifier)<<5 | extendable<<4 | uint32(s.iterationExponent)
+	params := uint32(s.groupIndex)<<16 | uint32(s.groupThreshold-1)<<12 | uint32(s.groupCount-1)<<8 |
+		uint32(s.memberIndex)<<4 | uint32(s.memberThreshold-1)
+	indexes := []int{
+		int(header >> 10), int(header & 1023),
+		int(params >> 10), int(params & 1023),
+	}
+	indexes = append(indexes, bytesToSlip39Indexes(s.value)...)
+	indexes = append(indexes, rs1024CreateChecksum(s.customization(), indexes)...)
+	mnemonic := make([]string, len(indexes))
+	for i, index := range indexes {
+		mnemonic[i] = slip39Words[index]
+	}
+	return mnemonic
+}
+
+func decodeSlip39Share(mnemonic []string) (*slip39Share, error) {
+	if len(mnemonic) < slip39MinWords {
+		return nil, fmt.Errorf("%w: at least %d words required", ErrInvalidShare, slip39MinWords)
+	}
+	indexes := make([]int, len(mnemonic))
+	for i, word := range mnemonic {
+		index, ok := slip39WordsIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidWord, word)
+		}
+		indexes[i] = int(index)
+	}
+	valueWords := len(indexes) - slip39MetadataWords
+	if (slip39RadixBits*valueWords)%16 > 8 {
+		return nil, fmt.Errorf("%w: invalid length", ErrInvalidShare)
+	}
+	header := uint32(indexes[0])<<10 | uint32(indexes[1])
+	share := &slip39Share{
+		identifier:        uint16(header >> 5),
+		extendable:        header>>4&1 == 1,
+		iterationExponent: int(header & 15),
+	}
+	if !rs1024VerifyChecksum(share.customization(), indexes) {
+		return nil, ErrInvalidChecksum
+	}
+	params := uint32(indexes[2])<<10 | uint32(indexes[3])
+	share.groupIndex = int(params >> 16)
+	share.groupThreshold = int(params>>12&15) + 1
+	share.groupCount = int(params>>8&15) + 1
+	share.memberIndex = int(params >> 4 & 15)
+	share.memberThreshold = int(params&15) + 1
+	if share.groupCount < share.groupThreshold {
+		return nil, fmt.Errorf("%w: group threshold exceeds group count", ErrInvalidShare)
+	}
+	value, err := slip39IndexesToBytes(indexes[4 : len(indexes)-slip39ChecksumWords])
+	if err != nil {
+		return nil, err
+	}
+	share.value = value
+	return share, nil
+}
+
+// bytesToSlip39Indexes splits big-endian value into 10-bit words, padded with leading zero bits
+func bytesToSlip39Indexes(value []byte) []int {
+	wordCount := (len(value)*8 + slip39RadixBits - 1) / slip39RadixBits
+	indexes := make([]int, 0, wordCount)
+	var acc uint32
+	bits := wordCount*slip39RadixBits - len(value)*8
+	for _, b := range value {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= slip39RadixBits {
+			bits -= slip39RadixBits
+			indexes = append(indexes, int(acc>>bits&1023))
+		}
+		acc &= 1<<bits - 1
+	}
+	return indexes
+}
+
+// slip39IndexesToBytes joins 10-bit words into bytes, the leading padding bits must be zero
+func slip39IndexesToBytes(indexes []int) ([]byte, error) {
+	padding := slip39RadixBits * len(indexes) % 16
+	value := make([]byte, 0, (slip39RadixBits*len(indexes)-padding)/8)
+	var acc uint32
+	bits := 0
+	for i, index := range indexes {
+		acc = acc<<slip39RadixBits | uint32(index)
+		bits += slip39RadixBits
+		if i == 0 {
+			// padding is at most 8 bits, so it is inside the first word
+			bits -= padding
+			if acc>>bits != 0 {
+				return nil, ErrInvalidPadding
+			}
+		}
+		for bits >= 8 {
+			bits -= 8
+			value = append(value, byte(acc>>bits))
+		}
+		acc &= 1<<bits - 1
+	}
+	return value, nil
+}
+
+var rs1024Generator = [10]uint32{0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009, 0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120}
+
+func rs1024Polymod(customization string, indexes []int) uint32 {
+	chk := uint32(1)
+	step := func(v uint32) {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ v
+		for i := 0; i < 10; i++ {
+			if b>>i&1 == 1 {
+				chk ^= rs1024Generator[i]
+			}
+		}
+	}
+	for i := 0; i < len(customization); i++ {
+		step(uint32(customization[i]))
+	}
+	for _, index := range indexes {
+		step(uint32(index))
+	}
+	return chk
+}
+
+func rs1024CreateChecksum(customization string, indexes []int) []int {
+	polymod := rs1024Polymod(customization, append(append([]int{}, indexes...), 0, 0, 0)) ^ 1
+	return []int{int(polymod >> 20 & 1023), int(polymod >> 10 & 1023), int(polymod & 1023)}
+}
+
+func rs1024VerifyChecksum(customization string, indexes []int) bool {
+	return rs1024Polymod(customization, indexes) == 1
+}
+
+// slip39Encrypt runs four rounds of Feistel network with PBKDF2-HMAC-SHA256 round function
+func slip39Encrypt(masterSecret, passphrase []byte, iterationExponent int, identifier uint16, extendable bool) []byte {
+	return slip39Feistel(masterSecret, passphrase, iterationExponent, identifier, extendable, []byte{0, 1, 2, 3})
+}
+
+func slip39Decrypt(encrypted, passphrase []byte, iterationExponent int, identifier uint16, extendable bool) []byte {
+	return slip39Feistel(encrypted, passphrase, iterationExponent, identifier, extendable, []byte{3, 2, 1, 0})
+}
+
+func slip39Feistel(data, passphrase []byte, iterationExponent int, identifier uint16, extendable bool, rounds []byte) []byte {
+	half := len(data) / 2
+	left := append([]byte{}, data[:half]...)
+	right := append([]byte{}, data[half:]...)
+	var salt []byte
+	if !extendable {
+		salt = binary.BigEndian.AppendUint16([]byte(slip39Customization), identifier)
+	}
+	iterations := (slip39BaseIterationCount << iterationExponent) / slip39RoundCount
+	for _, round := range rounds {
+		password := append([]byte{round}, passphrase...)
+		f := pbkdf2.Key(password, append(append([]byte{}, salt...), right...), iterations, len(right), sha256.New)
+		for i := range f {
+			f[i] ^= left[i]
+		}
+		secret.Wipe(left)
+		left, right = right, f
+	}
+	return append(right, left...)
+}
+
+// Shamir's secret sharing over GF(256) with Rijndael polynomial
+
+type shamirShare struct {
+	x     byte
+	value []byte
+}
+
+var gf256Exp, gf256Log = gf256Tables()
+
+func gf256Tables() (exp [255]byte, log [256]int) {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(poly)
+		log[poly] = i
+		// multiply by generator 3
+		poly = poly<<1 ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11b
+		}
+	}
+	return exp, log
+}
+
+// shamirInterpolate returns value at x of the polynomial going through the shares
+func shamirInterpolate(shares []shamirShare, x byte) []byte {
+	for _, share := range shares {
+		if share.x == x {
+			return append([]byte{}, share.value...)
+		}
+	}
+	logProduct := 0
+	for _, share := range shares {
+		logProduct += gf256Log[share.x^x]
+	}
+	result := make([]byte, len(shares[0].value))
+	for i, share := range shares {
+		logBasis := logProduct - gf256Log[share.x^x]
+		for j, other := range shares {
+			if j != i {
+				logBasis -= gf256Log[share.x^other.x]
+			}
+		}
+		logBasis = (logBasis%255 + 255) % 255
+		for k, v := range share.value {
+			if v != 0 {
+				result[k] ^= gf256Exp[(gf256Log[v]+logBasis)%255]
+			}
+		}
+	}
+	return result
+}
+
+func shamirSplit(threshold, count int, value []byte) ([]shamirShare, error) {
+	if threshold < 1 || threshold > count || count > slip39MaxShareCount {
+		return nil, ErrInvalidThreshold
+	}
+	shares := make([]shamirShare, 0, count)
+	if threshold == 1 {
+		for i := 0; i < count; i++ {
+			shares = append(shares, shamirShare{x: byte(i), value: append([]byte{}, value...)})
+		}
+		return shares, nil
+	}
+	randomShareCount := threshold - 2
+	for i := 0; i < randomShareCount; i++ {
+		randomValue := make([]byte, len(value))
+		if _, err := rand.Read(randomValue); err != nil {
+			return nil, err
+		}
+		shares = append(shares, shamirShare{x: byte(i), value: randomValue})
+	}
+	digestValue := make([]byte, len(value))
+	if _, err := rand.Read(digestValue[slip39DigestLength:]); err != nil {
+		return nil, err
+	}
+	copy(digestValue, shamirDigest(digestValue[slip39DigestLength:], value))
+	baseShares := append(append([]shamirShare{}, shares...),
+		shamirShare{x: slip39DigestIndex, value: digestValue},
+		shamirShare{x: slip39SecretIndex, value: value},
+	)
+	for i := randomShareCount; i < count; i++ {
+		shares = append(shares, shamirShare{x: byte(i), value: shamirInterpolate(baseShares, byte(i))})
+	}
+	secret.Wipe(digestValue)
+	return shares, nil
+}
+
+func shamirRecover(threshold int, shares []shamirShare) ([]byte, error) {
+	if threshold == 1 {
+		return append([]byte{}, shares[0].value...), nil
+	}
+	value := shamirInterpolate(shares, slip39SecretIndex)
+	digestValue := shamirInterpolate(shares, slip39DigestIndex)
+	defer secret.Wipe(digestValue)
+	if !hmac.Equal(digestValue[:slip39DigestLength], shamirDigest(digestValue[slip39DigestLength:], value)) {
+		secret.Wipe(value)
+		return nil, ErrInvalidShareDigest
+	}
+	return value, nil
+}
+
+func shamirDigest(randomPart, value []byte) []byte {
+	mac := hmac.New(sha256.New, randomPart)
+	mac.Write(value)
+	return mac.Sum(nil)[:slip39DigestLength]
+}
@@ -0,0 +1,204 @@
+package seedphrase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/mcmx73/easytron/common/base58"
+	"os"
+	"strings"
+	"testing"
+)
+
+// slip39VectorsFile holds test vectors in the layout of
+// https://github.com/trezor/python-shamir-mnemonic/blob/master/vectors.json: description, mnemonics,
+// master secret and BIP-32 master xprv of the secret. Vectors without master secret must be
+// rejected, the passphrase of all of them is "TREZOR".
+const slip39VectorsFile = "testdata/slip39_vectors.json"
+
+type slip39Vector struct {
+	description string
+	mnemonics   []string
+	secret      string
+	xprv        string
+}
+
+func (v *slip39Vector) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &[]interface{}{&v.description, &v.mnemonics, &v.secret, &v.xprv})
+}
+
+// bip32MasterXprv serializes BIP-32 master private key of the seed, keys package derives extended
+// keys but imports seedphrase
+func bip32MasterXprv(seed []byte) string {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	// version, depth, parent fingerprint and child number are those of the master key
+	data := append([]byte{0x04, 0x88, 0xad, 0xe4}, make([]byte, 9)...)
+	data = append(append(append(data, sum[32:]...), 0), sum[:32]...)
+	checksum := sha256.Sum256(data)
+	checksum = sha256.Sum256(checksum[:])
+	return base58.Encode(append(data, checksum[:4]...))
+}
+
+func TestSlip39Vectors(t *testing.T) {
+	data, err := os.ReadFile(slip39VectorsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []slip39Vector
+	if err = json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	for _, vector := range vectors {
+		mnemonics := make([][]string, len(vector.mnemonics))
+		for i, mnemonic := range vector.mnemonics {
+			mnemonics[i] = strings.Fields(mnemonic)
+		}
+		masterSecret, err := RecoverSlip39Secret(mnemonics, "TREZOR")
+		if vector.secret == "" {
+			if err == nil {
+				t.Errorf("%s: recovered %x", vector.description, masterSecret.Export())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", vector.description, err)
+			continue
+		}
+		if hex.EncodeToString(masterSecret.Export()) != vector.secret {
+			t.Errorf("%s: secret %x", vector.description, masterSecret.Export())
+		}
+		if xprv := bip32MasterXprv(masterSecret.Export()); xprv != vector.xprv {
+			t.Errorf("%s: xprv %s", vector.description, xprv)
+		}
+		masterSecret.Destroy()
+	}
+}
+
+func TestSlip39InvalidShares(t *testing.T) {
+	masterSecret, _ := hex.DecodeString("bb54aac4b89dc868ba37d9cc21b2cece")
+	shares, err := GenerateSlip39Shares(masterSecret, 2, []Slip39Group{{2, 3}, {2, 3}}, WithSlip39IterationExponent(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherShares, err := GenerateSlip39Shares(masterSecret, 2, []Slip39Group{{2, 3}, {2, 3}}, WithSlip39IterationExponent(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(mnemonic []string) *slip39Share {
+		share, err := decodeSlip39Share(mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return share
+	}
+	// modified re-encodes the first share with a valid checksum, so only the change is invalid
+	modified := func(change func(share *slip39Share)) []string {
+		share := decode(shares[0][0])
+		change(share)
+		return share.mnemonic()
+	}
+	// replaced returns a valid set of shares with the first one replaced
+	replaced := func(mnemonic []string) [][]string {
+		return [][]string{mnemonic, shares[0][1], shares[1][0], shares[1][1]}
+	}
+
+	recovered, err := RecoverSlip39Secret(replaced(shares[0][0]), "")
+	if err != nil || hex.EncodeToString(recovered.Export()) != hex.EncodeToString(masterSecret) {
+		t.Fatalf("recovered %x, %v", recovered.Export(), err)
+	}
+
+	badChecksum := append([]string(nil), shares[0][0]...)
+	if badChecksum[19] == "academic" {
+		badChecksum[19] = "acid"
+	} else {
+		badChecksum[19] = "academic"
+	}
+	badPadding := func() []string {
+		share := decode(shares[0][0])
+		indexes := make([]int, 0, len(shares[0][0]))
+		for _, word := range share.mnemonic()[:len(shares[0][0])-slip39ChecksumWords] {
+			indexes = append(indexes, int(slip39WordsIndex[word]))
+		}
+		indexes[4] |= 1 << (slip39RadixBits - 1)
+		indexes = append(indexes, rs1024CreateChecksum(share.customization(), indexes)...)
+		mnemonic := make([]string, len(indexes))
+		for i, index := range indexes {
+			mnemonic[i] = slip39Words[index]
+		}
+		return mnemonic
+	}()
+	cases := []struct {
+		name      string
+		mnemonics [][]string
+		err       error
+	}{
+		{"too short", replaced(shares[0][0][:slip39MinWords-1]), ErrInvalidShare},
+		{"unknown word", replaced(append([]string{"bitcoin"}, shares[0][0][1:]...)), ErrInvalidWord},
+		{"invalid checksum", replaced(badChecksum), ErrInvalidChecksum},
+		{"invalid padding", replaced(badPadding), ErrInvalidPadding},
+		{"group threshold exceeds group count", replaced(modified(func(s *slip39Share) { s.groupThreshold = 3 })), ErrInvalidShare},
+		{"different identifiers", replaced(otherShares[0][0]), ErrShareMismatch},
+		{"different group thresholds", replaced(modified(func(s *slip39Share) { s.groupThreshold = 1 })), ErrShareMismatch},
+		{"different group counts", replaced(modified(func(s *slip39Share) { s.groupCount = 3 })), ErrShareMismatch},
+		{"different member thresholds", replaced(modified(func(s *slip39Share) { s.memberThreshold = 3 })), ErrShareMismatch},
+		{"different value lengths", replaced(modified(func(s *slip39Share) { s.value = append(s.value, 0, 0) })), ErrShareMismatch},
+		{"same member index", replaced(modified(func(s *slip39Share) { s.memberIndex = decode(shares[0][1]).memberIndex })), ErrShareMismatch},
+		{"invalid digest", replaced(modified(func(s *slip39Share) { s.value[0] ^= 1 })), ErrInvalidShareDigest},
+		{"insufficient members", [][]string{shares[0][0], shares[1][0], shares[1][1]}, ErrInsufficientShares},
+		{"insufficient groups", [][]string{shares[0][0], shares[0][1]}, ErrInsufficientShares},
+	}
+	for _, c := range cases {
+		if _, err := RecoverSlip39Secret(c.mnemonics, ""); !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestSlip39SplitAndRecover(t *testing.T) {
+	masterSecret, _ := hex.DecodeString("bb54aac4b89dc868ba37d9cc21b2cece")
+	groups := []Slip39Group{{1, 1}, {2, 3}, {3, 5}}
+	for _, extendable := range []bool{false, true} {
+		shares, err := GenerateSlip39Shares(masterSecret, 2, groups,
+			WithSlip39Passphrase("TREZOR"), WithSlip39IterationExponent(0), WithSlip39Extendable(extendable))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, group := range groups {
+			if len(shares[i]) != group.MemberCount {
+				t.Fatalf("group %d has %d shares", i, len(shares[i]))
+			}
+			for _, share := range shares[i] {
+				if len(share) != 20 {
+					t.Errorf("share of %d words", len(share))
+				}
+				if err = ValidateSlip39Share(share); err != nil {
+					t.Error(err)
+				}
+			}
+		}
+		recovered, err := RecoverSlip39Secret([][]string{shares[2][4], shares[1][2], shares[2][0], shares[1][0], shares[2][1]}, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(recovered.Export()) != hex.EncodeToString(masterSecret) {
+			t.Errorf("recovered %x", recovered.Export())
+		}
+		if other, err := RecoverSlip39Secret([][]string{shares[0][0], shares[1][1], shares[1][2]}, ""); err != nil || hex.EncodeToString(other.Export()) == hex.EncodeToString(masterSecret) {
+			t.Errorf("wrong passphrase recovers %x, %v", other.Export(), err)
+		}
+		if _, err = RecoverSlip39Secret([][]string{shares[0][0], shares[2][0], shares[2][1]}, "TREZOR"); !errors.Is(err, ErrInsufficientShares) {
+			t.Errorf("expected ErrInsufficientShares, got %v", err)
+		}
+	}
+	if _, err := GenerateSlip39Shares(masterSecret, 2, []Slip39Group{{1, 3}, {1, 1}}); !errors.Is(err, ErrInvalidThreshold) {
+		t.Errorf("expected ErrInvalidThreshold, got %v", err)
+	}
+	if _, err := GenerateSlip39Shares(masterSecret[:15], 1, []Slip39Group{{1, 1}}); !errors.Is(err, ErrInvalidMasterSecret) {
+		t.Errorf("expected ErrInvalidMasterSecret, got %v", err)
+	}
+}
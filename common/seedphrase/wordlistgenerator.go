@@ -5,22 +5,61 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"strings"
 )
 
+// Official BIP39 wordlists from https://github.com/bitcoin/bips/tree/master/bip-0039, each is read
+// from wordlist_<language>.txt and must be the unmodified upstream file
+var bip39Languages = []string{
+	"chinese_simplified",
+	"chinese_traditional",
+	"czech",
+	"french",
+	"italian",
+	"japanese",
+	"korean",
+	"spanish",
+}
+
 func main() {
-	words, _ := os.ReadFile("wordlist.txt")
-	wordList := strings.Split(string(words), "\n")
-	fmt.Print("Generate words array from wordlist.txt\n\n")
-	wordCount := len(wordList)
+	generate("wordlist.txt", "words.go", "words", 2048)
+	for _, language := range bip39Languages {
+		generate("wordlist_"+language+".txt", "words_"+language+".go", camelCase(language)+"Words", 2048)
+	}
+	generate("slip39wordlist.txt", "slip39words.go", "slip39Words", 1024)
+}
+
+func camelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// generate stops on missing or truncated source, a partial wordlist would produce mnemonics other
+// wallets can not restore
+func generate(source, target, name string, wordCount int) {
+	words, err := os.ReadFile(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	wordList := strings.Split(strings.TrimSpace(string(words)), "\n")
+	if len(wordList) != wordCount {
+		log.Fatalf("%s has %d words, %d expected", source, len(wordList), wordCount)
+	}
+	fmt.Printf("Generate %s array from %s\n\n", name, source)
 	out := "package seedphrase\n"
 	out += "// This file autogenerated. Please don't edit!\n"
-	out += "\nfunc init() {\n\tfor i, word := range words {\n\t\twordsIndex[word] = uint16(i)\n\t}\n}\n\n"
-	out += "var (\n\twordsIndex = make(map[string]uint16)\n words = [" + fmt.Sprintf("%d", wordCount) + "]string{\n"
+	out += "\nfunc init() {\n\tfor i, word := range " + name + " {\n\t\t" + name + "Index[word] = uint16(i)\n\t}\n}\n\n"
+	out += "var (\n\t" + name + "Index = make(map[string]uint16)\n " + name + " = [" + fmt.Sprintf("%d", wordCount) + "]string{\n"
 	for _, word := range wordList {
 		out += "\t\"" + word + "\",\n"
 	}
 	out += "}\n)\n"
-	os.WriteFile("words.go", []byte(out), 0644)
+	if err = os.WriteFile(target, []byte(out), 0644); err != nil {
+		log.Fatal(err)
+	}
 }
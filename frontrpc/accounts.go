@@ -0,0 +1,150 @@
+package frontrpc
+
+import (
+	"github.com/mcmx73/easytron/keys"
+	"github.com/mcmx73/easytron/wallet"
+)
+
+type BalanceResult struct {
+	CoinId    wallet.CoinId            `json:"coinId"`
+	Address   string                   `json:"address"`
+	WatchOnly bool                     `json:"watchOnly"`
+	Balances  map[string]wallet.Amount `json:"balances"`
+}
+
+type TransactionsResult struct {
+	CoinId       wallet.CoinId         `json:"coinId"`
+	Address      string                `json:"address"`
+	WatchOnly    bool                  `json:"watchOnly"`
+	Transactions []*wallet.Transaction `json:"transactions"`
+}
+
+func (s *Server) registerAccountCommands() {
+	s.router.AddCommand("addWatchOnlyAddress", s.addWatchOnlyAddress)
+	s.router.AddCommand("addWatchOnlyExtendedKey", s.addWatchOnlyExtendedKey)
+	s.router.AddCommand("removeWatchOnly", s.removeWatchOnly)
+	s.router.AddCommand("listWatchOnly", s.listWatchOnly)
+	s.router.AddCommand("getBalance", s.getBalance)
+	s.router.AddCommand("getTransactions", s.getTransactions)
+	s.router.AddCommand("setLabel", s.setLabel)
+}
+
+func (s *Server) addWatchOnlyAddress(request *Rpc) (interface{}, error) {
+	coinId, address, err := coinAddressParams(request)
+	if err != nil {
+		return nil, err
+	}
+	return s.walletManager.AddWatchOnlyAddress(coinId, address, request.OptionalStringParam("label", ""))
+}
+
+// addWatchOnlyExtendedKey watches addresses of "extendedKey" xpub, "template" overrides
+// keys.AccountExternalPathTemplate
+func (s *Server) addWatchOnlyExtendedKey(request *Rpc) (interface{}, error) {
+	coinId, err := request.StringParam("coinId")
+	if err != nil {
+		return nil, err
+	}
+	extendedKey, err := request.StringParam("extendedKey")
+	if err != nil {
+		return nil, err
+	}
+	return s.walletManager.AddWatchOnlyExtendedKey(
+		wallet.CoinId(coinId),
+		extendedKey,
+		keys.PathTemplate(request.OptionalStringParam("template", "")),
+		request.OptionalStringParam("label", ""),
+	)
+}
+
+func (s *Server) removeWatchOnly(request *Rpc) (interface{}, error) {
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	if err = s.walletManager.RemoveWatchOnly(address); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (s *Server) listWatchOnly(request *Rpc) (interface{}, error) {
+	return s.walletManager.WatchOnlyAccounts(), nil
+}
+
+// getBalance fetches balances from the network, with "cached" set to "true" returns balances
+// of the last refresh at once, null when the address was never refreshed
+func (s *Server) getBalance(request *Rpc) (interface{}, error) {
+	coinId, address, err := coinAddressParams(request)
+	if err != nil {
+		return nil, err
+	}
+	s.markViewed(coinId, address)
+	var balances map[string]wallet.Amount
+	if request.OptionalStringParam("cached", "") == "true" {
+		balances, err = s.walletManager.CachedBalances(coinId, address)
+	} else {
+		balances, err = s.walletManager.GetAddressBalance(coinId, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &BalanceResult{
+		CoinId:    coinId,
+		Address:   address,
+		WatchOnly: s.walletManager.IsWatchOnly(address),
+		Balances:  balances,
+	}, nil
+}
+
+// getTransactions fetches history from the network, with "cached" set to "true" returns
+// stored history at once. Suspected address poisoning transactions are marked, "hidePoisoning"
+// set to "true" leaves them out.
+func (s *Server) getTransactions(request *Rpc) (interface{}, error) {
+	coinId, address, err := coinAddressParams(request)
+	if err != nil {
+		return nil, err
+	}
+	s.markViewed(coinId, address)
+	var transactions []*wallet.Transaction
+	if request.OptionalStringParam("cached", "") == "true" {
+		transactions, err = s.walletManager.CachedTransactions(coinId, address)
+	} else {
+		transactions, err = s.walletManager.GetAddressTransactions(coinId, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if request.OptionalStringParam("hidePoisoning", "") == "true" {
+		transactions = wallet.WithoutPoisoning(transactions)
+	}
+	return &TransactionsResult{
+		CoinId:       coinId,
+		Address:      address,
+		WatchOnly:    s.walletManager.IsWatchOnly(address),
+		Transactions: transactions,
+	}, nil
+}
+
+// setLabel names "address" with "label", empty label removes it
+func (s *Server) setLabel(request *Rpc) (interface{}, error) {
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	if err = s.walletManager.SetLabel(address, request.OptionalStringParam("label", "")); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func coinAddressParams(request *Rpc) (wallet.CoinId, string, error) {
+	coinId, err := request.StringParam("coinId")
+	if err != nil {
+		return "", "", err
+	}
+	address, err := request.StringParam("address")
+	if err != nil {
+		return "", "", err
+	}
+	return wallet.CoinId(coinId), address, nil
+}
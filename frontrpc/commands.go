@@ -1,3 +1,3 @@
 package frontrpc
 
-type Command func()
+type Command func(request *Rpc) (result interface{}, err error)
@@ -0,0 +1,72 @@
+package frontrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mcmx73/easytron/wallet"
+)
+
+func (s *Server) registerContactCommands() {
+	s.router.AddCommand("listContacts", s.listContacts)
+	s.router.AddCommand("getContact", s.getContact)
+	s.router.AddCommand("saveContact", s.saveContact)
+	s.router.AddCommand("deleteContact", s.deleteContact)
+	s.router.AddCommand("checkRecipient", s.checkRecipient)
+}
+
+// listContacts returns the address book, with optional "tag" only contacts having the tag
+func (s *Server) listContacts(request *Rpc) (interface{}, error) {
+	return s.walletManager.Contacts(request.OptionalStringParam("tag", "")), nil
+}
+
+func (s *Server) getContact(request *Rpc) (interface{}, error) {
+	id, err := request.StringParam("id")
+	if err != nil {
+		return nil, err
+	}
+	return s.walletManager.GetContact(id)
+}
+
+// saveContact creates contact from "contact" object without id or updates the contact with its id
+func (s *Server) saveContact(request *Rpc) (interface{}, error) {
+	raw, err := request.RawParam("contact")
+	if err != nil {
+		return nil, err
+	}
+	contact := new(wallet.Contact)
+	if err = json.Unmarshal(raw, contact); err != nil {
+		return nil, fmt.Errorf("%w: contact: %v", ErrInvalidParams, err)
+	}
+	return s.walletManager.SaveContact(contact)
+}
+
+func (s *Server) deleteContact(request *Rpc) (interface{}, error) {
+	id, err := request.StringParam("id")
+	if err != nil {
+		return nil, err
+	}
+	if err = s.walletManager.DeleteContact(id); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// checkRecipient returns warnings about "address" the app shows while the user enters the recipient
+func (s *Server) checkRecipient(request *Rpc) (interface{}, error) {
+	coinId, err := request.StringParam("coinId")
+	if err != nil {
+		return nil, err
+	}
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := s.walletManager.CheckRecipient(wallet.CoinId(coinId), address)
+	if err != nil {
+		return nil, err
+	}
+	if warnings == nil {
+		warnings = make([]*wallet.RecipientWarning, 0)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,133 @@
+package frontrpc
+
+import (
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+)
+
+type ImportKeyResult struct {
+	TronAddress     string `json:"tronAddress"`
+	EthereumAddress string `json:"ethereumAddress"`
+}
+
+type ExportKeyResult struct {
+	Format string `json:"format"`
+	Data   string `json:"data"`
+}
+
+func (s *Server) registerKeyCommands() {
+	s.router.AddCommand("importKey", s.importKey)
+	s.router.AddCommand("exportKey", s.exportKey)
+	s.router.AddCommand("unlockKey", s.unlockKey)
+	s.router.AddCommand("lockKey", s.lockKey)
+}
+
+// importKey imports "key" in "format" (detected when absent) and stores it encrypted with
+// "newPassword" repeated in "newPasswordConfirm". "password" decrypts keystore JSON, "passphrase"
+// and "path" derive the key from mnemonic, "address" is checked against the imported key.
+func (s *Server) importKey(request *Rpc) (interface{}, error) {
+	data, err := request.StringParam("key")
+	if err != nil {
+		return nil, err
+	}
+	newPassword, err := confirmedPasswordParam(request, "newPassword")
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.walletManager.ImportKey(data, newPassword,
+		keys.WithImportFormat(keys.KeyFormat(request.OptionalStringParam("format", ""))),
+		keys.WithImportPassword(request.OptionalStringParam("password", "")),
+		keys.WithImportPassphrase(request.OptionalStringParam("passphrase", "")),
+		keys.WithImportPath(request.OptionalStringParam("path", keys.DefaultImportPath)),
+		keys.WithExpectedAddress(request.OptionalStringParam("address", "")),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return keyAddresses(key)
+}
+
+// unlockKey decrypts stored key of "address" with its "password" so the wallet can sign with it
+func (s *Server) unlockKey(request *Rpc) (interface{}, error) {
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	password, err := request.StringParam("password")
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.walletManager.UnlockKey(address, password)
+	if err != nil {
+		return nil, err
+	}
+	return keyAddresses(key)
+}
+
+// lockKey forgets decrypted key of "address" until it is unlocked again
+func (s *Server) lockKey(request *Rpc) (interface{}, error) {
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	s.walletManager.LockKey(address)
+	return true, nil
+}
+
+func keyAddresses(key *keys.Key) (*ImportKeyResult, error) {
+	result := &ImportKeyResult{}
+	var err error
+	if result.TronAddress, err = key.TronAddress(); err != nil {
+		return nil, err
+	}
+	if result.EthereumAddress, err = key.EthereumAddress(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// exportKey exports key of "address" in "format" after checking its keystore "password",
+// keystore exports are encrypted with "exportPassword" repeated in "exportPasswordConfirm"
+func (s *Server) exportKey(request *Rpc) (interface{}, error) {
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	format, err := request.StringParam("format")
+	if err != nil {
+		return nil, err
+	}
+	password, err := request.StringParam("password")
+	if err != nil {
+		return nil, err
+	}
+	var exportPassword string
+	if keys.KeyFormat(format) == keys.KeyFormatKeyStore {
+		if exportPassword, err = confirmedPasswordParam(request, "exportPassword"); err != nil {
+			return nil, err
+		}
+	}
+	exported, err := s.walletManager.ExportKey(address, password, keys.KeyFormat(format), exportPassword)
+	if err != nil {
+		return nil, err
+	}
+	defer exported.Destroy()
+	result := &ExportKeyResult{Format: format}
+	err = exported.Use(func(data []byte) error {
+		result.Data = string(data)
+		return nil
+	})
+	return result, err
+}
+
+// confirmedPasswordParam returns required password parameter which must be repeated in <name>Confirm
+func confirmedPasswordParam(request *Rpc, name string) (string, error) {
+	password, err := request.StringParam(name)
+	if err != nil {
+		return "", err
+	}
+	if request.OptionalStringParam(name+"Confirm", "") != password {
+		return "", fmt.Errorf("%w: %s confirmation does not match", ErrInvalidParams, name)
+	}
+	return password, nil
+}
@@ -0,0 +1,64 @@
+package frontrpc
+
+import (
+	"errors"
+	"github.com/mcmx73/easytron/common/seedphrase"
+)
+
+const (
+	mnemonicSuggestionsLimit = 5
+	mnemonicCorrectionsLimit = 10
+)
+
+type InvalidWordResult struct {
+	Index       int      `json:"index"`
+	Suggestions []string `json:"suggestions"`
+}
+
+type CheckMnemonicResult struct {
+	Valid       bool                        `json:"valid"`
+	Language    seedphrase.Language         `json:"language,omitempty"`
+	Words       []string                    `json:"words,omitempty"`
+	Error       string                      `json:"error,omitempty"`
+	InvalidWord *InvalidWordResult          `json:"invalidWord,omitempty"`
+	Corrections []seedphrase.WordCorrection `json:"corrections,omitempty"`
+}
+
+func (s *Server) registerMnemonicCommands() {
+	s.router.AddCommand("checkMnemonic", s.checkMnemonic)
+}
+
+// checkMnemonic completes word prefixes of entered "mnemonic" for the restore screen. Invalid
+// word is reported with nearest wordlist words, checksum failure with single word corrections.
+func (s *Server) checkMnemonic(request *Rpc) (interface{}, error) {
+	phrase, err := request.StringParam("mnemonic")
+	if err != nil {
+		return nil, err
+	}
+	result := &CheckMnemonicResult{}
+	words, language, err := seedphrase.CompleteMnemonic(seedphrase.NormalizeMnemonic(phrase))
+	var wordErr *seedphrase.WordError
+	if errors.As(err, &wordErr) {
+		result.Error = err.Error()
+		result.Language = wordErr.Language
+		result.InvalidWord = &InvalidWordResult{Index: wordErr.Index}
+		result.InvalidWord.Suggestions, err = seedphrase.SuggestWords(wordErr.Word, wordErr.Language, mnemonicSuggestionsLimit)
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Language, result.Words = language, words
+	err = seedphrase.ValidateMnemonic(words)
+	switch {
+	case err == nil:
+		result.Valid = true
+	case errors.Is(err, seedphrase.ErrInvalidChecksum):
+		result.Error = err.Error()
+		result.Corrections, err = seedphrase.ChecksumCorrections(words, language, mnemonicCorrectionsLimit)
+		return result, err
+	default:
+		result.Error = err.Error()
+	}
+	return result, nil
+}
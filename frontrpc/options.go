@@ -7,3 +7,10 @@ func WithWalletManager(wm *wallet.Manager) WithServerOption {
 		c.walletManager = wm
 	}
 }
+
+// WithSyncer prioritizes addresses requested by the app and serves getSyncEvents
+func WithSyncer(syncer *wallet.Syncer) WithServerOption {
+	return func(c *Server) {
+		c.syncer = syncer
+	}
+}
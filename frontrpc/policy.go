@@ -0,0 +1,57 @@
+package frontrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mcmx73/easytron/wallet"
+)
+
+func (s *Server) registerPolicyCommands() {
+	s.router.AddCommand("getPolicy", s.getPolicy)
+	s.router.AddCommand("setPolicy", s.setPolicy)
+	s.router.AddCommand("unlockPolicy", s.unlockPolicy)
+}
+
+// getPolicy returns the spending policy, null when there is none
+func (s *Server) getPolicy(request *Rpc) (interface{}, error) {
+	if s.walletManager.PolicyMissing() {
+		return nil, wallet.ErrPolicyMissing
+	}
+	if s.walletManager.PolicyLocked() {
+		return nil, wallet.ErrPolicyLocked
+	}
+	return s.walletManager.Policy(), nil
+}
+
+// setPolicy replaces the spending policy with "policy" object, "password" is the wallet password
+// of the keystore
+func (s *Server) setPolicy(request *Rpc) (interface{}, error) {
+	raw, err := request.RawParam("policy")
+	if err != nil {
+		return nil, err
+	}
+	policy := new(wallet.Policy)
+	if err = json.Unmarshal(raw, policy); err != nil {
+		return nil, fmt.Errorf("%w: policy: %v", ErrInvalidParams, err)
+	}
+	password, err := request.StringParam("password")
+	if err != nil {
+		return nil, err
+	}
+	if err = s.walletManager.SetPolicy(policy, password); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// unlockPolicy activates the stored spending policy with the wallet "password"
+func (s *Server) unlockPolicy(request *Rpc) (interface{}, error) {
+	password, err := request.StringParam("password")
+	if err != nil {
+		return nil, err
+	}
+	if err = s.walletManager.UnlockPolicy(password); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
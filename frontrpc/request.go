@@ -1 +1,57 @@
 package frontrpc
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidParams = errors.New(ERROR_MESSAGE_INVALID_PARAMS)
+
+// StringParam returns required non-empty string parameter
+func (r *Rpc) StringParam(name string) (string, error) {
+	value, ok := r.Params[name].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: %s is required", ErrInvalidParams, name)
+	}
+	return value, nil
+}
+
+// OptionalStringParam returns string parameter or def when it is absent
+func (r *Rpc) OptionalStringParam(name, def string) string {
+	value, ok := r.Params[name].(string)
+	if !ok || value == "" {
+		return def
+	}
+	return value
+}
+
+// HexParam returns required 0x prefixed hex parameter as bytes
+func (r *Rpc) HexParam(name string) ([]byte, error) {
+	value, err := r.StringParam(name)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(value, "0x") && !strings.HasPrefix(value, "0X") {
+		return nil, fmt.Errorf("%w: %s must be 0x prefixed hex", ErrInvalidParams, name)
+	}
+	data, err := hex.DecodeString(value[2:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidParams, name, err)
+	}
+	return data, nil
+}
+
+// RawParam returns JSON of the parameter, string parameters are treated as already encoded JSON
+func (r *Rpc) RawParam(name string) ([]byte, error) {
+	value, ok := r.Params[name]
+	if !ok || value == nil {
+		return nil, fmt.Errorf("%w: %s is required", ErrInvalidParams, name)
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
@@ -1,5 +1,66 @@
 package frontrpc
 
+import (
+	"errors"
+	"github.com/mcmx73/easytron/wallet"
+)
+
+func NewRouter() *Router {
+	return &Router{
+		Commands: make(map[string]Command),
+	}
+}
+
 type Router struct {
 	Commands map[string]Command
 }
+
+func (r *Router) AddCommand(method string, command Command) {
+	r.Commands[method] = command
+}
+
+// Route executes the command of the request method and wraps its result or error into response
+func (r *Router) Route(request *Rpc) *RpcResponse {
+	response := &RpcResponse{
+		Id:      request.Id,
+		Jsonrpc: JSON_RPC_VERSION,
+	}
+	command, ok := r.Commands[request.Method]
+	if !ok {
+		response.Error = &RpcError{Code: ERROR_CODE_METHOD_NOT_FOUND, Message: ERROR_MESSAGE_METHOD_NOT_FOUND}
+		return response
+	}
+	result, err := command(request)
+	if err != nil {
+		response.Error = toRpcError(err)
+		return response
+	}
+	response.Result = result
+	return response
+}
+
+func toRpcError(err error) *RpcError {
+	var rpcErr *RpcError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	var violation *wallet.PolicyViolation
+	if errors.As(err, &violation) {
+		return &RpcError{Code: ERROR_CODE_POLICY_VIOLATION, Message: err.Error(), Data: violation}
+	}
+	switch {
+	case errors.Is(err, ErrInvalidParams):
+		return &RpcError{Code: ERROR_CODE_INVALID_PARAMS, Message: err.Error()}
+	case errors.Is(err, wallet.ErrWatchOnly):
+		return &RpcError{Code: ERROR_CODE_WATCH_ONLY, Message: err.Error()}
+	case errors.Is(err, wallet.ErrQuoteNotFound), errors.Is(err, wallet.ErrQuoteExpired):
+		return &RpcError{Code: ERROR_CODE_QUOTE_EXPIRED, Message: err.Error()}
+	case errors.Is(err, wallet.ErrPoisoningRecipient):
+		return &RpcError{Code: ERROR_CODE_POISONING_RECIPIENT, Message: err.Error()}
+	case errors.Is(err, wallet.ErrPolicyLocked), errors.Is(err, wallet.ErrPolicyMissing):
+		return &RpcError{Code: ERROR_CODE_POLICY_VIOLATION, Message: err.Error()}
+	case errors.Is(err, wallet.ErrNotImplemented):
+		return &RpcError{Code: ERROR_CODE_NOT_IMPLEMENTED, Message: err.Error()}
+	}
+	return &RpcError{Code: ERROR_CODE_SERVER_ERROR, Message: err.Error()}
+}
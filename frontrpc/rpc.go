@@ -9,13 +9,26 @@ const (
 	ERROR_MESSAGE_INVALID_REQUEST  = "invalid request"
 	ERROR_CODE_METHOD_NOT_FOUND    = -32601
 	ERROR_MESSAGE_METHOD_NOT_FOUND = "method not found"
+	ERROR_CODE_INVALID_PARAMS      = -32602
+	ERROR_MESSAGE_INVALID_PARAMS   = "invalid params"
 	ERROR_CODE_SERVER_ERROR        = -32000
 	ERROR_MESSAGE_SERVER_ERROR     = "server error"
+	ERROR_CODE_WATCH_ONLY          = -32001
+	ERROR_CODE_QUOTE_EXPIRED       = -32002
+	ERROR_CODE_POISONING_RECIPIENT = -32003
+	ERROR_CODE_POLICY_VIOLATION    = -32004
+	ERROR_CODE_NOT_IMPLEMENTED     = -32005
 )
 
 type RpcError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// Data is structured error description, e.g. *wallet.PolicyViolation
+	Data interface{} `json:"data,omitempty"`
+}
+
+func (e *RpcError) Error() string {
+	return e.Message
 }
 
 type Rpc struct {
@@ -25,3 +38,10 @@ type Rpc struct {
 	Method       string                 `json:"method"`
 	Params       map[string]interface{} `json:"params,omitempty"`
 }
+
+type RpcResponse struct {
+	Id      RequestId   `json:"id"`
+	Jsonrpc string      `json:"jsonrpc"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *RpcError   `json:"error,omitempty"`
+}
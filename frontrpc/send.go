@@ -0,0 +1,79 @@
+package frontrpc
+
+import (
+	"fmt"
+	"github.com/mcmx73/easytron/wallet"
+)
+
+type ConfirmSendResult struct {
+	Hash string `json:"hash"`
+}
+
+func (s *Server) registerSendCommands() {
+	s.router.AddCommand("prepareSend", s.prepareSend)
+	s.router.AddCommand("confirmSend", s.confirmSend)
+	s.router.AddCommand("cancelSend", s.cancelSend)
+}
+
+// prepareSend quotes transfer of decimal "amount" of "coinId" from "from" to "to" with optional
+// "memo". The app shows the quote summary and fee, then calls confirmSend with
+// the quote id before it expires. Recipient from suspected address poisoning transaction fails
+// with ERROR_CODE_POISONING_RECIPIENT until the user confirms it and the app sets
+// "poisoningConfirmed" to "true".
+func (s *Server) prepareSend(request *Rpc) (interface{}, error) {
+	coinId, err := request.StringParam("coinId")
+	if err != nil {
+		return nil, err
+	}
+	from, err := request.StringParam("from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := request.StringParam("to")
+	if err != nil {
+		return nil, err
+	}
+	amount, err := s.amountParam(request, wallet.CoinId(coinId), "amount")
+	if err != nil {
+		return nil, err
+	}
+	options := []wallet.WithSendOption{wallet.WithSendMemo(request.OptionalStringParam("memo", ""))}
+	if request.OptionalStringParam("poisoningConfirmed", "") == "true" {
+		options = append(options, wallet.WithSendPoisoningConfirmed())
+	}
+	return s.walletManager.PrepareSend(wallet.CoinId(coinId), from, to, amount, options...)
+}
+
+func (s *Server) confirmSend(request *Rpc) (interface{}, error) {
+	quoteId, err := request.StringParam("quoteId")
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.walletManager.ConfirmSend(quoteId)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfirmSendResult{Hash: hash}, nil
+}
+
+func (s *Server) cancelSend(request *Rpc) (interface{}, error) {
+	quoteId, err := request.StringParam("quoteId")
+	if err != nil {
+		return nil, err
+	}
+	s.walletManager.CancelSend(quoteId)
+	return true, nil
+}
+
+// amountParam parses decimal string parameter with decimals of the coin
+func (s *Server) amountParam(request *Rpc, coinId wallet.CoinId, name string) (wallet.Amount, error) {
+	value, err := request.StringParam(name)
+	if err != nil {
+		return wallet.Amount{}, err
+	}
+	amount, err := s.walletManager.ParseAmount(coinId, value)
+	if err != nil {
+		return wallet.Amount{}, fmt.Errorf("%w: %s: %v", ErrInvalidParams, name, err)
+	}
+	return amount, nil
+}
@@ -1,21 +1,61 @@
 package frontrpc
 
-import "github.com/mcmx73/easytron/wallet"
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mcmx73/easytron/wallet"
+)
 
 type WithServerOption func(*Server)
 
 func NewServer(options ...WithServerOption) *Server {
-	s := &Server{}
+	s := &Server{
+		router: NewRouter(),
+	}
 	for _, opt := range options {
 		opt(s)
 	}
+	s.registerSigningCommands()
+	s.registerKeyCommands()
+	s.registerAccountCommands()
+	s.registerMnemonicCommands()
+	s.registerSyncCommands()
+	s.registerSendCommands()
+	s.registerContactCommands()
+	s.registerPolicyCommands()
 	return s
 }
 
 type Server struct {
 	walletManager *wallet.Manager
+	syncer        *wallet.Syncer
+	router        *Router
 }
 
 func (s *Server) Start() (err error) {
 	return nil
 }
+
+// HandleMessage decodes JSON-RPC request, routes it and returns encoded response
+func (s *Server) HandleMessage(data []byte) []byte {
+	request := &Rpc{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var response *RpcResponse
+	if err := decoder.Decode(request); err != nil {
+		response = &RpcResponse{
+			Jsonrpc: JSON_RPC_VERSION,
+			Error:   &RpcError{Code: ERROR_CODE_PARSE_ERROR, Message: ERROR_MESSAGE_PARSE_ERROR},
+		}
+	} else if request.Jsonrpc != JSON_RPC_VERSION || request.Method == "" {
+		response = &RpcResponse{
+			Id:      request.Id,
+			Jsonrpc: JSON_RPC_VERSION,
+			Error:   &RpcError{Code: ERROR_CODE_INVALID_REQUEST, Message: ERROR_MESSAGE_INVALID_REQUEST},
+		}
+	} else {
+		response = s.router.Route(request)
+	}
+	out, _ := json.Marshal(response)
+	return out
+}
@@ -0,0 +1,268 @@
+package frontrpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+	"github.com/mcmx73/easytron/keys/eip712"
+	"github.com/mcmx73/easytron/wallet"
+)
+
+const (
+	MESSAGE_ENCODING_TEXT = "text"
+	MESSAGE_ENCODING_HEX  = "hex"
+)
+
+type SignPreview struct {
+	Preview string `json:"preview"`
+	Hash    string `json:"hash"`
+}
+
+type SignResult struct {
+	SignPreview
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+}
+
+type VerifyResult struct {
+	Valid  bool   `json:"valid"`
+	Signer string `json:"signer"`
+}
+
+func (s *Server) registerSigningCommands() {
+	s.router.AddCommand("ethPreviewMessage", s.ethPreviewMessage)
+	s.router.AddCommand("ethSignMessage", s.ethSignMessage)
+	s.router.AddCommand("ethVerifyMessage", s.ethVerifyMessage)
+	s.router.AddCommand("ethPreviewTypedData", s.ethPreviewTypedData)
+	s.router.AddCommand("ethSignTypedData", s.ethSignTypedData)
+	s.router.AddCommand("ethVerifyTypedData", s.ethVerifyTypedData)
+	s.router.AddCommand("tronPreviewMessage", s.tronPreviewMessage)
+	s.router.AddCommand("tronSignMessage", s.tronSignMessage)
+	s.router.AddCommand("tronVerifyMessage", s.tronVerifyMessage)
+}
+
+func (s *Server) ethPreviewMessage(request *Rpc) (interface{}, error) {
+	message, err := messageParam(request)
+	if err != nil {
+		return nil, err
+	}
+	return ethMessagePreview(message), nil
+}
+
+func (s *Server) ethSignMessage(request *Rpc) (interface{}, error) {
+	message, err := messageParam(request)
+	if err != nil {
+		return nil, err
+	}
+	address, key, err := s.signingKey(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.SignEthereumMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	return &SignResult{
+		SignPreview: *ethMessagePreview(message),
+		Address:     address,
+		Signature:   "0x" + hex.EncodeToString(signature),
+	}, nil
+}
+
+func (s *Server) ethVerifyMessage(request *Rpc) (interface{}, error) {
+	message, err := messageParam(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := request.HexParam("signature")
+	if err != nil {
+		return nil, err
+	}
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	signer, err := keys.RecoverEthereumMessageAddress(message, signature)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := keys.VerifyEthereumMessage(message, signature, address)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Valid: valid, Signer: signer}, nil
+}
+
+func (s *Server) ethPreviewTypedData(request *Rpc) (interface{}, error) {
+	typedData, err := typedDataParam(request)
+	if err != nil {
+		return nil, err
+	}
+	return typedDataPreview(typedData)
+}
+
+func (s *Server) ethSignTypedData(request *Rpc) (interface{}, error) {
+	typedData, err := typedDataParam(request)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := typedDataPreview(typedData)
+	if err != nil {
+		return nil, err
+	}
+	address, key, err := s.signingKey(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.SignTypedData(typedData)
+	if err != nil {
+		return nil, err
+	}
+	return &SignResult{
+		SignPreview: *preview,
+		Address:     address,
+		Signature:   "0x" + hex.EncodeToString(signature),
+	}, nil
+}
+
+func (s *Server) ethVerifyTypedData(request *Rpc) (interface{}, error) {
+	typedData, err := typedDataParam(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := request.HexParam("signature")
+	if err != nil {
+		return nil, err
+	}
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	signer, err := keys.RecoverTypedDataAddress(typedData, signature)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := keys.VerifyTypedData(typedData, signature, address)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Valid: valid, Signer: signer}, nil
+}
+
+func (s *Server) tronPreviewMessage(request *Rpc) (interface{}, error) {
+	message, err := messageParam(request)
+	if err != nil {
+		return nil, err
+	}
+	return tronMessagePreview(message), nil
+}
+
+func (s *Server) tronSignMessage(request *Rpc) (interface{}, error) {
+	message, err := messageParam(request)
+	if err != nil {
+		return nil, err
+	}
+	address, key, err := s.signingKey(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.SignTronMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	return &SignResult{
+		SignPreview: *tronMessagePreview(message),
+		Address:     address,
+		Signature:   "0x" + hex.EncodeToString(signature),
+	}, nil
+}
+
+func (s *Server) tronVerifyMessage(request *Rpc) (interface{}, error) {
+	message, err := messageParam(request)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := request.HexParam("signature")
+	if err != nil {
+		return nil, err
+	}
+	address, err := request.StringParam("address")
+	if err != nil {
+		return nil, err
+	}
+	signer, err := keys.RecoverTronMessageAddress(message, signature)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := keys.VerifyTronMessage(message, signature, address)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResult{Valid: valid, Signer: signer}, nil
+}
+
+func (s *Server) signingKey(request *Rpc) (string, *keys.Key, error) {
+	address, err := request.StringParam("address")
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := s.walletManager.GetKey(address)
+	if errors.Is(err, wallet.ErrWatchOnly) {
+		return "", nil, &RpcError{Code: ERROR_CODE_WATCH_ONLY, Message: err.Error()}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return address, key, nil
+}
+
+// messageParam decodes "message" as UTF-8 text or, with encoding "hex", as 0x prefixed hex
+func messageParam(request *Rpc) ([]byte, error) {
+	switch request.OptionalStringParam("encoding", MESSAGE_ENCODING_TEXT) {
+	case MESSAGE_ENCODING_TEXT:
+		message, err := request.StringParam("message")
+		return []byte(message), err
+	case MESSAGE_ENCODING_HEX:
+		return request.HexParam("message")
+	}
+	return nil, fmt.Errorf("%w: unknown encoding", ErrInvalidParams)
+}
+
+func typedDataParam(request *Rpc) (*eip712.TypedData, error) {
+	raw, err := request.RawParam("typedData")
+	if err != nil {
+		return nil, err
+	}
+	typedData, err := eip712.ParseTypedData(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: typedData: %v", ErrInvalidParams, err)
+	}
+	return typedData, nil
+}
+
+func ethMessagePreview(message []byte) *SignPreview {
+	return &SignPreview{
+		Preview: keys.MessagePreview(message),
+		Hash:    "0x" + hex.EncodeToString(keys.EthereumMessageHash(message)),
+	}
+}
+
+func tronMessagePreview(message []byte) *SignPreview {
+	return &SignPreview{
+		Preview: keys.MessagePreview(message),
+		Hash:    "0x" + hex.EncodeToString(keys.TronMessageHash(message)),
+	}
+}
+
+func typedDataPreview(typedData *eip712.TypedData) (*SignPreview, error) {
+	preview, err := typedData.Preview()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := typedData.SigningHash()
+	if err != nil {
+		return nil, err
+	}
+	return &SignPreview{Preview: preview, Hash: "0x" + hex.EncodeToString(hash)}, nil
+}
@@ -0,0 +1,34 @@
+package frontrpc
+
+import "github.com/mcmx73/easytron/wallet"
+
+const maxSyncEvents = 100
+
+func (s *Server) registerSyncCommands() {
+	s.router.AddCommand("getSyncEvents", s.getSyncEvents)
+}
+
+// getSyncEvents returns up to maxSyncEvents pending sync events, the app polls it to show
+// incoming payments
+func (s *Server) getSyncEvents(request *Rpc) (interface{}, error) {
+	events := make([]wallet.SyncEvent, 0)
+	if s.syncer == nil {
+		return events, nil
+	}
+	for len(events) < maxSyncEvents {
+		select {
+		case event := <-s.syncer.Events():
+			events = append(events, event)
+		default:
+			return events, nil
+		}
+	}
+	return events, nil
+}
+
+// markViewed prioritizes sync of the address the app shows
+func (s *Server) markViewed(coinId wallet.CoinId, address string) {
+	if s.syncer != nil {
+		s.syncer.MarkViewed(coinId, address)
+	}
+}
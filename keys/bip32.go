@@ -0,0 +1,274 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"github.com/mcmx73/easytron/common/base58"
+	"github.com/mcmx73/easytron/common/math"
+	"github.com/mcmx73/easytron/common/secret"
+	"github.com/mcmx73/easytron/keys/secp256k1"
+	"golang.org/x/crypto/ripemd160"
+	"math/big"
+)
+
+// BIP32 hierarchical deterministic keys
+// See https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+
+const (
+	HardenedKeyStart = 0x80000000
+
+	extendedKeyLength = 78
+	minSeedLength     = 16
+	maxSeedLength     = 64
+)
+
+var (
+	xprvVersion   = [4]byte{0x04, 0x88, 0xad, 0xe4}
+	xpubVersion   = [4]byte{0x04, 0x88, 0xb2, 0x1e}
+	masterKeySalt = []byte("Bitcoin seed")
+)
+
+// ExtendedKey is a private or public-only BIP32 key with its chain code
+type ExtendedKey struct {
+	depth             uint8
+	parentFingerprint [4]byte
+	childNumber       uint32
+	chainCode         []byte
+	// 32-byte private key or 33-byte compressed public key
+	key       []byte
+	isPrivate bool
+}
+
+// NewMasterKey creates master extended private key from the seed
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < minSeedLength || len(seed) > maxSeedLength {
+		return nil, ErrInvalidSeedLength
+	}
+	mac := hmac.New(sha512.New, masterKeySalt)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	if !validPrivateKeyScalar(new(big.Int).SetBytes(sum[:32])) {
+		return nil, ErrInvalidChildKey
+	}
+	return &ExtendedKey{
+		chainCode: sum[32:],
+		key:       sum[:32],
+		isPrivate: true,
+	}, nil
+}
+
+// Child derives child key with the index, indexes from HardenedKeyStart are hardened
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.depth == 255 {
+		return nil, ErrMaxDepthExceeded
+	}
+	hardened := index >= HardenedKeyStart
+	if hardened && !k.isPrivate {
+		return nil, ErrHardenedFromPublicKey
+	}
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(data, 0)
+		data = append(data, k.key...)
+	} else {
+		data = append(data, k.PublicKeyBytes()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	curve := secp256k1.P256k1()
+	n := curve.Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, ErrInvalidChildKey
+	}
+	child := &ExtendedKey{
+		depth:             k.depth + 1,
+		parentFingerprint: k.Fingerprint(),
+		childNumber:       index,
+		chainCode:         sum[32:],
+		isPrivate:         k.isPrivate,
+	}
+	if k.isPrivate {
+		childKey := il.Add(il, new(big.Int).SetBytes(k.key))
+		childKey.Mod(childKey, n)
+		if childKey.Sign() == 0 {
+			return nil, ErrInvalidChildKey
+		}
+		child.key = math.PaddedBigBytes(childKey, 32)
+		return child, nil
+	}
+	parentX, parentY := secp256k1.UnmarshalCompressed(curve, k.key)
+	if parentX == nil {
+		return nil, ErrInvalidExtendedKey
+	}
+	ilX, ilY := curve.ScalarBaseMult(sum[:32])
+	x, y := curve.Add(ilX, ilY, parentX, parentY)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, ErrInvalidChildKey
+	}
+	child.key = secp256k1.MarshalCompressed(curve, x, y)
+	return child, nil
+}
+
+// Derive derives descendant key following the indexes from this key
+func (k *ExtendedKey) Derive(indexes ...uint32) (key *ExtendedKey, err error) {
+	key = k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Neuter returns public-only extended key, which can derive non-hardened children only
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.isPrivate {
+		return k
+	}
+	return &ExtendedKey{
+		depth:             k.depth,
+		parentFingerprint: k.parentFingerprint,
+		childNumber:       k.childNumber,
+		chainCode:         append([]byte(nil), k.chainCode...),
+		key:               k.PublicKeyBytes(),
+	}
+}
+
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.isPrivate
+}
+
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+func (k *ExtendedKey) ChildNumber() uint32 {
+	return k.childNumber
+}
+
+// Fingerprint returns first 4 bytes of HASH160 of the compressed public key
+func (k *ExtendedKey) Fingerprint() (fingerprint [4]byte) {
+	sha := sha256.Sum256(k.PublicKeyBytes())
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	copy(fingerprint[:], hasher.Sum(nil))
+	return fingerprint
+}
+
+// PublicKeyBytes returns 33-byte compressed public key
+func (k *ExtendedKey) PublicKeyBytes() []byte {
+	if !k.isPrivate {
+		return k.key
+	}
+	curve := secp256k1.P256k1()
+	x, y := curve.ScalarBaseMult(k.key)
+	return secp256k1.MarshalCompressed(curve, x, y)
+}
+
+// ECDSAPrivateKey returns private key of the extended private key
+func (k *ExtendedKey) ECDSAPrivateKey() (*ecdsa.PrivateKey, error) {
+	if !k.isPrivate {
+		return nil, ErrKeyIsEmpty
+	}
+	privateKey, _ := ECDSAKeysFromPrivateKeyBytes(k.key)
+	return privateKey, nil
+}
+
+// ECDSAPublicKey returns public key of the extended key
+func (k *ExtendedKey) ECDSAPublicKey() (*ecdsa.PublicKey, error) {
+	curve := secp256k1.P256k1()
+	x, y := secp256k1.UnmarshalCompressed(curve, k.PublicKeyBytes())
+	if x == nil {
+		return nil, ErrInvalidExtendedKey
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// Key returns signing key for private extended keys and public-only key otherwise
+func (k *ExtendedKey) Key() (*Key, error) {
+	if k.isPrivate {
+		return NewKey(WithPrivateKeyBytes(append([]byte(nil), k.key...))), nil
+	}
+	publicKey, err := k.ECDSAPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(WithECDSAPublicKey(publicKey)), nil
+}
+
+// Destroy zeroes the key and the chain code, the extended key is unusable afterwards
+func (k *ExtendedKey) Destroy() {
+	secret.Wipe(k.key)
+	secret.Wipe(k.chainCode)
+}
+
+// String returns base58check serialized xprv or xpub
+func (k *ExtendedKey) String() string {
+	data := make([]byte, 0, extendedKeyLength)
+	if k.isPrivate {
+		data = append(data, xprvVersion[:]...)
+	} else {
+		data = append(data, xpubVersion[:]...)
+	}
+	data = append(data, k.depth)
+	data = append(data, k.parentFingerprint[:]...)
+	data = binary.BigEndian.AppendUint32(data, k.childNumber)
+	data = append(data, k.chainCode...)
+	if k.isPrivate {
+		data = append(data, 0)
+	}
+	data = append(data, k.key...)
+	// CheckEncode prepends single version byte, the first byte of 4-byte version is passed as it
+	return base58.CheckEncode(data[1:], data[0])
+}
+
+// ParseExtendedKey decodes base58check serialized xprv or xpub
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	payload, version, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, ErrInvalidExtendedKey
+	}
+	data := append([]byte{version}, payload...)
+	if len(data) != extendedKeyLength {
+		return nil, ErrInvalidExtendedKey
+	}
+	k := &ExtendedKey{
+		depth:       data[4],
+		childNumber: binary.BigEndian.Uint32(data[9:13]),
+		chainCode:   data[13:45],
+	}
+	copy(k.parentFingerprint[:], data[5:9])
+	if k.depth == 0 && (k.childNumber != 0 || k.parentFingerprint != [4]byte{}) {
+		return nil, ErrInvalidExtendedKey
+	}
+	keyData := data[45:]
+	switch {
+	case bytes.Equal(data[:4], xprvVersion[:]):
+		if keyData[0] != 0 || !validPrivateKeyScalar(new(big.Int).SetBytes(keyData[1:])) {
+			return nil, ErrInvalidExtendedKey
+		}
+		k.key = keyData[1:]
+		k.isPrivate = true
+	case bytes.Equal(data[:4], xpubVersion[:]):
+		if x, _ := secp256k1.UnmarshalCompressed(secp256k1.P256k1(), keyData); x == nil {
+			return nil, ErrInvalidExtendedKey
+		}
+		k.key = keyData
+	default:
+		return nil, ErrInvalidExtendedKey
+	}
+	return k, nil
+}
+
+// validPrivateKeyScalar checks that private key is in [1, N-1]
+func validPrivateKeyScalar(d *big.Int) bool {
+	return d.Sign() > 0 && d.Cmp(secp256k1.P256k1().Params().N) < 0
+}
@@ -0,0 +1,124 @@
+package keys
+
+import (
+	"errors"
+	"github.com/mcmx73/easytron/common/hexutil"
+	"testing"
+)
+
+// Test vector 1 from https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
+var bip32TestVector1 = []struct {
+	path []uint32
+	xpub string
+	xprv string
+}{
+	{
+		nil,
+		"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+	},
+	{
+		[]uint32{HardenedKeyStart},
+		"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+	},
+	{
+		[]uint32{HardenedKeyStart, 1},
+		"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		"xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+	},
+	{
+		[]uint32{HardenedKeyStart, 1, HardenedKeyStart + 2},
+		"xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+		"xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM",
+	},
+	{
+		[]uint32{HardenedKeyStart, 1, HardenedKeyStart + 2, 2},
+		"xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		"xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334",
+	},
+	{
+		[]uint32{HardenedKeyStart, 1, HardenedKeyStart + 2, 2, 1000000000},
+		"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		"xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+	},
+}
+
+func TestBIP32Vectors(t *testing.T) {
+	master, err := NewMasterKey(hexutil.FromHex("000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range bip32TestVector1 {
+		key, err := master.Derive(v.path...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.String() != v.xprv {
+			t.Errorf("%v xprv mismatch: %s", v.path, key.String())
+		}
+		if key.Neuter().String() != v.xpub {
+			t.Errorf("%v xpub mismatch: %s", v.path, key.Neuter().String())
+		}
+		parsed, err := ParseExtendedKey(v.xprv)
+		if err != nil || parsed.String() != v.xprv {
+			t.Errorf("%v ParseExtendedKey xprv failed: %v", v.path, err)
+		}
+		parsed, err = ParseExtendedKey(v.xpub)
+		if err != nil || parsed.String() != v.xpub || parsed.IsPrivate() {
+			t.Errorf("%v ParseExtendedKey xpub failed: %v", v.path, err)
+		}
+	}
+}
+
+func TestBIP32PublicDerivation(t *testing.T) {
+	master, err := NewMasterKey(hexutil.FromHex("fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := master.Derive(HardenedKeyStart+44, HardenedKeyStart+195, HardenedKeyStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPublic := account.Neuter()
+	if _, err = accountPublic.Child(HardenedKeyStart); !errors.Is(err, ErrHardenedFromPublicKey) {
+		t.Error("Hardened derivation from public key is not rejected:", err)
+	}
+	for index := uint32(0); index < 5; index++ {
+		private, err := account.Derive(0, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		public, err := accountPublic.Derive(0, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if private.Neuter().String() != public.String() {
+			t.Errorf("Public derivation mismatch for index %d", index)
+		}
+		privateKey, err := private.Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		publicKey, err := public.Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		privateAddress, _ := privateKey.TronAddress()
+		publicAddress, _ := publicKey.TronAddress()
+		if privateAddress != publicAddress {
+			t.Errorf("Address mismatch for index %d: %s != %s", index, privateAddress, publicAddress)
+		}
+	}
+	xpub := accountPublic.String()
+	account.Destroy()
+	if accountPublic.String() != xpub {
+		t.Error("Destroying private key changed its neutered key")
+	}
+	if _, err = NewMasterKey(make([]byte, 15)); !errors.Is(err, ErrInvalidSeedLength) {
+		t.Error("Short seed is not rejected:", err)
+	}
+	if _, err = ParseExtendedKey("xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet9"); !errors.Is(err, ErrInvalidExtendedKey) {
+		t.Error("Corrupted xpub is not rejected:", err)
+	}
+}
@@ -0,0 +1,114 @@
+package keys
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BIP44 derivation paths m / purpose' / coin_type' / account' / change / address_index
+// See https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki
+
+const (
+	BIP44Purpose     = 44
+	CoinTypeEthereum = 60
+	CoinTypeTron     = 195
+
+	pathPrefix         = "m/"
+	accountPlaceholder = "{account}"
+	indexPlaceholder   = "{index}"
+)
+
+// PathTemplate is derivation path with {account} and {index} placeholders, e.g. "m/44'/195'/{account}'/0/{index}"
+type PathTemplate string
+
+// Templates of popular wallets, all of them use external chain (change = 0)
+const (
+	TronBIP44PathTemplate      PathTemplate = "m/44'/195'/{account}'/0/{index}"
+	TronLinkPathTemplate       PathTemplate = "m/44'/195'/0'/0/{index}"
+	LedgerLiveTronPathTemplate PathTemplate = "m/44'/195'/{account}'/0/0"
+	EthereumBIP44PathTemplate  PathTemplate = "m/44'/60'/{account}'/0/{index}"
+	MetaMaskPathTemplate       PathTemplate = "m/44'/60'/0'/0/{index}"
+	LedgerLivePathTemplate     PathTemplate = "m/44'/60'/{account}'/0/0"
+	LedgerLegacyPathTemplate   PathTemplate = "m/44'/60'/0'/{index}"
+)
+
+// AccountExternalPathTemplate derives external chain addresses from account level extended public
+// key, like xpub of m/44'/195'/0' exported by hardware wallets
+const AccountExternalPathTemplate PathTemplate = "m/0/{index}"
+
+func (t PathTemplate) HasAccount() bool {
+	return strings.Contains(string(t), accountPlaceholder)
+}
+
+func (t PathTemplate) HasIndex() bool {
+	return strings.Contains(string(t), indexPlaceholder)
+}
+
+// Path substitutes account and index into the template and parses the result
+func (t PathTemplate) Path(account, index uint32) (DerivationPath, error) {
+	if account >= HardenedKeyStart || index >= HardenedKeyStart {
+		return nil, fmt.Errorf("%w: account and index must be below 2^31", ErrInvalidDerivationPath)
+	}
+	path := strings.ReplaceAll(string(t), accountPlaceholder, strconv.FormatUint(uint64(account), 10))
+	path = strings.ReplaceAll(path, indexPlaceholder, strconv.FormatUint(uint64(index), 10))
+	return ParseDerivationPath(path)
+}
+
+// Validate checks that the template produces valid derivation paths and has a placeholder,
+// a template without one derives the same address at every position
+func (t PathTemplate) Validate() error {
+	if !t.HasAccount() && !t.HasIndex() {
+		return fmt.Errorf("%w: %s has neither %s nor %s", ErrInvalidDerivationPath, t, accountPlaceholder, indexPlaceholder)
+	}
+	_, err := t.Path(0, 0)
+	return err
+}
+
+// DerivationPath is the list of child indexes from master key
+type DerivationPath []uint32
+
+// ParseDerivationPath parses path like "m/44'/195'/0'/0/0", hardened levels are marked with ', h or H
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	if !strings.HasPrefix(path, pathPrefix) && path != "m" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDerivationPath, path)
+	}
+	components := strings.Split(path, "/")[1:]
+	result := make(DerivationPath, 0, len(components))
+	for _, component := range components {
+		hardened := false
+		if strings.HasSuffix(component, "'") || strings.HasSuffix(component, "h") || strings.HasSuffix(component, "H") {
+			hardened = true
+			component = component[:len(component)-1]
+		}
+		index, err := strconv.ParseUint(component, 10, 32)
+		if err != nil || index >= HardenedKeyStart {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDerivationPath, path)
+		}
+		if hardened {
+			index += HardenedKeyStart
+		}
+		result = append(result, uint32(index))
+	}
+	return result, nil
+}
+
+func (p DerivationPath) String() string {
+	var out strings.Builder
+	out.WriteString("m")
+	for _, index := range p {
+		out.WriteByte('/')
+		if index >= HardenedKeyStart {
+			out.WriteString(strconv.FormatUint(uint64(index-HardenedKeyStart), 10))
+			out.WriteByte('\'')
+		} else {
+			out.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+	return out.String()
+}
+
+// DerivePath derives descendant key of the master key by the path
+func (k *ExtendedKey) DerivePath(path DerivationPath) (*ExtendedKey, error) {
+	return k.Derive(path...)
+}
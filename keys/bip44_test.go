@@ -0,0 +1,86 @@
+package keys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44'/195h/0H/0/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := DerivationPath{HardenedKeyStart + 44, HardenedKeyStart + 195, HardenedKeyStart, 0, 7}
+	if len(path) != len(expected) {
+		t.Fatalf("path length %d, expected %d", len(path), len(expected))
+	}
+	for i := range path {
+		if path[i] != expected[i] {
+			t.Errorf("component %d: %d, expected %d", i, path[i], expected[i])
+		}
+	}
+	if path.String() != "m/44'/195'/0'/0/7" {
+		t.Errorf("formatted path %s", path.String())
+	}
+	for _, invalid := range []string{"", "44'/0'", "m/", "m/x", "m/2147483648", "m/0''"} {
+		if _, err = ParseDerivationPath(invalid); !errors.Is(err, ErrInvalidDerivationPath) {
+			t.Errorf("path %q: expected ErrInvalidDerivationPath, got %v", invalid, err)
+		}
+	}
+	path, err = TronLinkPathTemplate.Path(5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.String() != "m/44'/195'/0'/0/3" {
+		t.Errorf("TronLink path %s", path.String())
+	}
+	if err = PathTemplate("m/44'/{coin}'/0'").Validate(); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Errorf("expected ErrInvalidDerivationPath, got %v", err)
+	}
+	if err = PathTemplate("m/44'/195'/0'/0/0").Validate(); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Errorf("expected ErrInvalidDerivationPath for template without placeholders, got %v", err)
+	}
+}
+
+func TestBIP44Addresses(t *testing.T) {
+	master, err := NewMasterKeyFromMnemonic(strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, _ := MetaMaskPathTemplate.Path(0, 0)
+	extendedKey, err := master.DerivePath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := extendedKey.Key()
+	address, _ := key.EthereumAddress()
+	if address != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Errorf("address %s", address)
+	}
+	path, _ = TronLinkPathTemplate.Path(0, 0)
+	if extendedKey, err = master.DerivePath(path); err != nil {
+		t.Fatal(err)
+	}
+	key, _ = extendedKey.Key()
+	tronAddress, _ := key.TronAddress()
+	t.Logf("%s %s", path, tronAddress)
+}
+
+func BenchmarkBIP44AddressDerivation(b *testing.B) {
+	master, err := NewMasterKeyFromMnemonic(strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"), "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		path, _ := TronLinkPathTemplate.Path(0, uint32(i%1000))
+		extendedKey, err := master.DerivePath(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		key, _ := extendedKey.Key()
+		if _, err = key.TronAddress(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"fmt"
 	"github.com/mcmx73/easytron/common/hexutil"
 	"github.com/mcmx73/easytron/common/math"
 	"github.com/mcmx73/easytron/keys/keccak"
@@ -51,3 +52,34 @@ func BytesFromECDSAPublicKey(pub *ecdsa.PublicKey) []byte {
 	}
 	return elliptic.Marshal(secp256k1.P256k1(), pub.X, pub.Y)
 }
+
+// CompressedBytesFromECDSAPublicKey returns 33-byte compressed public key
+func CompressedBytesFromECDSAPublicKey(pub *ecdsa.PublicKey) []byte {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil
+	}
+	return secp256k1.MarshalCompressed(secp256k1.P256k1(), pub.X, pub.Y)
+}
+
+// ECDSAPublicKeyFromBytes parses 33-byte compressed or 65-byte uncompressed public key,
+// hybrid encodings and points not on the curve are rejected
+func ECDSAPublicKeyFromBytes(pub []byte) (*ecdsa.PublicKey, error) {
+	curve := secp256k1.P256k1()
+	var x, y *big.Int
+	switch {
+	case len(pub) == 33 && (pub[0] == 2 || pub[0] == 3):
+		x, y = secp256k1.UnmarshalCompressed(curve, pub)
+	case len(pub) == 65 && pub[0] == 4:
+		x, y = new(big.Int).SetBytes(pub[1:33]), new(big.Int).SetBytes(pub[33:])
+		p := curve.Params().P
+		if x.Cmp(p) >= 0 || y.Cmp(p) >= 0 || !curve.IsOnCurve(x, y) {
+			x = nil
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported encoding of %d bytes", ErrInvalidPublicKey, len(pub))
+	}
+	if x == nil {
+		return nil, fmt.Errorf("%w: point is not on the curve", ErrInvalidPublicKey)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
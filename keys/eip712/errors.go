@@ -0,0 +1,10 @@
+package eip712
+
+import "errors"
+
+var (
+	ErrUndefinedType = errors.New("undefined type")
+	ErrInvalidType   = errors.New("invalid type")
+	ErrMissingValue  = errors.New("missing value")
+	ErrInvalidValue  = errors.New("invalid value")
+)
@@ -0,0 +1,83 @@
+package eip712
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Preview returns human-readable text of the domain and message for confirmation before signing.
+// String values are quoted and other text is escaped, so line breaks can not imitate fields.
+func (td *TypedData) Preview() (string, error) {
+	if err := td.Validate(); err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	out.WriteString("Domain:\n")
+	if err := td.writeStruct(&out, DomainType, td.Domain, 1); err != nil {
+		return "", err
+	}
+	out.WriteString(escape(td.PrimaryType) + ":\n")
+	if err := td.writeStruct(&out, td.PrimaryType, td.Message, 1); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (td *TypedData) writeStruct(out *strings.Builder, typeName string, data map[string]interface{}, depth int) error {
+	for _, field := range td.Types[typeName] {
+		value, ok := data[field.Name]
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", ErrMissingValue, typeName, field.Name)
+		}
+		if err := td.writeValue(out, field.Name, field.Type, value, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (td *TypedData) writeValue(out *strings.Builder, name, typeName string, value interface{}, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	baseType, _, err := splitArrayType(typeName)
+	if err != nil {
+		return err
+	}
+	if baseType != typeName {
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s expects array", ErrInvalidValue, typeName)
+		}
+		fmt.Fprintf(out, "%s%s: %d items\n", indent, escape(name), len(items))
+		itemType := typeName[:strings.LastIndexByte(typeName, '[')]
+		for i, item := range items {
+			if err = td.writeValue(out, fmt.Sprintf("[%d]", i), itemType, item, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if _, ok := td.Types[typeName]; ok {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: %s expects object", ErrInvalidValue, typeName)
+		}
+		fmt.Fprintf(out, "%s%s (%s):\n", indent, escape(name), escape(typeName))
+		return td.writeStruct(out, typeName, fields, depth+1)
+	}
+	if text, ok := value.(string); ok && typeName == "string" {
+		fmt.Fprintf(out, "%s%s: %q\n", indent, escape(name), text)
+		return nil
+	}
+	fmt.Fprintf(out, "%s%s: %s\n", indent, escape(name), escape(fmt.Sprint(value)))
+	return nil
+}
+
+// escape replaces line breaks and other non-printable characters with Go escape sequences
+func escape(text string) string {
+	if strings.IndexFunc(text, func(r rune) bool { return !strconv.IsPrint(r) }) < 0 {
+		return text
+	}
+	quoted := strconv.Quote(text)
+	return quoted[1 : len(quoted)-1]
+}
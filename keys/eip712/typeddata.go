@@ -0,0 +1,200 @@
+// Package eip712 implements hashing of typed structured data as specified by EIP-712
+// See https://eips.ethereum.org/EIPS/eip-712
+package eip712
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"sort"
+	"strings"
+)
+
+const (
+	DomainType = "EIP712Domain"
+)
+
+type Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type Types map[string][]Type
+
+// TypedData is the eth_signTypedData_v4 request payload
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      map[string]interface{} `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// ParseTypedData decodes JSON typed data keeping numbers exact
+func ParseTypedData(data []byte) (*TypedData, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	td := &TypedData{}
+	if err := decoder.Decode(td); err != nil {
+		return nil, err
+	}
+	if err := td.Validate(); err != nil {
+		return nil, err
+	}
+	return td, nil
+}
+
+// Validate checks that primary and domain types are declared and every field type is known
+func (td *TypedData) Validate() error {
+	if _, ok := td.Types[DomainType]; !ok {
+		return fmt.Errorf("%w: %s", ErrUndefinedType, DomainType)
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return fmt.Errorf("%w: %s", ErrUndefinedType, td.PrimaryType)
+	}
+	for typeName, fields := range td.Types {
+		for _, field := range fields {
+			if field.Name == "" {
+				return fmt.Errorf("%w: empty field name in %s", ErrInvalidType, typeName)
+			}
+			baseType, _, err := splitArrayType(field.Type)
+			if err != nil {
+				return err
+			}
+			if _, ok := td.Types[baseType]; ok {
+				continue
+			}
+			if !isAtomicType(baseType) && !isDynamicType(baseType) {
+				return fmt.Errorf("%w: %s", ErrUndefinedType, baseType)
+			}
+		}
+	}
+	return nil
+}
+
+// SigningHash returns keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+func (td *TypedData) SigningHash() ([]byte, error) {
+	domainSeparator, err := td.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, err
+	}
+	return keccak.Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
+
+// DomainSeparator returns hashStruct of the EIP712Domain
+func (td *TypedData) DomainSeparator() ([]byte, error) {
+	return td.HashStruct(DomainType, td.Domain)
+}
+
+// HashStruct returns keccak256(typeHash ‖ encodeData(data))
+func (td *TypedData) HashStruct(typeName string, data map[string]interface{}) ([]byte, error) {
+	encoded, err := td.EncodeData(typeName, data)
+	if err != nil {
+		return nil, err
+	}
+	return keccak.Keccak256(td.TypeHash(typeName), encoded), nil
+}
+
+// TypeHash returns keccak256 of the encoded type
+func (td *TypedData) TypeHash(typeName string) []byte {
+	return keccak.Keccak256([]byte(td.EncodeType(typeName)))
+}
+
+// EncodeType returns type signature with referenced struct types appended in alphabetical order,
+// e.g. "Mail(Person from,Person to,string contents)Person(string name,address wallet)"
+func (td *TypedData) EncodeType(typeName string) string {
+	deps := td.dependencies(typeName, map[string]bool{})
+	sort.Strings(deps)
+	var out strings.Builder
+	for _, dep := range append([]string{typeName}, deps...) {
+		out.WriteString(dep)
+		out.WriteByte('(')
+		for i, field := range td.Types[dep] {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(field.Type)
+			out.WriteByte(' ')
+			out.WriteString(field.Name)
+		}
+		out.WriteByte(')')
+	}
+	return out.String()
+}
+
+// dependencies collects struct types referenced by typeName, excluding typeName itself
+func (td *TypedData) dependencies(typeName string, found map[string]bool) []string {
+	found[typeName] = true
+	var deps []string
+	for _, field := range td.Types[typeName] {
+		baseType, _, err := splitArrayType(field.Type)
+		if err != nil || found[baseType] {
+			continue
+		}
+		if _, ok := td.Types[baseType]; !ok {
+			continue
+		}
+		deps = append(deps, baseType)
+		deps = append(deps, td.dependencies(baseType, found)...)
+	}
+	return deps
+}
+
+// EncodeData returns concatenated 32-byte encoded values of every field of the struct
+func (td *TypedData) EncodeData(typeName string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := td.Types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUndefinedType, typeName)
+	}
+	out := make([]byte, 0, 32*len(fields))
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s.%s", ErrMissingValue, typeName, field.Name)
+		}
+		encoded, err := td.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typeName, field.Name, err)
+		}
+		out = append(out, encoded...)
+	}
+	return out, nil
+}
+
+func (td *TypedData) encodeValue(typeName string, value interface{}) ([]byte, error) {
+	baseType, arrayLen, err := splitArrayType(typeName)
+	if err != nil {
+		return nil, err
+	}
+	if baseType != typeName {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects array", ErrInvalidValue, typeName)
+		}
+		if arrayLen >= 0 && len(items) != arrayLen {
+			return nil, fmt.Errorf("%w: %s expects %d items, got %d", ErrInvalidValue, typeName, arrayLen, len(items))
+		}
+		itemType := typeName[:strings.LastIndexByte(typeName, '[')]
+		encoded := make([]byte, 0, 32*len(items))
+		for _, item := range items {
+			itemEncoded, err := td.encodeValue(itemType, item)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, itemEncoded...)
+		}
+		return keccak.Keccak256(encoded), nil
+	}
+	if _, ok := td.Types[typeName]; ok {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects object", ErrInvalidValue, typeName)
+		}
+		return td.HashStruct(typeName, fields)
+	}
+	return encodePrimitive(typeName, value)
+}
@@ -0,0 +1,146 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// Example from https://eips.ethereum.org/EIPS/eip-712
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestMailExample(t *testing.T) {
+	td, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded := td.EncodeType("Mail"); encoded != "Mail(Person from,Person to,string contents)Person(string name,address wallet)" {
+		t.Error("EncodeType mismatch:", encoded)
+	}
+	if typeHash := hex.EncodeToString(td.TypeHash("Mail")); typeHash != "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2" {
+		t.Error("TypeHash mismatch:", typeHash)
+	}
+	domainSeparator, err := td.DomainSeparator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(domainSeparator) != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Errorf("DomainSeparator mismatch: %x", domainSeparator)
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(messageHash) != "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e" {
+		t.Errorf("HashStruct mismatch: %x", messageHash)
+	}
+	hash, err := td.SigningHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(hash) != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Errorf("SigningHash mismatch: %x", hash)
+	}
+	preview, err := td.Preview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Preview:\n" + preview)
+}
+
+func TestArrays(t *testing.T) {
+	td := &TypedData{
+		Types: Types{
+			DomainType: {{Name: "name", Type: "string"}},
+			"Person":   {{Name: "name", Type: "string"}, {Name: "wallets", Type: "address[]"}},
+			"Group":    {{Name: "members", Type: "Person[]"}, {Name: "ids", Type: "uint8[2]"}},
+		},
+		PrimaryType: "Group",
+		Domain:      map[string]interface{}{"name": "Groups"},
+		Message: map[string]interface{}{
+			"members": []interface{}{
+				map[string]interface{}{"name": "Alice", "wallets": []interface{}{"0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"}},
+				map[string]interface{}{"name": "Bob", "wallets": []interface{}{}},
+			},
+			"ids": []interface{}{"1", "0x02"},
+		},
+	}
+	if err := td.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if encoded := td.EncodeType("Group"); encoded != "Group(Person[] members,uint8[2] ids)Person(string name,address[] wallets)" {
+		t.Error("EncodeType mismatch:", encoded)
+	}
+	if _, err := td.SigningHash(); err != nil {
+		t.Error(err)
+	}
+	td.Message["ids"] = []interface{}{"1"}
+	if _, err := td.SigningHash(); !errors.Is(err, ErrInvalidValue) {
+		t.Error("fixed size array length is not checked:", err)
+	}
+	td.Message["ids"] = []interface{}{"1", "256"}
+	if _, err := td.SigningHash(); !errors.Is(err, ErrInvalidValue) {
+		t.Error("uint8 overflow is not checked:", err)
+	}
+	td.Types["Group"][1].Type = "Unknown[2]"
+	if err := td.Validate(); !errors.Is(err, ErrUndefinedType) {
+		t.Error("undefined type is not detected:", err)
+	}
+}
+
+func TestPreviewEscapes(t *testing.T) {
+	td := &TypedData{
+		Types: Types{
+			DomainType: {{Name: "name", Type: "string"}},
+			"Transfer": {{Name: "note", Type: "string"}, {Name: "to\n  amount", Type: "address"}},
+		},
+		PrimaryType: "Transfer",
+		Domain:      map[string]interface{}{"name": "Pay"},
+		Message: map[string]interface{}{
+			"note":         "hi\n  to: 0x0000000000000000000000000000000000000001",
+			"to\n  amount": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+		},
+	}
+	preview, err := td.Preview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Domain:\n" +
+		"  name: \"Pay\"\n" +
+		"Transfer:\n" +
+		"  note: \"hi\\n  to: 0x0000000000000000000000000000000000000001\"\n" +
+		"  to\\n  amount: 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826\n"
+	if preview != want {
+		t.Errorf("Preview mismatch:\n%s", preview)
+	}
+}
@@ -0,0 +1,181 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/mcmx73/easytron/common/math"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// splitArrayType returns the innermost element type and the length of the outermost
+// array dimension, -1 for dynamic arrays. For non-array types baseType equals typeName.
+func splitArrayType(typeName string) (baseType string, arrayLen int, err error) {
+	open := strings.IndexByte(typeName, '[')
+	if open < 0 {
+		return typeName, 0, nil
+	}
+	if !strings.HasSuffix(typeName, "]") || open == 0 {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidType, typeName)
+	}
+	outer := typeName[strings.LastIndexByte(typeName, '[')+1 : len(typeName)-1]
+	arrayLen = -1
+	if outer != "" {
+		arrayLen, err = strconv.Atoi(outer)
+		if err != nil || arrayLen <= 0 {
+			return "", 0, fmt.Errorf("%w: %s", ErrInvalidType, typeName)
+		}
+	}
+	return typeName[:open], arrayLen, nil
+}
+
+func isDynamicType(typeName string) bool {
+	return typeName == "string" || typeName == "bytes"
+}
+
+func isAtomicType(typeName string) bool {
+	switch {
+	case typeName == "address" || typeName == "bool":
+		return true
+	case strings.HasPrefix(typeName, "bytes"):
+		size, err := strconv.Atoi(typeName[5:])
+		return err == nil && size >= 1 && size <= 32
+	case strings.HasPrefix(typeName, "uint"):
+		return validIntSize(typeName[4:])
+	case strings.HasPrefix(typeName, "int"):
+		return validIntSize(typeName[3:])
+	}
+	return false
+}
+
+func validIntSize(size string) bool {
+	bits, err := strconv.Atoi(size)
+	return err == nil && bits >= 8 && bits <= 256 && bits%8 == 0
+}
+
+// encodePrimitive encodes atomic value as 32-byte word and dynamic value as its keccak256 hash
+func encodePrimitive(typeName string, value interface{}) ([]byte, error) {
+	switch {
+	case typeName == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: string expected", ErrInvalidValue)
+		}
+		return keccak.Keccak256([]byte(s)), nil
+	case typeName == "bytes":
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return keccak.Keccak256(b), nil
+	case typeName == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: bool expected", ErrInvalidValue)
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case typeName == "address":
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != 20 {
+			return nil, fmt.Errorf("%w: 20-byte address expected", ErrInvalidValue)
+		}
+		return math.PaddedBigBytes(new(big.Int).SetBytes(b), 32), nil
+	case strings.HasPrefix(typeName, "bytes"):
+		size, _ := strconv.Atoi(typeName[5:])
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != size {
+			return nil, fmt.Errorf("%w: %d bytes expected, got %d", ErrInvalidValue, size, len(b))
+		}
+		word := make([]byte, 32)
+		copy(word, b)
+		return word, nil
+	case strings.HasPrefix(typeName, "uint"), strings.HasPrefix(typeName, "int"):
+		n, err := parseInteger(value)
+		if err != nil {
+			return nil, err
+		}
+		signed := typeName[0] == 'i'
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typeName, "u")[3:])
+		if !integerFits(n, bits, signed) {
+			return nil, fmt.Errorf("%w: %s overflows %s", ErrInvalidValue, n.String(), typeName)
+		}
+		return math.U256Bytes(new(big.Int).Set(n)), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUndefinedType, typeName)
+}
+
+func integerFits(n *big.Int, bits int, signed bool) bool {
+	if !signed {
+		return n.Sign() >= 0 && n.BitLen() <= bits
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	if n.Sign() < 0 {
+		return n.Cmp(new(big.Int).Neg(limit)) >= 0
+	}
+	return n.Cmp(limit) < 0
+}
+
+// parseBytes accepts 0x prefixed hex string or a raw byte slice
+func parseBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") && !strings.HasPrefix(v, "0X") {
+			return nil, fmt.Errorf("%w: 0x prefixed hex expected", ErrInvalidValue)
+		}
+		b, err := hex.DecodeString(v[2:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%w: hex string expected", ErrInvalidValue)
+}
+
+// parseInteger accepts JSON numbers, decimal or 0x prefixed hex strings and Go integers
+func parseInteger(value interface{}) (*big.Int, error) {
+	var s string
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("%w: %v is not an integer", ErrInvalidValue, v)
+		}
+		return big.NewInt(int64(v)), nil
+	case json.Number:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return nil, fmt.Errorf("%w: integer expected", ErrInvalidValue)
+	}
+	n, ok := math.ParseBig256(strings.TrimPrefix(s, "-"))
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid integer %q", ErrInvalidValue, s)
+	}
+	if strings.HasPrefix(s, "-") {
+		n.Neg(n)
+	}
+	return n, nil
+}
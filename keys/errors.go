@@ -0,0 +1,28 @@
+package keys
+
+import "errors"
+
+var (
+	ErrKeyIsEmpty       = errors.New("key is empty")
+	ErrKeyInvalid       = errors.New("key is invalid")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidAddress   = errors.New("invalid address")
+	ErrAddressChecksum  = errors.New("address checksum mismatch")
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	ErrUnknownKeyFormat = errors.New("unknown key format")
+	ErrAddressMismatch  = errors.New("key does not match expected address")
+
+	ErrInvalidSeedLength     = errors.New("seed length must be between 128 and 512 bits")
+	ErrInvalidExtendedKey    = errors.New("invalid extended key")
+	ErrHardenedFromPublicKey = errors.New("cannot derive hardened key from public key")
+	ErrInvalidChildKey       = errors.New("derived key is invalid, use next index")
+	ErrMaxDepthExceeded      = errors.New("max depth of extended key exceeded")
+	ErrInvalidDerivationPath = errors.New("invalid derivation path")
+
+	ErrInvalidKeyStore     = errors.New("invalid keystore file")
+	ErrUnsupportedKeyStore = errors.New("unsupported keystore file")
+	ErrDecrypt             = errors.New("could not decrypt key with given password")
+	ErrKeyNotFound         = errors.New("key not found")
+	ErrKeyExists           = errors.New("key already exists")
+	ErrNoKeyStore          = errors.New("keystore is not configured")
+)
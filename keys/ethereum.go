@@ -0,0 +1,63 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"strings"
+)
+
+// EthereumAddressFromPublicKey returns EIP-55 checksummed Ethereum address for the public key
+func EthereumAddressFromPublicKey(pub *ecdsa.PublicKey) string {
+	return EthereumChecksumAddress(pubKeyToKeccak256HashBytes(*pub))
+}
+
+// EthereumChecksumAddress encodes 20 address bytes as hex with EIP-55 mixed-case checksum
+// See https://eips.ethereum.org/EIPS/eip-55
+func EthereumChecksumAddress(address []byte) string {
+	lower := hex.EncodeToString(address)
+	hash := keccak.Keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		if c < 'a' {
+			continue
+		}
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0x0f >= 8 {
+			out[i] = c - 32
+		}
+	}
+	return "0x" + string(out)
+}
+
+// ValidateEthereumAddress checks 0x hex address and its EIP-55 checksum. All lower or all upper
+// case addresses carry no checksum and are accepted.
+func ValidateEthereumAddress(address string) error {
+	addressBytes, err := ethereumAddressBytes(address)
+	if err != nil {
+		return err
+	}
+	digits := address[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	if EthereumChecksumAddress(addressBytes)[2:] != digits {
+		return ErrAddressChecksum
+	}
+	return nil
+}
+
+// ethereumAddressBytes decodes 0x prefixed hex address, mixed case checksum is not checked
+func ethereumAddressBytes(address string) ([]byte, error) {
+	if !strings.HasPrefix(address, "0x") && !strings.HasPrefix(address, "0X") {
+		return nil, ErrInvalidAddress
+	}
+	addressBytes, err := hex.DecodeString(address[2:])
+	if err != nil || len(addressBytes) != 20 {
+		return nil, ErrInvalidAddress
+	}
+	return addressBytes, nil
+}
@@ -0,0 +1,147 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"github.com/mcmx73/easytron/keys/eip712"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"github.com/mcmx73/easytron/keys/secp256k1"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ethereumSignedMessagePrefix = "\x19Ethereum Signed Message:\n"
+	recoveryIdOffset            = 27
+)
+
+// EthereumMessageHash returns EIP-191 version 0x45 hash of the message, as used by personal_sign
+func EthereumMessageHash(message []byte) []byte {
+	prefix := ethereumSignedMessagePrefix + strconv.Itoa(len(message))
+	return keccak.Keccak256([]byte(prefix), message)
+}
+
+// SignEthereumMessage signs the message in personal_sign format
+// The produced signature is in the 65-byte [R || S || V] format with V = 27 or 28
+func (k *Key) SignEthereumMessage(message []byte) ([]byte, error) {
+	return k.signHash(EthereumMessageHash(message))
+}
+
+// SignTypedData signs EIP-712 typed structured data as eth_signTypedData_v4 does
+func (k *Key) SignTypedData(typedData *eip712.TypedData) ([]byte, error) {
+	hash, err := typedData.SigningHash()
+	if err != nil {
+		return nil, err
+	}
+	return k.signHash(hash)
+}
+
+// signHash returns 65-byte [R || S || V] signature with V = 27 or 28, the format shared by
+// personal_sign, eth_signTypedData and TronWeb signMessageV2
+func (k *Key) signHash(hash []byte) ([]byte, error) {
+	var signature []byte
+	err := k.usePrivateKey(func(privateKey *ecdsa.PrivateKey) (err error) {
+		signature, err = secp256k1.SignEthereum(hash, privateKey)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	signature[64] += recoveryIdOffset
+	return signature, nil
+}
+
+// RecoverEthereumMessageAddress returns checksummed address of the personal_sign message signer
+func RecoverEthereumMessageAddress(message, signature []byte) (string, error) {
+	return recoverEthereumAddress(EthereumMessageHash(message), signature)
+}
+
+// RecoverTypedDataAddress returns checksummed address of the EIP-712 typed data signer
+func RecoverTypedDataAddress(typedData *eip712.TypedData, signature []byte) (string, error) {
+	hash, err := typedData.SigningHash()
+	if err != nil {
+		return "", err
+	}
+	return recoverEthereumAddress(hash, signature)
+}
+
+// VerifyEthereumMessage reports whether personal_sign signature of the message was made by address
+func VerifyEthereumMessage(message, signature []byte, address string) (bool, error) {
+	return verifyEthereumSigner(EthereumMessageHash(message), signature, address)
+}
+
+// VerifyTypedData reports whether EIP-712 signature of the typed data was made by address
+func VerifyTypedData(typedData *eip712.TypedData, signature []byte, address string) (bool, error) {
+	hash, err := typedData.SigningHash()
+	if err != nil {
+		return false, err
+	}
+	return verifyEthereumSigner(hash, signature, address)
+}
+
+func verifyEthereumSigner(hash, signature []byte, address string) (bool, error) {
+	expected, err := ethereumAddressBytes(address)
+	if err != nil {
+		return false, err
+	}
+	signer, err := recoverEthereumAddress(hash, signature)
+	if err != nil {
+		return false, err
+	}
+	return signer == EthereumChecksumAddress(expected), nil
+}
+
+// RecoverPublicKey returns public key of the hash signer, use PublicKeyBytes of the result
+// for compressed or uncompressed encoding. V is accepted as recovery id (0, 1) or with
+// Ethereum offset (27, 28)
+func RecoverPublicKey(hash, signature []byte) (*Key, error) {
+	if len(signature) != 65 {
+		return nil, ErrInvalidSignature
+	}
+	pub, err := secp256k1.RecoverEthereum(hash, normalizeRecoveryId(signature))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	key, err := NewPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return key, nil
+}
+
+func recoverEthereumAddress(hash, signature []byte) (string, error) {
+	key, err := RecoverPublicKey(hash, signature)
+	if err != nil {
+		return "", err
+	}
+	return key.EthereumAddress()
+}
+
+// normalizeRecoveryId returns copy of the signature with V = 27, 28 converted to recovery id 0, 1
+func normalizeRecoveryId(signature []byte) []byte {
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if sig[len(sig)-1] >= recoveryIdOffset {
+		sig[len(sig)-1] -= recoveryIdOffset
+	}
+	return sig
+}
+
+// MessagePreview returns the message as text when it is printable UTF-8, otherwise as 0x prefixed hex
+func MessagePreview(message []byte) string {
+	if utf8.Valid(message) {
+		printable := true
+		for _, r := range string(message) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(message)
+		}
+	}
+	return "0x" + hex.EncodeToString(message)
+}
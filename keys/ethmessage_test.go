@@ -0,0 +1,83 @@
+package keys
+
+import (
+	"encoding/hex"
+	"github.com/mcmx73/easytron/keys/eip712"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"testing"
+)
+
+func TestEthereumMessage(t *testing.T) {
+	hash := EthereumMessageHash([]byte("hello world"))
+	if hex.EncodeToString(hash) != "d9eba16ed0ecae432b71fe008c98cc872bb4cc214d3220a36f365326cf807d68" {
+		t.Errorf("EthereumMessageHash mismatch: %x", hash)
+	}
+	privateKey, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey(WithECDSAPrivateKey(privateKey))
+	address, err := key.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("Sign in to easytron")
+	signature, err := key.SignEthereumMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signature) != 65 || (signature[64] != 27 && signature[64] != 28) {
+		t.Errorf("Unexpected signature format: %x", signature)
+	}
+	valid, err := VerifyEthereumMessage(message, signature, address)
+	if err != nil || !valid {
+		t.Error("VerifyEthereumMessage failed", err)
+	}
+	valid, err = VerifyEthereumMessage([]byte("Sign in to easytron!"), signature, address)
+	if err != nil || valid {
+		t.Error("VerifyEthereumMessage passed with other message", err)
+	}
+	if MessagePreview(message) != "Sign in to easytron" {
+		t.Error("Text message preview mismatch")
+	}
+	if MessagePreview([]byte{0, 1, 0xff}) != "0x0001ff" {
+		t.Error("Binary message preview mismatch")
+	}
+}
+
+func TestTypedDataSignature(t *testing.T) {
+	typedData := &eip712.TypedData{
+		Types: eip712.Types{
+			eip712.DomainType: {{Name: "name", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Permit":          {{Name: "spender", Type: "address"}, {Name: "value", Type: "uint256"}},
+		},
+		PrimaryType: "Permit",
+		Domain:      map[string]interface{}{"name": "Token", "chainId": 1},
+		Message: map[string]interface{}{
+			"spender": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			"value":   "1000000000000000000",
+		},
+	}
+	// keccak256("cow") is the signer key of the EIP-712 specification example
+	cowKey := NewKey(WithPrivateKeyHex(hex.EncodeToString(keccak.Keccak256([]byte("cow")))))
+	cowAddress, err := cowKey.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cowAddress != "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826" {
+		t.Error("Ethereum address mismatch:", cowAddress)
+	}
+	signature, err := cowKey.SignTypedData(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := RecoverTypedDataAddress(typedData, signature)
+	if err != nil || signer != cowAddress {
+		t.Error("RecoverTypedDataAddress mismatch:", signer, err)
+	}
+	typedData.Message["value"] = "1"
+	valid, err := VerifyTypedData(typedData, signature, cowAddress)
+	if err != nil || valid {
+		t.Error("VerifyTypedData passed for modified message", err)
+	}
+}
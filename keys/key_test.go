@@ -1,8 +1,13 @@
 package keys
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/mcmx73/easytron/common/hexutil"
 	"github.com/mcmx73/easytron/common/seedphrase"
+	"strings"
 	"testing"
 )
 
@@ -44,3 +49,138 @@ func TestECDSAKeys(t *testing.T) {
 	}
 	t.Log("Restored key:", hexutil.Encode(recoveredFromBackup))
 }
+
+func TestLegacyBackupPhrase(t *testing.T) {
+	privateKey, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey(WithECDSAPrivateKey(privateKey))
+	restored, err := RestoreLegacyKey(key.GetLegacyBackupPhrase())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privateKeyHex(restored) != privateKeyHex(key) {
+		t.Error("Legacy backup phrase mismatch")
+	}
+}
+
+func TestKeyDestroy(t *testing.T) {
+	privateKey, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey(WithECDSAPrivateKey(privateKey))
+	keyHex := privateKeyHex(key)
+	for _, out := range []string{fmt.Sprintf("%v", key), fmt.Sprintf("%+v", *key)} {
+		if strings.Contains(out, keyHex) {
+			t.Errorf("private key is printed: %s", out)
+		}
+	}
+	if out, _ := json.Marshal(key); strings.Contains(string(out), keyHex) {
+		t.Errorf("private key is marshaled: %s", out)
+	}
+	address, _ := key.TronAddress()
+	key.Destroy()
+	if key.HasPrivateKey() || privateKey.D.Sign() != 0 {
+		t.Error("private key is not wiped")
+	}
+	if _, err = key.ExportPrivateKey(); !errors.Is(err, ErrKeyIsEmpty) {
+		t.Errorf("expected ErrKeyIsEmpty, got %v", err)
+	}
+	if restored, _ := key.TronAddress(); restored != address {
+		t.Error("public key is lost")
+	}
+	if _, err = NewKey(WithPrivateKeyHex("0x00")).ExportPrivateKey(); !errors.Is(err, ErrKeyInvalid) {
+		t.Errorf("expected ErrKeyInvalid, got %v", err)
+	}
+}
+
+func TestPublicKeyEncoding(t *testing.T) {
+	privateKey, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey(WithECDSAPrivateKey(privateKey))
+	compressed, err := key.PublicKeyBytes(true)
+	if err != nil || len(compressed) != 33 {
+		t.Fatalf("compressed key %x, %v", compressed, err)
+	}
+	uncompressed, _ := key.PublicKeyBytes(false)
+	address, _ := key.TronAddress()
+	for _, encoded := range [][]byte{compressed, uncompressed} {
+		imported, err := NewPublicKey(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		importedAddress, _ := imported.TronAddress()
+		if importedAddress != address {
+			t.Errorf("address of imported %d-byte key %s, expected %s", len(encoded), importedAddress, address)
+		}
+		fromHex := NewKey(WithPublicKeyHex(hexutil.Encode(encoded)))
+		if hexAddress, err := fromHex.TronAddress(); err != nil || hexAddress != address {
+			t.Errorf("address of %d-byte hex key %s, %v", len(encoded), hexAddress, err)
+		}
+	}
+
+	hash := TronMessageHash([]byte("compressed"))
+	signature, _ := key.signHash(hash)
+	recovered, err := RecoverPublicKey(hash, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recoveredCompressed, _ := recovered.PublicKeyBytes(true); !bytes.Equal(recoveredCompressed, compressed) {
+		t.Errorf("recovered key %x, expected %x", recoveredCompressed, compressed)
+	}
+
+	offCurve := make([]byte, 65)
+	offCurve[0], offCurve[32], offCurve[64] = 4, 1, 1
+	xOverflow := append([]byte{2}, bytes.Repeat([]byte{0xff}, 32)...)
+	hybrid := append([]byte{6}, uncompressed[1:]...)
+	invalid := [][]byte{nil, compressed[:32], uncompressed[1:], hybrid, offCurve, xOverflow}
+	for _, encoded := range invalid {
+		if _, err = NewPublicKey(encoded); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("%x: expected ErrInvalidPublicKey, got %v", encoded, err)
+		}
+	}
+
+	// xpub with the point which is not on the curve
+	master, _ := NewMasterKey(bytes.Repeat([]byte{1}, 32))
+	xpub := master.Neuter()
+	if _, err = ParseExtendedKey(xpub.String()); err != nil {
+		t.Fatal(err)
+	}
+	broken := *xpub
+	broken.key = xOverflow
+	if _, err = ParseExtendedKey(broken.String()); !errors.Is(err, ErrInvalidExtendedKey) {
+		t.Errorf("expected ErrInvalidExtendedKey, got %v", err)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		err     error
+	}{
+		{"0x52908400098527886E0F7030069857D2E4169EE7", nil},
+		{"0x52908400098527886e0f7030069857d2e4169ee7", nil},
+		{"0x8617E340B3D01FA5F11F306F4090FD50E238070D", nil},
+		{"0xde709f2102306220921060314715629080e2fb77", nil},
+		{"0x52908400098527886E0F7030069857D2E4169eE7", ErrAddressChecksum},
+		{"52908400098527886E0F7030069857D2E4169EE7", ErrInvalidAddress},
+		{"0x52908400098527886E0F7030069857D2E4169E", ErrInvalidAddress},
+	}
+	for _, test := range tests {
+		if err := ValidateEthereumAddress(test.address); !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v, got %v", test.address, test.err, err)
+		}
+	}
+	if err := ValidateTronAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"); err != nil {
+		t.Error(err)
+	}
+	for _, address := range []string{"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6T", "0x52908400098527886E0F7030069857D2E4169EE7", ""} {
+		if err := ValidateTronAddress(address); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("%s: expected ErrInvalidAddress, got %v", address, err)
+		}
+	}
+}
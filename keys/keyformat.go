@@ -0,0 +1,246 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/mcmx73/easytron/common/base58"
+	"github.com/mcmx73/easytron/common/secret"
+	"github.com/mcmx73/easytron/common/seedphrase"
+	"strings"
+)
+
+// KeyFormat is text encoding of private key used by import and export
+type KeyFormat string
+
+const (
+	// KeyFormatAuto detects the format of imported data, see DetectKeyFormat
+	KeyFormatAuto KeyFormat = ""
+	// KeyFormatHex is 32-byte private key as hex with optional 0x prefix, as TronLink and MetaMask export it
+	KeyFormatHex KeyFormat = "hex"
+	// KeyFormatWIF is base58check of version 0x80 and private key, the Bitcoin WIF layout with
+	// optional compression flag byte
+	KeyFormatWIF KeyFormat = "wif"
+	// KeyFormatKeyStore is Web3 Secret Storage v3 JSON encrypted with password
+	KeyFormatKeyStore KeyFormat = "keystore"
+	// KeyFormatMnemonic is BIP39 mnemonic, the key is derived with the import derivation path
+	KeyFormatMnemonic KeyFormat = "mnemonic"
+	// KeyFormatLegacyPhrase is legacy easytron backup phrase, see GetLegacyBackupPhrase
+	KeyFormatLegacyPhrase KeyFormat = "legacy"
+
+	wifVersion        = 0x80
+	wifCompressedFlag = 0x01
+
+	// DefaultImportPath is derivation path of mnemonic import, the first TronLink address
+	DefaultImportPath = "m/44'/195'/0'/0/0"
+)
+
+type WithImportOption func(*keyImport)
+
+type keyImport struct {
+	format          KeyFormat
+	password        string
+	passphrase      string
+	path            string
+	expectedAddress string
+}
+
+// WithImportFormat disables format detection
+func WithImportFormat(format KeyFormat) WithImportOption {
+	return func(i *keyImport) {
+		i.format = format
+	}
+}
+
+// WithImportPassword sets password of keystore JSON
+func WithImportPassword(password string) WithImportOption {
+	return func(i *keyImport) {
+		i.password = password
+	}
+}
+
+// WithImportPassphrase sets BIP39 passphrase of mnemonic
+func WithImportPassphrase(passphrase string) WithImportOption {
+	return func(i *keyImport) {
+		i.passphrase = passphrase
+	}
+}
+
+// WithImportPath sets derivation path of mnemonic, DefaultImportPath by default
+func WithImportPath(path string) WithImportOption {
+	return func(i *keyImport) {
+		i.path = path
+	}
+}
+
+// WithExpectedAddress makes import fail unless the key has the Tron or Ethereum address
+func WithExpectedAddress(address string) WithImportOption {
+	return func(i *keyImport) {
+		i.expectedAddress = address
+	}
+}
+
+// DetectKeyFormat guesses the format of private key text. Words are detected as
+// BIP39 mnemonic, legacy phrases must be imported with explicit format.
+func DetectKeyFormat(data string) (KeyFormat, error) {
+	data = strings.TrimSpace(data)
+	switch {
+	case strings.HasPrefix(data, "{"):
+		return KeyFormatKeyStore, nil
+	case len(strings.Fields(data)) > 1:
+		return KeyFormatMnemonic, nil
+	case len(strings.TrimPrefix(strings.TrimPrefix(data, "0x"), "0X")) == 64:
+		if _, err := hex.DecodeString(data[len(data)-64:]); err == nil {
+			return KeyFormatHex, nil
+		}
+	}
+	if _, version, err := base58.CheckDecode(data); err == nil && version == wifVersion {
+		return KeyFormatWIF, nil
+	}
+	return KeyFormatAuto, ErrUnknownKeyFormat
+}
+
+// ImportKey decodes private key from hex, WIF, keystore JSON, mnemonic or legacy phrase.
+// The scalar must be in [1, N-1].
+func ImportKey(data string, options ...WithImportOption) (*Key, error) {
+	i := &keyImport{path: DefaultImportPath}
+	for _, opt := range options {
+		opt(i)
+	}
+	data = strings.TrimSpace(data)
+	format := i.format
+	if format == KeyFormatAuto {
+		var err error
+		if format, err = DetectKeyFormat(data); err != nil {
+			return nil, err
+		}
+	}
+	var key *Key
+	var err error
+	switch format {
+	case KeyFormatHex:
+		key, err = importHexKey(data)
+	case KeyFormatWIF:
+		key, err = importWIFKey(data)
+	case KeyFormatKeyStore:
+		key, err = DecryptKey([]byte(data), i.password)
+	case KeyFormatMnemonic:
+		key, err = importMnemonicKey(seedphrase.NormalizeMnemonic(data), i.passphrase, i.path)
+	case KeyFormatLegacyPhrase:
+		key, err = RestoreLegacyKey(seedphrase.NormalizeMnemonic(data))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = checkKeyAddress(key, i.expectedAddress); err != nil {
+		key.Destroy()
+		return nil, err
+	}
+	return key, nil
+}
+
+// ExportKey encodes private key in the format, password is used by keystore format only.
+// Mnemonic can not be recovered from a key, KeyFormatLegacyPhrase exports the legacy phrase.
+func ExportKey(key *Key, format KeyFormat, password string) (*secret.Buffer, error) {
+	privateKeyBytes, err := key.ExportPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(privateKeyBytes)
+	switch format {
+	case KeyFormatHex:
+		encoded := make([]byte, hex.EncodedLen(len(privateKeyBytes)))
+		hex.Encode(encoded, privateKeyBytes)
+		return secret.FromBytes(encoded), nil
+	case KeyFormatWIF:
+		return secret.FromString(base58.CheckEncode(privateKeyBytes, wifVersion)), nil
+	case KeyFormatKeyStore:
+		data, err := EncryptKey(key, password, StandardScryptN, StandardScryptP)
+		if err != nil {
+			return nil, err
+		}
+		return secret.FromBytes(data), nil
+	case KeyFormatLegacyPhrase:
+		phrase, err := seedphrase.LegacyKeyToMnemonic(privateKeyBytes)
+		if err != nil {
+			return nil, err
+		}
+		defer secret.WipeStrings(phrase)
+		return secret.FromString(strings.Join(phrase, " ")), nil
+	}
+	return nil, fmt.Errorf("%w: %s can not be exported", ErrUnknownKeyFormat, format)
+}
+
+func importHexKey(data string) (*Key, error) {
+	privateKeyBytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(data, "0x"), "0X"))
+	if err != nil || len(privateKeyBytes) != 32 {
+		secret.Wipe(privateKeyBytes)
+		return nil, ErrKeyInvalid
+	}
+	return newImportedKey(privateKeyBytes)
+}
+
+func importWIFKey(data string) (*Key, error) {
+	decoded, version, err := base58.CheckDecode(data)
+	if err != nil || version != wifVersion {
+		secret.Wipe(decoded)
+		return nil, ErrKeyInvalid
+	}
+	switch {
+	case len(decoded) == 33 && decoded[32] == wifCompressedFlag:
+		decoded = decoded[:32]
+	case len(decoded) != 32:
+		secret.Wipe(decoded)
+		return nil, ErrKeyInvalid
+	}
+	return newImportedKey(decoded)
+}
+
+func importMnemonicKey(mnemonic []string, passphrase, path string) (*Key, error) {
+	derivationPath, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	master, err := NewMasterKeyFromMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer master.Destroy()
+	child, err := master.DerivePath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	defer child.Destroy()
+	return child.Key()
+}
+
+// newImportedKey moves private key bytes into new key and checks the scalar is in [1, N-1]
+func newImportedKey(privateKeyBytes []byte) (*Key, error) {
+	key := NewKey(WithPrivateKeyBytes(privateKeyBytes))
+	if !key.validPrivateKey() {
+		key.Destroy()
+		return nil, ErrKeyInvalid
+	}
+	return key, nil
+}
+
+// checkKeyAddress returns ErrAddressMismatch unless the key has the 0x Ethereum or base58 Tron address
+func checkKeyAddress(key *Key, address string) error {
+	if address == "" {
+		return nil
+	}
+	expected, err := addressBytes(address)
+	if err != nil {
+		return err
+	}
+	pub, err := key.ecdsaPublicKey()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pubKeyToKeccak256HashBytes(*pub), expected) {
+		return fmt.Errorf("%w: %s", ErrAddressMismatch, address)
+	}
+	return nil
+}
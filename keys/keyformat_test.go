@@ -0,0 +1,103 @@
+package keys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectKeyFormat(t *testing.T) {
+	vectors := []struct {
+		data   string
+		format KeyFormat
+	}{
+		{"0x0000000000000000000000000000000000000000000000000000000000000001", KeyFormatHex},
+		{"7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d", KeyFormatHex},
+		{"5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf", KeyFormatWIF},
+		{"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", KeyFormatWIF},
+		{keyStoreTestVectors[0].json, KeyFormatKeyStore},
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", KeyFormatMnemonic},
+	}
+	for _, vector := range vectors {
+		format, err := DetectKeyFormat(vector.data)
+		if err != nil || format != vector.format {
+			t.Errorf("%.20s: detected %q, %v", vector.data, format, err)
+		}
+	}
+	for _, data := range []string{"", "0x1234", "TJRabPrwbZy45sbavfcjinPJC18kjpRTv8"} {
+		if _, err := DetectKeyFormat(data); !errors.Is(err, ErrUnknownKeyFormat) {
+			t.Errorf("%s: expected ErrUnknownKeyFormat, got %v", data, err)
+		}
+	}
+}
+
+func TestImportKey(t *testing.T) {
+	const keyOne = "0000000000000000000000000000000000000000000000000000000000000001"
+	for _, data := range []string{
+		"0x" + keyOne,
+		"5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf",
+		"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn",
+	} {
+		key, err := ImportKey(data, WithExpectedAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"))
+		if err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		if privateKeyHex(key) != keyOne {
+			t.Errorf("%s: imported %s", data, privateKeyHex(key))
+		}
+	}
+
+	key, err := ImportKey(keyStoreTestVectors[1].json, WithImportPassword("testpassword"))
+	if err != nil || privateKeyHex(key) != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Errorf("keystore import: %v", err)
+	}
+
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	if _, err = ImportKey(mnemonic, WithImportPath("m/44'/60'/0'/0/0"), WithExpectedAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")); err != nil {
+		t.Errorf("mnemonic import: %v", err)
+	}
+	if _, err = ImportKey(mnemonic, WithExpectedAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")); !errors.Is(err, ErrAddressMismatch) {
+		t.Errorf("expected ErrAddressMismatch, got %v", err)
+	}
+
+	for _, data := range []string{
+		"0x0000000000000000000000000000000000000000000000000000000000000000",
+		"0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+	} {
+		if _, err = ImportKey(data); !errors.Is(err, ErrKeyInvalid) {
+			t.Errorf("%s: expected ErrKeyInvalid, got %v", data, err)
+		}
+	}
+}
+
+func TestExportKey(t *testing.T) {
+	privateKey, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := NewKey(WithECDSAPrivateKey(privateKey))
+	address, _ := key.TronAddress()
+	for _, format := range []KeyFormat{KeyFormatHex, KeyFormatWIF, KeyFormatKeyStore, KeyFormatLegacyPhrase} {
+		exported, err := ExportKey(key, format, "password")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		data := string(exported.Export())
+		exported.Destroy()
+		if format == KeyFormatLegacyPhrase && len(strings.Fields(data)) != 24 {
+			t.Errorf("legacy phrase %d words", len(strings.Fields(data)))
+		}
+		imported, err := ImportKey(data, WithImportFormat(format), WithImportPassword("password"), WithExpectedAddress(address))
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if privateKeyHex(imported) != privateKeyHex(key) {
+			t.Errorf("%s: round trip mismatch", format)
+		}
+	}
+	if _, err = ExportKey(key, KeyFormatMnemonic, ""); !errors.Is(err, ErrUnknownKeyFormat) {
+		t.Errorf("expected ErrUnknownKeyFormat, got %v", err)
+	}
+}
@@ -1,6 +1,13 @@
 package keys
 
-import "crypto/ecdsa"
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"github.com/mcmx73/easytron/common/hexutil"
+	"github.com/mcmx73/easytron/common/secret"
+	"math/big"
+)
 
 // "crypto/ecdsa"
 type WithKeyOption func(*Key)
@@ -13,8 +20,177 @@ func NewKey(options ...WithKeyOption) *Key {
 	return k
 }
 
+// WithPrivateKeyHex sets private key from hex, an invalid key is reported on first use.
+// The string itself can not be wiped, prefer WithPrivateKeyBytes.
+func WithPrivateKeyHex(privateKey string) WithKeyOption {
+	return WithPrivateKeyBytes(hexutil.FromHex(privateKey))
+}
+
+// WithPrivateKeyBytes moves 32-byte private key into secret buffer, privateKey is zeroed.
+// An invalid key is reported on first use.
+func WithPrivateKeyBytes(privateKey []byte) WithKeyOption {
+	return func(k *Key) {
+		k.Destroy()
+		k.secretKey = secret.FromBytes(privateKey)
+		// only valid keys get the public key
+		k.PublicKey = ""
+		_ = k.usePrivateKey(func(privateKey *ecdsa.PrivateKey) error {
+			k.PublicKey = fmt.Sprintf("%x", BytesFromECDSAPublicKey(&privateKey.PublicKey))
+			return nil
+		})
+	}
+}
+
+// WithECDSAPrivateKey moves private key into secret buffer, the scalar of privateKey is zeroed
+func WithECDSAPrivateKey(privateKey *ecdsa.PrivateKey) WithKeyOption {
+	return func(k *Key) {
+		k.Destroy()
+		k.secretKey = secret.FromBytes(BytesFromECDSAPrivateKey(privateKey))
+		k.PublicKey = fmt.Sprintf("%x", BytesFromECDSAPublicKey(&privateKey.PublicKey))
+		wipeBigInt(privateKey.D)
+	}
+}
+
+// WithPublicKeyHex sets compressed or uncompressed public key of watch-only key, the encoding is
+// validated on first use
+func WithPublicKeyHex(publicKey string) WithKeyOption {
+	return func(k *Key) {
+		k.PublicKey = publicKey
+	}
+}
+
+func WithECDSAPublicKey(publicKey *ecdsa.PublicKey) WithKeyOption {
+	return func(k *Key) {
+		k.PublicKey = fmt.Sprintf("%x", BytesFromECDSAPublicKey(publicKey))
+	}
+}
+
+// NewPublicKey creates public-only key from compressed or uncompressed encoding,
+// as exported by hardware wallets
+func NewPublicKey(publicKey []byte) (*Key, error) {
+	pub, err := ECDSAPublicKeyFromBytes(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(WithECDSAPublicKey(pub)), nil
+}
+
+// Key keeps private key bytes in secret buffer, they are never printed or marshaled,
+// call Destroy to wipe them when the key is not needed anymore
 type Key struct {
-	PublicKey  string
-	PrivateKey string
-	privateKey *ecdsa.PrivateKey
+	PublicKey string
+	secretKey *secret.Buffer
+}
+
+// hasPrivateKey reports whether the key holds private key material
+func (k *Key) hasPrivateKey() bool {
+	return k.secretKey != nil && !k.secretKey.Destroyed()
+}
+
+// validPrivateKey reports whether the private key is a valid scalar
+func (k *Key) validPrivateKey() bool {
+	return k.usePrivateKey(func(*ecdsa.PrivateKey) error { return nil }) == nil
+}
+
+// usePrivateKey calls fn with private key parsed from the secret buffer. The parsed scalar lives
+// on the heap only until fn returns, fn must not retain the key.
+func (k *Key) usePrivateKey(fn func(privateKey *ecdsa.PrivateKey) error) error {
+	if k == nil || !k.hasPrivateKey() {
+		return ErrKeyIsEmpty
+	}
+	err := k.secretKey.Use(func(data []byte) error {
+		d := new(big.Int).SetBytes(data)
+		defer wipeBigInt(d)
+		if len(data) != 32 || !validPrivateKeyScalar(d) {
+			return ErrKeyInvalid
+		}
+		privateKey, _ := ECDSAKeysFromPrivateKeyBytes(data)
+		defer wipeBigInt(privateKey.D)
+		return fn(privateKey)
+	})
+	if errors.Is(err, secret.ErrDestroyed) {
+		return ErrKeyIsEmpty
+	}
+	return err
+}
+
+// HasPrivateKey reports whether the key can sign, false for public-only and destroyed keys
+func (k *Key) HasPrivateKey() bool {
+	return k != nil && k.hasPrivateKey()
+}
+
+// ExportPrivateKey returns a copy of 32-byte private key for an explicit export,
+// the caller should wipe it with secret.Wipe
+func (k *Key) ExportPrivateKey() ([]byte, error) {
+	var exported []byte
+	err := k.usePrivateKey(func(privateKey *ecdsa.PrivateKey) error {
+		exported = BytesFromECDSAPrivateKey(privateKey)
+		return nil
+	})
+	return exported, err
+}
+
+// Destroy wipes private key bytes, the public key is kept. Keys in use by other goroutines must
+// not be destroyed, drop them instead and the garbage collector wipes them.
+func (k *Key) Destroy() {
+	if k == nil {
+		return
+	}
+	k.secretKey.Destroy()
+}
+
+// wipeBigInt zeroes the words of x in place
+func wipeBigInt(x *big.Int) {
+	if x == nil {
+		return
+	}
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+}
+
+// ecdsaPublicKey returns public key of private key or, for keys without it, decoded PublicKey hex
+func (k *Key) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	if k == nil {
+		return nil, ErrKeyIsEmpty
+	}
+	if k.hasPrivateKey() && k.PublicKey == "" {
+		return nil, ErrKeyInvalid
+	}
+	if k.PublicKey == "" {
+		return nil, ErrKeyIsEmpty
+	}
+	return ECDSAPublicKeyFromBytes(hexutil.FromHex(k.PublicKey))
+}
+
+// PublicKeyBytes returns 33-byte compressed or 65-byte uncompressed public key
+func (k *Key) PublicKeyBytes(compressed bool) ([]byte, error) {
+	pub, err := k.ecdsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if compressed {
+		return CompressedBytesFromECDSAPublicKey(pub), nil
+	}
+	return BytesFromECDSAPublicKey(pub), nil
+}
+
+// TronAddress returns base58 Tron address of the key
+func (k *Key) TronAddress() (string, error) {
+	pub, err := k.ecdsaPublicKey()
+	if err != nil {
+		return "", err
+	}
+	return TronAddressFromPublicKey(pub), nil
+}
+
+// EthereumAddress returns EIP-55 checksummed Ethereum address of the key
+func (k *Key) EthereumAddress() (string, error) {
+	pub, err := k.ecdsaPublicKey()
+	if err != nil {
+		return "", err
+	}
+	return EthereumAddressFromPublicKey(pub), nil
 }
@@ -0,0 +1,274 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/mcmx73/easytron/common/secret"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"strings"
+)
+
+// Web3 Secret Storage v3 key files, the format of geth and TronLink keystore exports
+// See https://ethereum.org/en/developers/docs/data-structures-and-encoding/web3-secret-storage/
+
+const (
+	keyStoreVersion = 3
+	keyStoreCipher  = "aes-128-ctr"
+	kdfScrypt       = "scrypt"
+	kdfPBKDF2       = "pbkdf2"
+	pbkdf2PRF       = "hmac-sha256"
+
+	// StandardScryptN and StandardScryptP are scrypt parameters of geth keystore files
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP use about 4MB of memory and are fast enough for mobile devices
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	// limits of kdf parameters of decrypted key files, imported files are not trusted to cost
+	// more than 1GB of memory (128 * N * r bytes) and several seconds of scrypt or pbkdf2
+	maxScryptN          = 1 << 20
+	maxScryptRP         = 1 << 30
+	maxScryptNR         = 1 << 23
+	maxScryptNRP        = 1 << 24
+	maxPBKDF2Iterations = 1 << 22
+	maxKDFDKLen         = 64
+)
+
+type keyStoreJSON struct {
+	Address string         `json:"address"`
+	Crypto  keyStoreCrypto `json:"crypto"`
+	Id      string         `json:"id"`
+	Version int            `json:"version"`
+}
+
+type keyStoreCrypto struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams keyStoreCipherParams   `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type keyStoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+// EncryptKey encrypts the private key with the password into Web3 Secret Storage v3 JSON using
+// scrypt with the given N and P parameters
+func EncryptKey(key *Key, password string, scryptN, scryptP int) ([]byte, error) {
+	privateKeyBytes, err := key.ExportPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(privateKeyBytes)
+	address, err := key.EthereumAddress()
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	id := make([]byte, 16)
+	for _, b := range [][]byte{salt, iv, id} {
+		if _, err = rand.Read(b); err != nil {
+			return nil, err
+		}
+	}
+	derivedKey, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(derivedKey)
+	cipherText, err := aesCTRXOR(derivedKey[:16], privateKeyBytes, iv)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&keyStoreJSON{
+		Address: strings.ToLower(address[2:]),
+		Crypto: keyStoreCrypto{
+			Cipher:       keyStoreCipher,
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: keyStoreCipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          kdfScrypt,
+			KDFParams: map[string]interface{}{
+				"n":     scryptN,
+				"r":     scryptR,
+				"p":     scryptP,
+				"dklen": scryptDKLen,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(keccak.Keccak256(derivedKey[16:32], cipherText)),
+		},
+		Id:      formatUUID(id),
+		Version: keyStoreVersion,
+	})
+}
+
+// DecryptKey decrypts Web3 Secret Storage v3 JSON with the password
+func DecryptKey(data []byte, password string) (*Key, error) {
+	var keyStore keyStoreJSON
+	if err := json.Unmarshal(data, &keyStore); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+	}
+	if keyStore.Version != keyStoreVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedKeyStore, keyStore.Version)
+	}
+	if keyStore.Crypto.Cipher != keyStoreCipher {
+		return nil, fmt.Errorf("%w: cipher %s", ErrUnsupportedKeyStore, keyStore.Crypto.Cipher)
+	}
+	cipherText, err := hex.DecodeString(keyStore.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ciphertext", ErrInvalidKeyStore)
+	}
+	iv, err := hex.DecodeString(keyStore.Crypto.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("%w: iv", ErrInvalidKeyStore)
+	}
+	mac, err := hex.DecodeString(keyStore.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mac", ErrInvalidKeyStore)
+	}
+	derivedKey, err := deriveKeyStoreKey(&keyStore.Crypto, password)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(derivedKey)
+	if !hmac.Equal(keccak.Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+	privateKeyBytes, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	key := NewKey(WithPrivateKeyBytes(privateKeyBytes))
+	if !key.validPrivateKey() {
+		key.Destroy()
+		return nil, ErrKeyInvalid
+	}
+	if err = checkKeyStoreAddress(key, keyStore.Address); err != nil {
+		key.Destroy()
+		return nil, err
+	}
+	return key, nil
+}
+
+// KeyStoreAddress returns the address field of keystore JSON without decrypting it
+func KeyStoreAddress(data []byte) (string, error) {
+	var keyStore keyStoreJSON
+	if err := json.Unmarshal(data, &keyStore); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+	}
+	return keyStore.Address, nil
+}
+
+// checkKeyStoreAddress compares decrypted key with optional address field, geth writes lowercase
+// hex without 0x and TronLink writes base58 Tron address
+func checkKeyStoreAddress(key *Key, address string) error {
+	if address == "" {
+		return nil
+	}
+	var expected string
+	var err error
+	if addressBytes, decodeErr := hex.DecodeString(strings.TrimPrefix(strings.ToLower(address), "0x")); decodeErr == nil && len(addressBytes) == 20 {
+		expected, err = key.EthereumAddress()
+		address = EthereumChecksumAddress(addressBytes)
+	} else {
+		expected, err = key.TronAddress()
+	}
+	if err != nil {
+		return err
+	}
+	if expected != address {
+		return fmt.Errorf("%w: key does not match address %s", ErrInvalidKeyStore, address)
+	}
+	return nil
+}
+
+func deriveKeyStoreKey(crypto *keyStoreCrypto, password string) ([]byte, error) {
+	params := crypto.KDFParams
+	salt, err := hex.DecodeString(stringParam(params, "salt"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: salt", ErrInvalidKeyStore)
+	}
+	dkLen := intParam(params, "dklen")
+	if dkLen < 32 || dkLen > maxKDFDKLen {
+		return nil, fmt.Errorf("%w: dklen", ErrInvalidKeyStore)
+	}
+	switch crypto.KDF {
+	case kdfScrypt:
+		n, r, p := intParam(params, "n"), intParam(params, "r"), intParam(params, "p")
+		if n <= 1 || n > maxScryptN || n&(n-1) != 0 {
+			return nil, fmt.Errorf("%w: scrypt n %d", ErrInvalidKeyStore, n)
+		}
+		if r <= 0 || p <= 0 || int64(r)*int64(p) >= maxScryptRP ||
+			int64(n)*int64(r) > maxScryptNR || int64(n)*int64(r)*int64(p) > maxScryptNRP {
+			return nil, fmt.Errorf("%w: scrypt n %d, r %d and p %d", ErrInvalidKeyStore, n, r, p)
+		}
+		derivedKey, err := scrypt.Key([]byte(password), salt, n, r, p, dkLen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeyStore, err)
+		}
+		return derivedKey, nil
+	case kdfPBKDF2:
+		if prf := stringParam(params, "prf"); prf != pbkdf2PRF {
+			return nil, fmt.Errorf("%w: prf %s", ErrUnsupportedKeyStore, prf)
+		}
+		iterations := intParam(params, "c")
+		if iterations <= 0 || iterations > maxPBKDF2Iterations {
+			return nil, fmt.Errorf("%w: c", ErrInvalidKeyStore)
+		}
+		return pbkdf2.Key([]byte(password), salt, iterations, dkLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("%w: kdf %s", ErrUnsupportedKeyStore, crypto.KDF)
+}
+
+func stringParam(params map[string]interface{}, name string) string {
+	s, _ := params[name].(string)
+	return s
+}
+
+// intParam returns integer kdf parameter, 0 when it is missing or not a number
+func intParam(params map[string]interface{}, name string) int {
+	f, _ := params[name].(float64)
+	if f != float64(int(f)) {
+		return 0
+	}
+	return int(f)
+}
+
+func aesCTRXOR(key, in, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// formatUUID formats 16 random bytes as version 4 UUID
+func formatUUID(b []byte) string {
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	var out bytes.Buffer
+	for i, part := range [][]byte{b[:4], b[4:6], b[6:8], b[8:10], b[10:]} {
+		if i > 0 {
+			out.WriteByte('-')
+		}
+		out.WriteString(hex.EncodeToString(part))
+	}
+	return out.String()
+}
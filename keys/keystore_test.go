@@ -0,0 +1,165 @@
+package keys
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Test vectors from https://ethereum.org/en/developers/docs/data-structures-and-encoding/web3-secret-storage/
+var keyStoreTestVectors = []struct {
+	name string
+	json string
+}{
+	{
+		"pbkdf2",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+	},
+	{
+		"scrypt",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"p":8,"r":1,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+	},
+}
+
+func TestDecryptKey(t *testing.T) {
+	for _, vector := range keyStoreTestVectors {
+		key, err := DecryptKey([]byte(vector.json), "testpassword")
+		if err != nil {
+			t.Fatalf("%s: %v", vector.name, err)
+		}
+		if privateKeyHex(key) != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+			t.Errorf("%s: private key %s", vector.name, privateKeyHex(key))
+		}
+		if _, err = DecryptKey([]byte(vector.json), "wrong"); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: expected ErrDecrypt, got %v", vector.name, err)
+		}
+	}
+}
+
+func TestDecryptKeyKDFLimits(t *testing.T) {
+	scryptJSON, pbkdf2JSON := keyStoreTestVectors[1].json, keyStoreTestVectors[0].json
+	hostile := []string{
+		strings.Replace(scryptJSON, `"n":262144`, `"n":4194304`, 1),
+		strings.Replace(scryptJSON, `"n":262144`, `"n":262143`, 1),
+		strings.Replace(scryptJSON, `"p":8,"r":1`, `"p":1,"r":1073741824`, 1),
+		strings.Replace(scryptJSON, `"p":8,"r":1`, `"p":1,"r":64`, 1),
+		strings.Replace(scryptJSON, `"p":8,"r":1`, `"p":1024,"r":1`, 1),
+		strings.Replace(scryptJSON, `"dklen":32`, `"dklen":1073741824`, 1),
+		strings.Replace(pbkdf2JSON, `"c":262144`, `"c":1000000000`, 1),
+	}
+	for _, data := range hostile {
+		if data == scryptJSON || data == pbkdf2JSON {
+			t.Fatal("kdf parameters are not replaced")
+		}
+		if _, err := DecryptKey([]byte(data), "testpassword"); !errors.Is(err, ErrInvalidKeyStore) {
+			t.Errorf("expected ErrInvalidKeyStore, got %v", err)
+		}
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	store := NewKeyStore(t.TempDir(), WithScryptParams(LightScryptN, LightScryptP))
+	m := NewManager(WithKeyStore(store))
+	privateKey, _ := generateKey()
+	key := NewKey(WithECDSAPrivateKey(privateKey))
+	keyHex := privateKeyHex(key)
+	tronAddress, _ := key.TronAddress()
+	ethAddress, _ := key.EthereumAddress()
+	if err := m.StoreKey(key, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.StoreKey(key, "first"); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("expected ErrKeyExists, got %v", err)
+	}
+	addresses, err := store.Addresses()
+	if err != nil || len(addresses) != 1 || addresses[0] != ethAddress {
+		t.Fatalf("addresses %v, %v", addresses, err)
+	}
+
+	m.Lock(tronAddress)
+	if _, found := m.GetKey(ethAddress); found {
+		t.Error("key is not locked")
+	}
+	// callers holding the key may still sign with it
+	if _, err = key.SignTronMessage([]byte("locked")); err != nil {
+		t.Error("locked key is destroyed under its holder:", err)
+	}
+	if err = store.ChangePassword(tronAddress, "first", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Unlock(tronAddress, "first"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+	unlocked, err := m.Unlock(tronAddress, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privateKeyHex(unlocked) != keyHex {
+		t.Error("unlocked key differs")
+	}
+	if _, found := m.GetKey(ethAddress); !found {
+		t.Error("key is not unlocked")
+	}
+
+	exported, err := store.Export(ethAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewKeyStore(t.TempDir(), WithScryptParams(LightScryptN, LightScryptP))
+	imported, err := other.Import(exported, "second", "third")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if privateKeyHex(imported) != keyHex {
+		t.Error("imported key differs")
+	}
+	if _, err = other.LoadKey(ethAddress, "third"); err != nil {
+		t.Error(err)
+	}
+	if err = other.Delete(ethAddress, "third"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.LoadKey(ethAddress, "third"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func privateKeyHex(key *Key) string {
+	privateKeyBytes, _ := key.ExportPrivateKey()
+	return hex.EncodeToString(privateKeyBytes)
+}
+
+func TestManagerRestart(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(WithKeyStore(NewKeyStore(dir, WithScryptParams(LightScryptN, LightScryptP))))
+	keyHex := "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	key, err := m.ImportKey(keyHex, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tronAddress, _ := key.TronAddress()
+
+	restarted := NewManager(WithKeyStore(NewKeyStore(dir)))
+	if _, found := restarted.GetKey(tronAddress); found {
+		t.Error("key is unlocked without password")
+	}
+	if _, err = restarted.Unlock(tronAddress, "wrong"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+	if _, err = restarted.Unlock(tronAddress, "password"); err != nil {
+		t.Fatal(err)
+	}
+	unlocked, found := restarted.GetKey(tronAddress)
+	if !found || privateKeyHex(unlocked) != keyHex {
+		t.Error("restored key mismatch")
+	}
+	exported, err := restarted.ExportKey(tronAddress, "password", KeyFormatHex, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer exported.Destroy()
+	if !strings.Contains(string(exported.Export()), keyHex) {
+		t.Error("exported key mismatch")
+	}
+}
@@ -0,0 +1,216 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/mcmx73/easytron/common"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyFileTimeLayout = "2006-01-02T15-04-05.000000000Z"
+	keyFilePrefix     = "UTC--"
+)
+
+type WithKeyStoreOption func(*KeyStore)
+
+// NewKeyStore creates keystore keeping encrypted key files in the directory, one file per key
+// named like geth does: UTC--<created at>--<hex address>
+func NewKeyStore(dir string, options ...WithKeyStoreOption) *KeyStore {
+	s := &KeyStore{
+		dir:     dir,
+		scryptN: StandardScryptN,
+		scryptP: StandardScryptP,
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// WithScryptParams sets scrypt N and P used to encrypt new and re-encrypted key files
+func WithScryptParams(n, p int) WithKeyStoreOption {
+	return func(s *KeyStore) {
+		s.scryptN = n
+		s.scryptP = p
+	}
+}
+
+type KeyStore struct {
+	mux     sync.Mutex
+	dir     string
+	scryptN int
+	scryptP int
+}
+
+// ScryptParams returns scrypt N and P new key files are encrypted with
+func (s *KeyStore) ScryptParams() (n, p int) {
+	return s.scryptN, s.scryptP
+}
+
+// StoreKey encrypts the key with the password and writes it to a new key file
+func (s *KeyStore) StoreKey(key *Key, password string) error {
+	address, err := key.EthereumAddress()
+	if err != nil {
+		return err
+	}
+	data, err := EncryptKey(key, password, s.scryptN, s.scryptP)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, err = s.keyFile(address); err == nil {
+		return fmt.Errorf("%w: %s", ErrKeyExists, address)
+	}
+	if err = os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	name := keyFilePrefix + time.Now().UTC().Format(keyFileTimeLayout) + "--" + strings.ToLower(address[2:])
+	return common.WriteFileAtomic(filepath.Join(s.dir, name), data)
+}
+
+// LoadKey reads and decrypts key file of the Tron or Ethereum address
+func (s *KeyStore) LoadKey(address, password string) (*Key, error) {
+	s.mux.Lock()
+	path, err := s.keyFile(address)
+	s.mux.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(data, password)
+}
+
+// Export returns encrypted key file of the address as is
+func (s *KeyStore) Export(address string) ([]byte, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	path, err := s.keyFile(address)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// Import decrypts keystore JSON exported from TronLink, geth or another wallet and stores the key
+// encrypted with newPassword and keystore scrypt parameters
+func (s *KeyStore) Import(data []byte, password, newPassword string) (*Key, error) {
+	key, err := DecryptKey(data, password)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.StoreKey(key, newPassword); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ChangePassword re-encrypts key file of the address with the new password, the file is
+// re-encrypted with current keystore scrypt parameters as well
+func (s *KeyStore) ChangePassword(address, password, newPassword string) error {
+	key, err := s.LoadKey(address, password)
+	if err != nil {
+		return err
+	}
+	data, err := EncryptKey(key, newPassword, s.scryptN, s.scryptP)
+	if err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	path, err := s.keyFile(address)
+	if err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(path, data)
+}
+
+// Delete removes key file of the address after checking the password
+func (s *KeyStore) Delete(address, password string) error {
+	if _, err := s.LoadKey(address, password); err != nil {
+		return err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	path, err := s.keyFile(address)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Addresses returns checksummed Ethereum addresses of stored keys
+func (s *KeyStore) Addresses() ([]string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	for _, entry := range entries {
+		if addressBytes := keyFileAddress(entry); addressBytes != nil {
+			addresses = append(addresses, EthereumChecksumAddress(addressBytes))
+		}
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// keyFile finds key file of Tron or Ethereum address, the caller must hold the lock
+func (s *KeyStore) keyFile(address string) (string, error) {
+	addressBytes, err := addressBytes(address)
+	if err != nil {
+		return "", err
+	}
+	suffix := "--" + hex.EncodeToString(addressBytes)
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	for _, entry := range entries {
+		if keyFileAddress(entry) != nil && strings.HasSuffix(strings.ToLower(entry.Name()), suffix) {
+			return filepath.Join(s.dir, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrKeyNotFound, address)
+}
+
+// keyFileAddress returns address encoded in key file name or nil for other files
+func keyFileAddress(entry os.DirEntry) []byte {
+	name := entry.Name()
+	if entry.IsDir() || !strings.HasPrefix(name, keyFilePrefix) {
+		return nil
+	}
+	addressBytes, err := hex.DecodeString(name[strings.LastIndex(name, "--")+2:])
+	if err != nil || len(addressBytes) != 20 {
+		return nil
+	}
+	return addressBytes
+}
+
+// addressBytes decodes 0x hex Ethereum or base58 Tron address
+func addressBytes(address string) ([]byte, error) {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return ethereumAddressBytes(address)
+	}
+	return tronAddressBytes(address)
+}
+
+// ValidateAddress checks 0x hex Ethereum or base58check Tron address
+func ValidateAddress(address string) error {
+	_, err := addressBytes(address)
+	return err
+}
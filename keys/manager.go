@@ -1,6 +1,10 @@
 package keys
 
-import "sync"
+import (
+	"github.com/mcmx73/easytron/common/secret"
+	"strings"
+	"sync"
+)
 
 type WithManagerOption func(*Manager)
 
@@ -14,7 +18,143 @@ func NewManager(options ...WithManagerOption) *Manager {
 	return m
 }
 
+// WithKeyStore persists keys added with StoreKey and allows to Unlock them after restart
+func WithKeyStore(keyStore *KeyStore) WithManagerOption {
+	return func(m *Manager) {
+		m.keyStore = keyStore
+	}
+}
+
 type Manager struct {
-	keyMux sync.RWMutex
-	keys   map[string]*Key
+	keyMux   sync.RWMutex
+	keys     map[string]*Key
+	keyStore *KeyStore
+}
+
+// AddKey registers the key under both its Tron and Ethereum addresses
+func (m *Manager) AddKey(key *Key) error {
+	tronAddress, err := key.TronAddress()
+	if err != nil {
+		return err
+	}
+	ethAddress, err := key.EthereumAddress()
+	if err != nil {
+		return err
+	}
+	m.keyMux.Lock()
+	defer m.keyMux.Unlock()
+	m.keys[normalizeAddress(tronAddress)] = key
+	m.keys[normalizeAddress(ethAddress)] = key
+	return nil
+}
+
+// StoreKey encrypts the key into the keystore and adds it to unlocked keys
+func (m *Manager) StoreKey(key *Key, password string) error {
+	if m.keyStore == nil {
+		return ErrNoKeyStore
+	}
+	if err := m.keyStore.StoreKey(key, password); err != nil {
+		return err
+	}
+	return m.AddKey(key)
+}
+
+// ImportKeyStore stores keystore JSON exported from another wallet and adds the key to unlocked keys
+func (m *Manager) ImportKeyStore(data []byte, password, newPassword string) (*Key, error) {
+	if m.keyStore == nil {
+		return nil, ErrNoKeyStore
+	}
+	key, err := m.keyStore.Import(data, password, newPassword)
+	if err != nil {
+		return nil, err
+	}
+	return key, m.AddKey(key)
+}
+
+// ImportKey decodes private key in any format supported by ImportKey, stores it encrypted with
+// newPassword and adds it to unlocked keys
+func (m *Manager) ImportKey(data, newPassword string, options ...WithImportOption) (*Key, error) {
+	if m.keyStore == nil {
+		return nil, ErrNoKeyStore
+	}
+	key, err := ImportKey(data, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.StoreKey(key, newPassword); err != nil {
+		key.Destroy()
+		return nil, err
+	}
+	return key, nil
+}
+
+// ExportKey decrypts key file of the address with the password and encodes the key in the format,
+// exportPassword encrypts keystore format exports with keystore scrypt parameters. The decrypted
+// copy is wiped.
+func (m *Manager) ExportKey(address, password string, format KeyFormat, exportPassword string) (*secret.Buffer, error) {
+	if m.keyStore == nil {
+		return nil, ErrNoKeyStore
+	}
+	key, err := m.keyStore.LoadKey(address, password)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Destroy()
+	if format == KeyFormatKeyStore {
+		data, err := EncryptKey(key, exportPassword, m.keyStore.scryptN, m.keyStore.scryptP)
+		if err != nil {
+			return nil, err
+		}
+		return secret.FromBytes(data), nil
+	}
+	return ExportKey(key, format, exportPassword)
+}
+
+// Unlock decrypts the key of the address from the keystore and adds it to unlocked keys
+func (m *Manager) Unlock(address, password string) (*Key, error) {
+	if m.keyStore == nil {
+		return nil, ErrNoKeyStore
+	}
+	key, err := m.keyStore.LoadKey(address, password)
+	if err != nil {
+		return nil, err
+	}
+	return key, m.AddKey(key)
+}
+
+// Lock removes the key from unlocked keys, the key file is kept. The key is not destroyed as
+// concurrent GetKey callers may sign with it, the garbage collector wipes it once they are done.
+func (m *Manager) Lock(address string) {
+	m.keyMux.Lock()
+	defer m.keyMux.Unlock()
+	key, found := m.keys[normalizeAddress(address)]
+	if !found {
+		return
+	}
+	for indexedAddress, indexedKey := range m.keys {
+		if indexedKey == key {
+			delete(m.keys, indexedAddress)
+		}
+	}
+}
+
+// KeyStore returns configured keystore or nil
+func (m *Manager) KeyStore() *KeyStore {
+	return m.keyStore
+}
+
+// GetKey returns the key by Tron or Ethereum address
+func (m *Manager) GetKey(address string) (key *Key, found bool) {
+	m.keyMux.RLock()
+	defer m.keyMux.RUnlock()
+	key, found = m.keys[normalizeAddress(address)]
+	return key, found
+}
+
+// normalizeAddress lowercases hex Ethereum addresses, base58 Tron addresses are case-sensitive
+func normalizeAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
 }
@@ -18,7 +18,6 @@ import (
 	"crypto/cipher"
 	"crypto/ecdsa"
 	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/sha512"
 	"errors"
 	"io"
@@ -134,7 +133,23 @@ func Sign(rand io.Reader, priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int, r
 
 	// See [NSA] 3.4.1
 	c := priv.PublicKey.Curve
-	return sign(priv, &csprng, c, hash)
+	nonce := func() (*big.Int, error) {
+		return randFieldElement(c, csprng)
+	}
+	return sign(priv, nonce, c, hash)
+}
+
+// SignDeterministic signs a hash like Sign, but the nonce is derived from the
+// private key and the hash as described in [RFC6979], so signing the same hash
+// twice gives the same signature and does not depend on the quality of rand.
+// Optional extra entropy is mixed into the nonce, pass nil for pure RFC 6979.
+func SignDeterministic(priv *ecdsa.PrivateKey, hash, extraEntropy []byte) (r, s *big.Int, recid byte, err error) {
+	c := priv.PublicKey.Curve
+	generator := newRFC6979(c.Params().N, priv.D, hash, extraEntropy)
+	nonce := func() (*big.Int, error) {
+		return generator.next(), nil
+	}
+	return sign(priv, nonce, c, hash)
 }
 
 // sign also returns a byte (recovery id) for public key recovery
@@ -143,7 +158,8 @@ func Sign(rand io.Reader, priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int, r
 // recid = 1: x = r, y is odd
 // recid = 2: x = r+N, y is even
 // recid = 3: x = r+N, y is odd
-func sign(priv *ecdsa.PrivateKey, csprng *cipher.StreamReader, c elliptic.Curve, hash []byte) (r, s *big.Int, recid byte, err error) {
+// nonce returns the next candidate k in [1, N-1]
+func sign(priv *ecdsa.PrivateKey, nonce func() (*big.Int, error), c elliptic.Curve, hash []byte) (r, s *big.Int, recid byte, err error) {
 	N := c.Params().N
 	if N.Sign() == 0 {
 		return nil, nil, 0, errZeroParam
@@ -151,7 +167,7 @@ func sign(priv *ecdsa.PrivateKey, csprng *cipher.StreamReader, c elliptic.Curve,
 	var k, kInv, y *big.Int
 	for {
 		for {
-			k, err = randFieldElement(c, *csprng)
+			k, err = nonce()
 			if err != nil {
 				r = nil
 				return
@@ -280,9 +296,15 @@ const (
 	invalidSigLength byte = 255
 )
 
-// SignBytes returns the signature in bytes
+// SignBytes returns the signature in bytes, the nonce is deterministic [RFC6979]
 func SignBytes(priv *ecdsa.PrivateKey, hash []byte, flag byte) ([]byte, error) {
-	r, s, v, err := Sign(rand.Reader, priv, hash)
+	return SignBytesWithEntropy(priv, hash, flag, nil)
+}
+
+// SignBytesWithEntropy returns the signature in bytes, extraEntropy is mixed into
+// the deterministic nonce, 32 bytes of it give the same nonce as libsecp256k1 ndata
+func SignBytesWithEntropy(priv *ecdsa.PrivateKey, hash []byte, flag byte, extraEntropy []byte) ([]byte, error) {
+	r, s, v, err := SignDeterministic(priv, hash, extraEntropy)
 	if err != nil {
 		return nil, err
 	}
@@ -368,7 +368,8 @@ func P521() elliptic.Curve {
 // Multiple invocations of this function will return the same value, so it can
 // be used for equality checks and switch statements.
 //
-// The cryptographic operations do not use constant-time algorithms.
+// Point arithmetic uses constant-time fixed-width field elements, the GLV
+// endomorphism and precomputed base point tables.
 func P256k1() elliptic.Curve {
 	initonce.Do(initAll)
 	return secp256k1
@@ -18,7 +18,8 @@ var (
 )
 
 // SignEthereum returns an Ethereum-compatible signature
-// The produced signature is in the 65-byte [R || S || V] format
+// The produced signature is in the 65-byte [R || S || V] format, the nonce is
+// generated deterministically as described in RFC 6979
 //
 // This function is susceptible to chosen plaintext attackes. The
 // caller is responsible to ensure that the given hash cannot be
@@ -31,6 +32,15 @@ func SignEthereum(hash []byte, priv *ecdsa.PrivateKey) ([]byte, error) {
 	return SignBytes(priv, hash, LowerS|RecID)
 }
 
+// SignEthereumWithEntropy returns an Ethereum-compatible signature with extra
+// entropy mixed into the deterministic nonce
+func SignEthereumWithEntropy(hash []byte, priv *ecdsa.PrivateKey, extraEntropy []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, ErrInvalidLength
+	}
+	return SignBytesWithEntropy(priv, hash, LowerS|RecID, extraEntropy)
+}
+
 // VerifyEthereum verifies an Ethereum signature
 // The public key is either compressed (33-byte) or uncompressed (65-byte)
 // format, and the signature should have the 64-byte [R || S] format
@@ -0,0 +1,234 @@
+package secp256k1
+
+// Constant-time arithmetic modulo the secp256k1 field prime
+// p = 2^256 - 2^32 - 977 and the group order n. Values are four little-endian
+// 64-bit limbs kept fully reduced, every operation runs the same instructions
+// regardless of the values, conditional steps are done with masks.
+
+import (
+	"math/bits"
+)
+
+// fieldElement is an element of GF(p)
+type fieldElement [4]uint64
+
+// 2^256 - p
+var fieldComplement = [4]uint64{0x1000003d1, 0, 0, 0}
+
+var (
+	fieldZero = fieldElement{}
+	fieldOne  = fieldElement{1, 0, 0, 0}
+	// 3*b of the curve equation used by the point formulas
+	fieldB3 = fieldElement{21, 0, 0, 0}
+)
+
+// setBytes sets z to the big-endian value b mod p
+func (z *fieldElement) setBytes(b *[32]byte) *fieldElement {
+	limbsFromBytes((*[4]uint64)(z), b)
+	reduceOnce((*[4]uint64)(z), 0, &fieldComplement)
+	return z
+}
+
+// bytes returns big-endian encoding of z
+func (z *fieldElement) bytes() (b [32]byte) {
+	limbsToBytes(&b, (*[4]uint64)(z))
+	return b
+}
+
+func (z *fieldElement) isZero() uint64 {
+	return isZero((*[4]uint64)(z))
+}
+
+func (z *fieldElement) add(a, b *fieldElement) *fieldElement {
+	addMod((*[4]uint64)(z), (*[4]uint64)(a), (*[4]uint64)(b), &fieldComplement)
+	return z
+}
+
+func (z *fieldElement) sub(a, b *fieldElement) *fieldElement {
+	subMod((*[4]uint64)(z), (*[4]uint64)(a), (*[4]uint64)(b), &fieldComplement)
+	return z
+}
+
+func (z *fieldElement) neg(a *fieldElement) *fieldElement {
+	return z.sub(&fieldZero, a)
+}
+
+func (z *fieldElement) mul(a, b *fieldElement) *fieldElement {
+	wide := mul256((*[4]uint64)(a), (*[4]uint64)(b))
+	z.reduce(&wide)
+	return z
+}
+
+func (z *fieldElement) square(a *fieldElement) *fieldElement {
+	return z.mul(a, a)
+}
+
+// squareN sets z to a^(2^n)
+func (z *fieldElement) squareN(a *fieldElement, n int) *fieldElement {
+	z.square(a)
+	for i := 1; i < n; i++ {
+		z.square(z)
+	}
+	return z
+}
+
+// reduce sets z to 512-bit value w mod p using 2^256 ≡ 2^32 + 977 (mod p)
+func (z *fieldElement) reduce(w *[8]uint64) {
+	const c = 0x1000003d1
+	var t [4]uint64
+	var carry uint64
+	// t = w[0:4] + w[4:8]*c, less than 2^290
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(w[4+i], c)
+		var cc uint64
+		lo, cc = bits.Add64(lo, w[i], 0)
+		hi += cc
+		lo, cc = bits.Add64(lo, carry, 0)
+		hi += cc
+		t[i] = lo
+		carry = hi
+	}
+	// fold the top limb once more, the result is less than 2^256 + 2^67
+	hi, lo := bits.Mul64(carry, c)
+	var cc uint64
+	t[0], cc = bits.Add64(t[0], lo, 0)
+	t[1], cc = bits.Add64(t[1], hi, cc)
+	t[2], cc = bits.Add64(t[2], 0, cc)
+	t[3], cc = bits.Add64(t[3], 0, cc)
+	// on overflow the low part is small, so adding c can not overflow again
+	t[0], cc = bits.Add64(t[0], c&-cc, 0)
+	t[1], cc = bits.Add64(t[1], 0, cc)
+	t[2], cc = bits.Add64(t[2], 0, cc)
+	t[3], _ = bits.Add64(t[3], 0, cc)
+	reduceOnce(&t, 0, &fieldComplement)
+	*z = t
+}
+
+// inverse sets z to a^(p-2) = 1/a, the inverse of zero is zero.
+// The binary form of p-2 is 223 ones, 0, 22 ones, 0000, 1, 0, 11, 0, 1,
+// which allows an addition chain of 255 squarings and 15 multiplications.
+func (z *fieldElement) inverse(a *fieldElement) *fieldElement {
+	var x2, x3, x6, x9, x11, x22, x44, x88, x176, x220, x223, t fieldElement
+	x2.square(a).mul(&x2, a)
+	x3.square(&x2).mul(&x3, a)
+	x6.squareN(&x3, 3).mul(&x6, &x3)
+	x9.squareN(&x6, 3).mul(&x9, &x3)
+	x11.squareN(&x9, 2).mul(&x11, &x2)
+	x22.squareN(&x11, 11).mul(&x22, &x11)
+	x44.squareN(&x22, 22).mul(&x44, &x22)
+	x88.squareN(&x44, 44).mul(&x88, &x44)
+	x176.squareN(&x88, 88).mul(&x176, &x88)
+	x220.squareN(&x176, 44).mul(&x220, &x44)
+	x223.squareN(&x220, 3).mul(&x223, &x3)
+
+	t.squareN(&x223, 23).mul(&t, &x22)
+	t.squareN(&t, 5).mul(&t, a)
+	t.squareN(&t, 3).mul(&t, &x2)
+	t.squareN(&t, 2).mul(&t, a)
+	*z = t
+	return z
+}
+
+// selectFrom sets z to b if flag is 1 and to a if flag is 0
+func (z *fieldElement) selectFrom(a, b *fieldElement, flag uint64) *fieldElement {
+	selectLimbs((*[4]uint64)(z), (*[4]uint64)(a), (*[4]uint64)(b), flag)
+	return z
+}
+
+// mul256 returns 512-bit product of a and b
+func mul256(a, b *[4]uint64) (w [8]uint64) {
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, w[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			w[i+j] = lo
+			carry = hi
+		}
+		w[i+4] = carry
+	}
+	return w
+}
+
+// addMod sets z = a + b mod m, where a, b < m and c = 2^256 - m
+func addMod(z, a, b, c *[4]uint64) {
+	var t [4]uint64
+	var carry uint64
+	t[0], carry = bits.Add64(a[0], b[0], 0)
+	t[1], carry = bits.Add64(a[1], b[1], carry)
+	t[2], carry = bits.Add64(a[2], b[2], carry)
+	t[3], carry = bits.Add64(a[3], b[3], carry)
+	reduceOnce(&t, carry, c)
+	*z = t
+}
+
+// subMod sets z = a - b mod m, where a, b < m and c = 2^256 - m
+func subMod(z, a, b, c *[4]uint64) {
+	var t [4]uint64
+	var borrow uint64
+	t[0], borrow = bits.Sub64(a[0], b[0], 0)
+	t[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	t[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	t[3], borrow = bits.Sub64(a[3], b[3], borrow)
+	// on borrow t = a - b + 2^256, adding m is subtracting c
+	mask := -borrow
+	t[0], borrow = bits.Sub64(t[0], c[0]&mask, 0)
+	t[1], borrow = bits.Sub64(t[1], c[1]&mask, borrow)
+	t[2], borrow = bits.Sub64(t[2], c[2]&mask, borrow)
+	t[3], _ = bits.Sub64(t[3], c[3]&mask, borrow)
+	*z = t
+}
+
+// reduceOnce subtracts m from carry*2^256 + t if the value is not less than m,
+// the value must be less than 2m and c = 2^256 - m
+func reduceOnce(t *[4]uint64, carry uint64, c *[4]uint64) {
+	var r [4]uint64
+	var cc uint64
+	r[0], cc = bits.Add64(t[0], c[0], 0)
+	r[1], cc = bits.Add64(t[1], c[1], cc)
+	r[2], cc = bits.Add64(t[2], c[2], cc)
+	r[3], cc = bits.Add64(t[3], c[3], cc)
+	selectLimbs(t, t, &r, carry|cc)
+}
+
+// selectLimbs sets z to b if flag is 1 and to a if flag is 0 without branching
+func selectLimbs(z, a, b *[4]uint64, flag uint64) {
+	mask := -flag
+	z[0] = a[0] ^ (mask & (a[0] ^ b[0]))
+	z[1] = a[1] ^ (mask & (a[1] ^ b[1]))
+	z[2] = a[2] ^ (mask & (a[2] ^ b[2]))
+	z[3] = a[3] ^ (mask & (a[3] ^ b[3]))
+}
+
+// isZero returns 1 if all limbs are zero and 0 otherwise
+func isZero(a *[4]uint64) uint64 {
+	v := a[0] | a[1] | a[2] | a[3]
+	return 1 ^ ((v | -v) >> 63)
+}
+
+// equalMask returns 1 if a == b and 0 otherwise
+func equalMask(a, b uint64) uint64 {
+	v := a ^ b
+	return 1 ^ ((v | -v) >> 63)
+}
+
+func limbsFromBytes(z *[4]uint64, b *[32]byte) {
+	for i := 0; i < 4; i++ {
+		j := 24 - 8*i
+		z[i] = uint64(b[j])<<56 | uint64(b[j+1])<<48 | uint64(b[j+2])<<40 | uint64(b[j+3])<<32 |
+			uint64(b[j+4])<<24 | uint64(b[j+5])<<16 | uint64(b[j+6])<<8 | uint64(b[j+7])
+	}
+}
+
+func limbsToBytes(b *[32]byte, z *[4]uint64) {
+	for i := 0; i < 4; i++ {
+		j := 24 - 8*i
+		for k := 0; k < 8; k++ {
+			b[j+k] = byte(z[i] >> (56 - 8*k))
+		}
+	}
+}
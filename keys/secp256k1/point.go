@@ -0,0 +1,229 @@
+package secp256k1
+
+// Points are kept in homogeneous projective coordinates (X : Y : Z) with
+// x = X/Z and y = Y/Z, the point at infinity is (0 : 1 : 0). Addition and
+// doubling use the complete formulas for a = 0 curves of
+//   [RCB]: Renes, Costello, Batina, "Complete addition formulas for prime order elliptic curves"
+//     https://eprint.iacr.org/2015/1060
+// which have no exceptional cases, so scalar multiplication needs no branches
+// for the infinity, doubling or inverse points.
+
+import (
+	"math/big"
+	"sync"
+)
+
+type projectivePoint struct {
+	x, y, z fieldElement
+}
+
+func newIdentityPoint() *projectivePoint {
+	return &projectivePoint{y: fieldOne}
+}
+
+// setAffine sets p to (x, y), (0, 0) is the point at infinity
+func (p *projectivePoint) setAffine(x, y *big.Int) *projectivePoint {
+	p.x.setBytes(bigTo32(x))
+	p.y.setBytes(bigTo32(y))
+	p.z = fieldOne
+	infinity := p.x.isZero() & p.y.isZero()
+	p.y.selectFrom(&p.y, &fieldOne, infinity)
+	p.z.selectFrom(&p.z, &fieldZero, infinity)
+	return p
+}
+
+// affine returns the affine coordinates of p, (0, 0) for the point at infinity
+func (p *projectivePoint) affine() (x, y *big.Int) {
+	var zInv, ax, ay fieldElement
+	zInv.inverse(&p.z)
+	ax.mul(&p.x, &zInv)
+	ay.mul(&p.y, &zInv)
+	xb, yb := ax.bytes(), ay.bytes()
+	return new(big.Int).SetBytes(xb[:]), new(big.Int).SetBytes(yb[:])
+}
+
+// add sets p = a + b, algorithm 7 of [RCB]
+func (p *projectivePoint) add(a, b *projectivePoint) *projectivePoint {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldElement
+	t0.mul(&a.x, &b.x)
+	t1.mul(&a.y, &b.y)
+	t2.mul(&a.z, &b.z)
+	t3.add(&a.x, &a.y)
+	t4.add(&b.x, &b.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&a.y, &a.z)
+	x3.add(&b.y, &b.z)
+	t4.mul(&t4, &x3)
+	x3.add(&t1, &t2)
+	t4.sub(&t4, &x3)
+	x3.add(&a.x, &a.z)
+	y3.add(&b.x, &b.z)
+	x3.mul(&x3, &y3)
+	y3.add(&t0, &t2)
+	y3.sub(&x3, &y3)
+	x3.add(&t0, &t0)
+	t0.add(&x3, &t0)
+	t2.mul(&fieldB3, &t2)
+	z3.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	y3.mul(&fieldB3, &y3)
+	x3.mul(&t4, &y3)
+	t2.mul(&t3, &t1)
+	x3.sub(&t2, &x3)
+	y3.mul(&y3, &t0)
+	t1.mul(&t1, &z3)
+	y3.add(&t1, &y3)
+	t0.mul(&t0, &t3)
+	z3.mul(&z3, &t4)
+	z3.add(&z3, &t0)
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// double sets p = 2a, algorithm 9 of [RCB]
+func (p *projectivePoint) double(a *projectivePoint) *projectivePoint {
+	var t0, t1, t2, x3, y3, z3 fieldElement
+	t0.square(&a.y)
+	z3.add(&t0, &t0)
+	z3.add(&z3, &z3)
+	z3.add(&z3, &z3)
+	t1.mul(&a.y, &a.z)
+	t2.square(&a.z)
+	t2.mul(&fieldB3, &t2)
+	x3.mul(&t2, &z3)
+	y3.add(&t0, &t2)
+	z3.mul(&t1, &z3)
+	t1.add(&t2, &t2)
+	t2.add(&t1, &t2)
+	t0.sub(&t0, &t2)
+	y3.mul(&t0, &y3)
+	y3.add(&x3, &y3)
+	t1.mul(&a.x, &a.y)
+	x3.mul(&t0, &t1)
+	x3.add(&x3, &x3)
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// condNeg negates p if flag is 1
+func (p *projectivePoint) condNeg(flag uint64) *projectivePoint {
+	var negY fieldElement
+	negY.neg(&p.y)
+	p.y.selectFrom(&p.y, &negY, flag)
+	return p
+}
+
+// selectFrom sets p to b if flag is 1 and keeps it otherwise
+func (p *projectivePoint) selectFrom(b *projectivePoint, flag uint64) *projectivePoint {
+	p.x.selectFrom(&p.x, &b.x, flag)
+	p.y.selectFrom(&p.y, &b.y, flag)
+	p.z.selectFrom(&p.z, &b.z, flag)
+	return p
+}
+
+// pointTable holds 0*P, 1*P, ..., 15*P for 4-bit windows
+type pointTable [16]projectivePoint
+
+func (t *pointTable) init(p *projectivePoint) {
+	t[0] = *newIdentityPoint()
+	t[1] = *p
+	for i := 2; i < 16; i++ {
+		t[i].add(&t[i-1], p)
+	}
+}
+
+// lookup sets p to t[index] reading every entry, so memory access does not
+// depend on the index
+func (t *pointTable) lookup(p *projectivePoint, index uint64) {
+	*p = projectivePoint{}
+	for i := range t {
+		p.selectFrom(&t[i], equalMask(uint64(i), index))
+	}
+}
+
+var (
+	baseTablesOnce sync.Once
+	// baseTables[i][j] = j * 16^i * G
+	baseTables *[64]pointTable
+)
+
+func initBaseTables() {
+	baseTables = new([64]pointTable)
+	var g projectivePoint
+	g.setAffine(secp256k1.Gx, secp256k1.Gy)
+	for i := range baseTables {
+		baseTables[i].init(&g)
+		for j := 0; j < 4; j++ {
+			g.double(&g)
+		}
+	}
+}
+
+// scalarBaseMult sets p = k*G as a sum of one precomputed point per 4-bit
+// window of k, no doublings are needed
+func (p *projectivePoint) scalarBaseMult(k *scalar) *projectivePoint {
+	baseTablesOnce.Do(initBaseTables)
+	var q projectivePoint
+	acc := newIdentityPoint()
+	for i := 0; i < 64; i++ {
+		window := k[i/16] >> (4 * (i % 16)) & 0x0f
+		baseTables[i].lookup(&q, window)
+		acc.add(acc, &q)
+	}
+	*p = *acc
+	return p
+}
+
+// glvWindows is the number of 4-bit windows covering the split scalars
+const glvWindows = 33
+
+// scalarMult sets p = k*a using the endomorphism λ*(x, y) = (β*x, y):
+// k*a = k1*a + k2*(λ*a) with half-length k1 and k2, which halves the doublings
+func (p *projectivePoint) scalarMult(a *projectivePoint, k *scalar) *projectivePoint {
+	k1, k2, neg1, neg2 := splitScalar(k)
+
+	var table1, table2 pointTable
+	table1.init(a)
+	for i := range table2 {
+		table2[i] = table1[i]
+		table2[i].x.mul(&table2[i].x, &endoBeta)
+	}
+
+	var q projectivePoint
+	acc := newIdentityPoint()
+	for i := glvWindows - 1; i >= 0; i-- {
+		for j := 0; j < 4; j++ {
+			acc.double(acc)
+		}
+		table1.lookup(&q, k1[i/16]>>(4*(i%16))&0x0f)
+		acc.add(acc, q.condNeg(neg1))
+		table2.lookup(&q, k2[i/16]>>(4*(i%16))&0x0f)
+		acc.add(acc, q.condNeg(neg2))
+	}
+	*p = *acc
+	return p
+}
+
+// bigTo32 returns 32-byte big-endian encoding of x, values outside of
+// [0, 2^256) are reduced modulo p
+func bigTo32(x *big.Int) *[32]byte {
+	var b [32]byte
+	if x.Sign() < 0 || x.BitLen() > 256 {
+		x = new(big.Int).Mod(x, secp256k1.P)
+	}
+	x.FillBytes(b[:])
+	return &b
+}
+
+// scalarFromBytes reduces big-endian k of any length modulo n
+func scalarFromBytes(k []byte) *scalar {
+	var b [32]byte
+	if len(k) > 32 {
+		new(big.Int).Mod(new(big.Int).SetBytes(k), secp256k1.N).FillBytes(b[:])
+	} else {
+		copy(b[32-len(k):], k)
+	}
+	return new(scalar).setBytes(&b)
+}
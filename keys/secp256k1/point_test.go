@@ -0,0 +1,182 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func randomScalarBytes(t testing.TB) []byte {
+	k, err := rand.Int(rand.Reader, P256k1().Params().N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k.Bytes()
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	p := P256k1().Params().P
+	for i := 0; i < 100; i++ {
+		a, _ := rand.Int(rand.Reader, p)
+		b, _ := rand.Int(rand.Reader, p)
+		var fa, fb, r fieldElement
+		fa.setBytes(bigTo32(a))
+		fb.setBytes(bigTo32(b))
+		check := func(name string, got *fieldElement, want *big.Int) {
+			gotBytes := got.bytes()
+			if new(big.Int).SetBytes(gotBytes[:]).Cmp(want.Mod(want, p)) != 0 {
+				t.Fatalf("%s(%x, %x) = %x, want %x", name, a, b, gotBytes, want)
+			}
+		}
+		check("add", r.add(&fa, &fb), new(big.Int).Add(a, b))
+		check("sub", r.sub(&fa, &fb), new(big.Int).Sub(a, b))
+		check("mul", r.mul(&fa, &fb), new(big.Int).Mul(a, b))
+		check("inverse", r.inverse(&fa), new(big.Int).ModInverse(a, p))
+	}
+	// p - 1 and values at the reduction boundaries
+	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
+	var f, r fieldElement
+	f.setBytes(bigTo32(pMinusOne))
+	square := r.square(&f).bytes()
+	if new(big.Int).SetBytes(square[:]).Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("(p-1)^2 = %x", square)
+	}
+	sum := r.add(&f, &fieldOne).bytes()
+	if new(big.Int).SetBytes(sum[:]).Sign() != 0 {
+		t.Errorf("(p-1)+1 = %x", sum)
+	}
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	n := P256k1().Params().N
+	for i := 0; i < 100; i++ {
+		a, _ := rand.Int(rand.Reader, n)
+		b, _ := rand.Int(rand.Reader, n)
+		var sa, sb, r scalar
+		sa.setBytes(bigTo32(a))
+		sb.setBytes(bigTo32(b))
+		mul := r.mul(&sa, &sb).bytes()
+		if new(big.Int).SetBytes(mul[:]).Cmp(new(big.Int).Mod(new(big.Int).Mul(a, b), n)) != 0 {
+			t.Fatalf("mul(%x, %x) = %x", a, b, mul)
+		}
+		if P256k1().(secp256k1Curve).Inverse(a).Cmp(new(big.Int).ModInverse(a, n)) != 0 {
+			t.Fatalf("inverse(%x) is wrong", a)
+		}
+
+		k1, k2, neg1, neg2 := splitScalar(&sa)
+		k1b, k2b := k1.bytes(), k2.bytes()
+		x1, x2 := new(big.Int).SetBytes(k1b[:]), new(big.Int).SetBytes(k2b[:])
+		if x1.BitLen() > 4*glvWindows || x2.BitLen() > 4*glvWindows {
+			t.Fatalf("split of %x is too long: %x, %x", a, x1, x2)
+		}
+		if neg1 == 1 {
+			x1.Neg(x1)
+		}
+		if neg2 == 1 {
+			x2.Neg(x2)
+		}
+		lambda := hexToBig("5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72")
+		sum := x2.Mul(x2, lambda).Add(x2, x1)
+		if sum.Mod(sum, n).Cmp(a) != 0 {
+			t.Fatalf("k1 + k2*λ != k for %x", a)
+		}
+	}
+}
+
+func TestEndomorphism(t *testing.T) {
+	curve := P256k1()
+	params := curve.Params()
+	lambda := hexToBig("5363ad4cc05c30e0a5261c028812645a122e22ea20816678df02967c1b23bd72")
+	x, y := secp256k1.CurveParams.ScalarBaseMult(lambda.Bytes())
+	beta := endoBeta.bytes()
+	betaX := new(big.Int).Mul(params.Gx, new(big.Int).SetBytes(beta[:]))
+	if betaX.Mod(betaX, params.P).Cmp(x) != 0 || params.Gy.Cmp(y) != 0 {
+		t.Error("λ*G != (β*Gx, Gy)")
+	}
+}
+
+func TestPointArithmetic(t *testing.T) {
+	curve := P256k1()
+	generic := secp256k1.CurveParams
+	for i := 0; i < 20; i++ {
+		k := randomScalarBytes(t)
+		x, y := curve.ScalarBaseMult(k)
+		gx, gy := generic.ScalarBaseMult(k)
+		if x.Cmp(gx) != 0 || y.Cmp(gy) != 0 {
+			t.Fatalf("ScalarBaseMult(%x) = (%x, %x), want (%x, %x)", k, x, y, gx, gy)
+		}
+
+		m := randomScalarBytes(t)
+		mx, my := curve.ScalarMult(x, y, m)
+		gmx, gmy := generic.ScalarMult(x, y, m)
+		if mx.Cmp(gmx) != 0 || my.Cmp(gmy) != 0 {
+			t.Fatalf("ScalarMult(%x) = (%x, %x), want (%x, %x)", m, mx, my, gmx, gmy)
+		}
+
+		ax, ay := curve.Add(x, y, mx, my)
+		gax, gay := generic.Add(x, y, mx, my)
+		if ax.Cmp(gax) != 0 || ay.Cmp(gay) != 0 {
+			t.Fatal("Add differs from generic implementation")
+		}
+		dx, dy := curve.Double(x, y)
+		sx, sy := curve.Add(x, y, x, y)
+		gdx, gdy := generic.Double(x, y)
+		if dx.Cmp(gdx) != 0 || dy.Cmp(gdy) != 0 || sx.Cmp(gdx) != 0 || sy.Cmp(gdy) != 0 {
+			t.Fatal("Double differs from generic implementation")
+		}
+		zx, zy := curve.Add(x, y, x, new(big.Int).Sub(curve.Params().P, y))
+		if zx.Sign() != 0 || zy.Sign() != 0 {
+			t.Fatal("P + (-P) != ∞")
+		}
+	}
+
+	// scalars longer than 32 bytes and equal to N are reduced
+	long := new(big.Int).Add(new(big.Int).Lsh(curve.Params().N, 8), big.NewInt(5)).Bytes()
+	x, y := curve.ScalarBaseMult(long)
+	x5, y5 := curve.ScalarBaseMult([]byte{5})
+	if x.Cmp(x5) != 0 || y.Cmp(y5) != 0 {
+		t.Error("long scalar is not reduced modulo N")
+	}
+}
+
+func BenchmarkScalarBaseMultP256k1(b *testing.B) {
+	k := randomScalarBytes(b)
+	b.Run("optimized", func(b *testing.B) {
+		curve := P256k1()
+		for i := 0; i < b.N; i++ {
+			curve.ScalarBaseMult(k)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		curve := secp256k1.CurveParams
+		for i := 0; i < b.N; i++ {
+			curve.ScalarBaseMult(k)
+		}
+	})
+}
+
+func BenchmarkScalarMultP256k1(b *testing.B) {
+	curve := P256k1()
+	x, y := curve.ScalarBaseMult(randomScalarBytes(b))
+	k := randomScalarBytes(b)
+	b.Run("optimized", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.ScalarMult(x, y, k)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		generic := secp256k1.CurveParams
+		for i := 0; i < b.N; i++ {
+			generic.ScalarMult(x, y, k)
+		}
+	})
+}
+
+func BenchmarkFieldMul(b *testing.B) {
+	var x, y fieldElement
+	x.setBytes(bigTo32(P256k1().Params().Gx))
+	y.setBytes(bigTo32(P256k1().Params().Gy))
+	for i := 0; i < b.N; i++ {
+		x.mul(&x, &y)
+	}
+}
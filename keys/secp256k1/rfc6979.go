@@ -0,0 +1,96 @@
+package secp256k1
+
+// Deterministic nonce generation for ECDSA
+//   [RFC6979]: https://www.rfc-editor.org/rfc/rfc6979
+// Nonces are compatible with libsecp256k1 nonce_function_rfc6979, 32-byte extra
+// entropy is appended to the HMAC key the same way libsecp256k1 uses ndata.
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// rfc6979 is HMAC_DRBG of [RFC6979] 3.2 producing candidate nonces for the
+// private key and the hash, see steps b - h
+type rfc6979 struct {
+	q         *big.Int
+	qlen      int
+	k, v      []byte
+	generated bool
+}
+
+// newRFC6979 initializes the generator, extra is the additional data k' of
+// [RFC6979] 3.6 and may be nil
+func newRFC6979(q, priv *big.Int, hash, extra []byte) *rfc6979 {
+	qlen := q.BitLen()
+	rlen := (qlen + 7) / 8
+
+	// int2octets(x) || bits2octets(h1) || k'
+	h := bits2int(hash, qlen)
+	h.Mod(h, q)
+	seed := make([]byte, 2*rlen, 2*rlen+len(extra))
+	priv.FillBytes(seed[:rlen])
+	h.FillBytes(seed[rlen:])
+	seed = append(seed, extra...)
+
+	g := &rfc6979{
+		q:    q,
+		qlen: qlen,
+		k:    make([]byte, sha256.Size),
+		v:    make([]byte, sha256.Size),
+	}
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+	g.k = hmacSHA256(g.k, g.v, []byte{0x00}, seed)
+	g.v = hmacSHA256(g.k, g.v)
+	g.k = hmacSHA256(g.k, g.v, []byte{0x01}, seed)
+	g.v = hmacSHA256(g.k, g.v)
+	return g
+}
+
+// next returns the next nonce in [1, q-1], callers ask for another nonce when
+// the previous one produced r = 0 or s = 0
+func (g *rfc6979) next() *big.Int {
+	for {
+		if g.generated {
+			g.k = hmacSHA256(g.k, g.v, []byte{0x00})
+			g.v = hmacSHA256(g.k, g.v)
+		}
+		g.generated = true
+
+		var t []byte
+		for len(t)*8 < g.qlen {
+			g.v = hmacSHA256(g.k, g.v)
+			t = append(t, g.v...)
+		}
+		k := bits2int(t, g.qlen)
+		if k.Sign() > 0 && k.Cmp(g.q) < 0 {
+			return k
+		}
+	}
+}
+
+// NonceRFC6979 returns the deterministic nonce for the private key and the
+// hash, extra is optional additional entropy
+func NonceRFC6979(q, priv *big.Int, hash, extra []byte) *big.Int {
+	return newRFC6979(q, priv, hash, extra).next()
+}
+
+// bits2int takes leftmost qlen bits of b as an integer, see [RFC6979] 2.3.2
+func bits2int(b []byte, qlen int) *big.Int {
+	x := new(big.Int).SetBytes(b)
+	if excess := len(b)*8 - qlen; excess > 0 {
+		x.Rsh(x, uint(excess))
+	}
+	return x
+}
+
+func hmacSHA256(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
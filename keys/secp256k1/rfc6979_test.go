@@ -0,0 +1,158 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func hexToBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex in test vector: " + s)
+	}
+	return n
+}
+
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in test vector: " + s)
+	}
+	return b
+}
+
+// Test vectors from RFC 6979 A.2.5, P-256 with SHA-256
+func TestRFC6979P256(t *testing.T) {
+	q := elliptic.P256().Params().N
+	x := hexToBig("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	tests := []struct {
+		msg string
+		k   string
+	}{
+		{"sample", "A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60"},
+		{"test", "D16B6AE827F17175E040871A1C7EC3500192C4C92677336EC2537ACAEE0008E0"},
+	}
+	for _, test := range tests {
+		hash := sha256.Sum256([]byte(test.msg))
+		if k := NonceRFC6979(q, x, hash[:], nil); k.Cmp(hexToBig(test.k)) != 0 {
+			t.Errorf("%s: nonce %X, expected %s", test.msg, k, test.k)
+		}
+	}
+}
+
+// Test vectors of libsecp256k1 compatible implementations (dcrd, Trezor, CoreBitcoin)
+func TestRFC6979Secp256k1(t *testing.T) {
+	q := P256k1().Params().N
+	tests := []struct {
+		key        string
+		hash       string
+		extra      string
+		iterations int
+		nonce      string
+	}{
+		{
+			key:   "0011111111111111111111111111111111111111111111111111111111111111",
+			hash:  "0000000000000000000000000000000000000000000000000000000000000001",
+			nonce: "154e92760f77ad9af6b547edd6f14ad0fae023eb2221bc8be2911675d8a686a3",
+		},
+		{
+			key:   "0011111111111111111111111111111111111111111111111111111111111111",
+			hash:  "0000000000000000000000000000000000000000000000000000000000000001",
+			extra: "0000000000000000000000000000000000000000000000000000000000000002",
+			nonce: "67893461ade51cde61824b20bc293b585d058e6b9f40fb68453d5143f15116ae",
+		},
+		{
+			key:        "0011111111111111111111111111111111111111111111111111111111111111",
+			hash:       "0000000000000000000000000000000000000000000000000000000000000001",
+			iterations: 1,
+			nonce:      "66fca3fe494a6216e4a3f15cfbc1d969c60d9cdefda1a1c193edabd34aa8cd5e",
+		},
+		{
+			key:        "0011111111111111111111111111111111111111111111111111111111111111",
+			hash:       "0000000000000000000000000000000000000000000000000000000000000001",
+			iterations: 2,
+			nonce:      "70da248c92b5d28a52eafca1848b1a37d4cb36526c02553c9c48bb0b895fc77d",
+		},
+	}
+	for i, test := range tests {
+		generator := newRFC6979(q, hexToBig(test.key), hexToBytes(test.hash), hexToBytes(test.extra))
+		k := generator.next()
+		for j := 0; j < test.iterations; j++ {
+			k = generator.next()
+		}
+		if k.Cmp(hexToBig(test.nonce)) != 0 {
+			t.Errorf("#%d: nonce %x, expected %s", i, k, test.nonce)
+		}
+	}
+
+	messages := []struct {
+		key   string
+		msg   string
+		nonce string
+	}{
+		{
+			"cca9fbcc1b41e5a95d369eaa6ddcff73b61a4efaa279cfc6567e8daa39cbaf50",
+			"sample",
+			"2df40ca70e639d89528a6b670d9d48d9165fdc0febc0974056bdce192b8e16a3",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000001",
+			"Satoshi Nakamoto",
+			"8f8a276c19f4149656b280621e358cce24f5f52542772691ee69063b74f15d15",
+		},
+		{
+			"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+			"Satoshi Nakamoto",
+			"33a19b60e25fb6f4435af53a3d42d493644827367e6453928554f43e49aa6f90",
+		},
+		{
+			"f8b8af8ce3c7cca5e300d33939540c10d45ce001b8f252bfbc57ba0342904181",
+			"Alan Turing",
+			"525a82b70e67874398067543fd84c83d30c175fdc45fdeee082fe13b1d7cfdf1",
+		},
+	}
+	for _, test := range messages {
+		hash := sha256.Sum256([]byte(test.msg))
+		if k := NonceRFC6979(q, hexToBig(test.key), hash[:], nil); k.Cmp(hexToBig(test.nonce)) != 0 {
+			t.Errorf("%s: nonce %x, expected %s", test.msg, k, test.nonce)
+		}
+	}
+}
+
+func TestSignDeterministic(t *testing.T) {
+	curve := P256k1()
+	d := big.NewInt(1)
+	x, y := curve.ScalarBaseMult(d.Bytes())
+	priv := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y}, D: d}
+	hash := sha256.Sum256([]byte("Satoshi Nakamoto"))
+
+	sig, err := SignBytes(priv, hash[:], LowerS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"
+	if hex.EncodeToString(sig) != expected {
+		t.Errorf("signature %x, expected %s", sig, expected)
+	}
+	again, _ := SignBytes(priv, hash[:], LowerS)
+	if !bytes.Equal(sig, again) {
+		t.Error("signature is not deterministic")
+	}
+
+	extra := bytes.Repeat([]byte{0x42}, 32)
+	randomized, err := SignEthereumWithEntropy(hash[:], priv, extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(randomized[:64], sig) {
+		t.Error("extra entropy is ignored")
+	}
+	if !VerifyBytes(&priv.PublicKey, hash[:], randomized, LowerS|RecID) {
+		t.Error("signature with extra entropy is invalid")
+	}
+}
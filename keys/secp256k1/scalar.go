@@ -0,0 +1,161 @@
+package secp256k1
+
+import (
+	"math/bits"
+)
+
+// scalar is an integer modulo the group order n
+type scalar [4]uint64
+
+// 2^256 - n
+var scalarComplement = [4]uint64{0x402da1732fc9bebf, 0x4551231950b75fc4, 1, 0}
+
+// n / 2, scalars above it are negated by the GLV split
+var scalarHalfOrder = scalar{0xdfe92f46681b20a0, 0x5d576e7357a4501d, 0xffffffffffffffff, 0x7fffffffffffffff}
+
+// Constants of the endomorphism λ*(x, y) = (β*x, y), see splitScalar
+var (
+	endoBeta      = fieldElement{0xc1396c28719501ee, 0x9cf0497512f58995, 0x6e64479eac3434e9, 0x7ae96a2b657c0710}
+	endoNegLambda = scalar{0xe0cfc810b51283cf, 0xa880b9fc8ec739c2, 0x5ad9e3fd77ed9ba4, 0xac9c52b33fa3cf1f}
+	endoNegB1     = scalar{0x6f547fa90abfe4c3, 0xe4437ed6010e8828, 0, 0}
+	endoNegB2     = scalar{0xd765cda83db1562c, 0x8a280ac50774346d, 0xfffffffffffffffe, 0xffffffffffffffff}
+	endoZ1        = [3]uint64{0x3daa8a1471e8ca7f, 0xe86c90e49284eb15, 0x3086d221a7d46bcd}
+	endoZ2        = [3]uint64{0x221208ac9df506c6, 0x6f547fa90abfe4c4, 0xe4437ed6010e8828}
+)
+
+// setBytes sets z to the big-endian value b mod n
+func (z *scalar) setBytes(b *[32]byte) *scalar {
+	limbsFromBytes((*[4]uint64)(z), b)
+	reduceOnce((*[4]uint64)(z), 0, &scalarComplement)
+	return z
+}
+
+func (z *scalar) bytes() (b [32]byte) {
+	limbsToBytes(&b, (*[4]uint64)(z))
+	return b
+}
+
+func (z *scalar) isZero() uint64 {
+	return isZero((*[4]uint64)(z))
+}
+
+func (z *scalar) add(a, b *scalar) *scalar {
+	addMod((*[4]uint64)(z), (*[4]uint64)(a), (*[4]uint64)(b), &scalarComplement)
+	return z
+}
+
+func (z *scalar) neg(a *scalar) *scalar {
+	subMod((*[4]uint64)(z), &[4]uint64{}, (*[4]uint64)(a), &scalarComplement)
+	return z
+}
+
+func (z *scalar) mul(a, b *scalar) *scalar {
+	w := mul256((*[4]uint64)(a), (*[4]uint64)(b))
+	// each fold replaces the high half h with h*(2^256 - n), 512 bits shrink
+	// to 386, 260, 257 and finally 256 bits
+	for i := 0; i < 4; i++ {
+		w = foldScalar(&w)
+	}
+	t := [4]uint64{w[0], w[1], w[2], w[3]}
+	reduceOnce(&t, 0, &scalarComplement)
+	*z = t
+	return z
+}
+
+// foldScalar returns w[0:4] + w[4:8]*(2^256 - n)
+func foldScalar(w *[8]uint64) (r [8]uint64) {
+	copy(r[:4], w[:4])
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 3; j++ {
+			hi, lo := bits.Mul64(w[4+i], scalarComplement[j])
+			var c uint64
+			lo, c = bits.Add64(lo, r[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			r[i+j] = lo
+			carry = hi
+		}
+		for k := i + 3; k < 8; k++ {
+			r[k], carry = bits.Add64(r[k], carry, 0)
+		}
+	}
+	return r
+}
+
+// isHigh returns 1 if z > n/2 and 0 otherwise
+func (z *scalar) isHigh() uint64 {
+	var borrow uint64
+	_, borrow = bits.Sub64(scalarHalfOrder[0], z[0], 0)
+	_, borrow = bits.Sub64(scalarHalfOrder[1], z[1], borrow)
+	_, borrow = bits.Sub64(scalarHalfOrder[2], z[2], borrow)
+	_, borrow = bits.Sub64(scalarHalfOrder[3], z[3], borrow)
+	return borrow
+}
+
+// condNeg negates z if flag is 1
+func (z *scalar) condNeg(flag uint64) *scalar {
+	var negated scalar
+	negated.neg(z)
+	selectLimbs((*[4]uint64)(z), (*[4]uint64)(z), (*[4]uint64)(&negated), flag)
+	return z
+}
+
+// inverse sets z to a^(n-2) = 1/a, the exponent is public, so the
+// square-and-multiply sequence does not depend on a
+func (z *scalar) inverse(a *scalar) *scalar {
+	exponent := [4]uint64{0xbfd25e8cd036413f, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+	result := scalar{1}
+	for i := 255; i >= 0; i-- {
+		result.mul(&result, &result)
+		if exponent[i/64]>>(i%64)&1 == 1 {
+			result.mul(&result, a)
+		}
+	}
+	*z = result
+	return z
+}
+
+// mulShift320Round returns round(k * z / 2^320) for 192-bit z
+func mulShift320Round(k *scalar, z *[3]uint64) scalar {
+	var w [7]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 3; j++ {
+			hi, lo := bits.Mul64(k[i], z[j])
+			var c uint64
+			lo, c = bits.Add64(lo, w[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			w[i+j] = lo
+			carry = hi
+		}
+		w[i+3] = carry
+	}
+	var r scalar
+	var c uint64
+	r[0], c = bits.Add64(w[5], w[4]>>63, 0)
+	r[1], _ = bits.Add64(w[6], 0, c)
+	return r
+}
+
+// splitScalar splits k into k1 + k2*λ ≡ k (mod n) with k1 and k2 of about 128
+// bits, see algorithm 3.74 of "Guide to Elliptic Curve Cryptography". Divisions
+// by n are replaced with multiplications by precomputed z1 = b2*2^320/n and
+// z2 = -b1*2^320/n. The returned values are absolute, flags mark negative ones.
+func splitScalar(k *scalar) (k1, k2 scalar, neg1, neg2 uint64) {
+	c1 := mulShift320Round(k, &endoZ1)
+	c2 := mulShift320Round(k, &endoZ2)
+	c1.mul(&c1, &endoNegB1)
+	c2.mul(&c2, &endoNegB2)
+	k2.add(&c1, &c2)
+	k1.mul(&k2, &endoNegLambda).add(&k1, k)
+
+	neg1 = k1.isHigh()
+	neg2 = k2.isHigh()
+	k1.condNeg(neg1)
+	k2.condNeg(neg2)
+	return k1, k2, neg1, neg2
+}
@@ -32,3 +32,51 @@ func initSecp256k1() {
 		A:           new(big.Int),
 	}
 }
+
+// The methods below replace the generic math/big implementation of CurveParams
+// with constant-time fixed-width arithmetic, see field.go and point.go
+
+// Add returns the sum of (x1,y1) and (x2,y2)
+func (curve secp256k1Curve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	var p1, p2 projectivePoint
+	p1.setAffine(x1, y1)
+	p2.setAffine(x2, y2)
+	return p1.add(&p1, &p2).affine()
+}
+
+// Double returns 2*(x,y)
+func (curve secp256k1Curve) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	var p projectivePoint
+	p.setAffine(x1, y1)
+	return p.double(&p).affine()
+}
+
+// ScalarMult returns k*(Bx,By) where k is a number in big-endian form
+func (curve secp256k1Curve) ScalarMult(Bx, By *big.Int, k []byte) (x, y *big.Int) {
+	var p projectivePoint
+	p.setAffine(Bx, By)
+	return p.scalarMult(&p, scalarFromBytes(k)).affine()
+}
+
+// ScalarBaseMult returns k*G, where G is the base point of the group
+// and k is an integer in big-endian form
+func (curve secp256k1Curve) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	var p projectivePoint
+	return p.scalarBaseMult(scalarFromBytes(k)).affine()
+}
+
+// CombinedMult returns baseScalar*G + scalar*(bigX,bigY), used by signature verification
+func (curve secp256k1Curve) CombinedMult(bigX, bigY *big.Int, baseScalar, scalar []byte) (x, y *big.Int) {
+	var p, q projectivePoint
+	p.scalarBaseMult(scalarFromBytes(baseScalar))
+	q.setAffine(bigX, bigY)
+	q.scalarMult(&q, scalarFromBytes(scalar))
+	return p.add(&p, &q).affine()
+}
+
+// Inverse returns the inverse of k modulo the group order N
+func (curve secp256k1Curve) Inverse(k *big.Int) *big.Int {
+	s := scalarFromBytes(k.Bytes())
+	b := s.inverse(s).bytes()
+	return new(big.Int).SetBytes(b[:])
+}
@@ -1,23 +1,53 @@
 package keys
 
-import "github.com/mcmx73/easytron/common/seedphrase"
+import (
+	"github.com/mcmx73/easytron/common/secret"
+	"github.com/mcmx73/easytron/common/seedphrase"
+)
 
-func (k *Key) GetKeyBackupPhrase() []string {
+// GetLegacyBackupPhrase returns private key encoded as words, see seedphrase.LegacyKeyToMnemonic.
+// The words point to the static wordlist, clear the phrase with secret.WipeStrings after use.
+func (k *Key) GetLegacyBackupPhrase() []string {
 	if k == nil {
 		panic("Key is nil")
 	}
-	if k.privateKey == nil && k.PrivateKey == "" {
-		panic("Key is empty")
-	} else if k.privateKey == nil {
-		k.privateKey, _ = ECDSAKeysFromPrivateKeyHex(k.PrivateKey)
-		if k.privateKey == nil {
-			panic("Key is invalid")
-		}
+	privateKeyBytes, err := k.ExportPrivateKey()
+	if err != nil {
+		panic(err)
 	}
-	privateKeyBytes := BytesFromECDSAPrivateKey(k.privateKey)
-	backupPhrase, err := seedphrase.Bytes2Mnemonic(privateKeyBytes)
+	defer secret.Wipe(privateKeyBytes)
+	backupPhrase, err := seedphrase.LegacyKeyToMnemonic(privateKeyBytes)
 	if err != nil {
 		panic(err)
 	}
 	return backupPhrase
 }
+
+// RestoreLegacyKey restores the key from phrase made by GetLegacyBackupPhrase
+func RestoreLegacyKey(backupPhrase []string) (*Key, error) {
+	privateKeyBytes, err := seedphrase.LegacyMnemonicToKey(backupPhrase)
+	if err != nil {
+		return nil, err
+	}
+	key := NewKey(WithPrivateKeyBytes(privateKeyBytes))
+	if !key.validPrivateKey() {
+		key.Destroy()
+		return nil, ErrKeyInvalid
+	}
+	return key, nil
+}
+
+// NewMasterKeyFromMnemonic derives BIP32 master key from BIP39 mnemonic and optional passphrase
+func NewMasterKeyFromMnemonic(mnemonic []string, passphrase string) (*ExtendedKey, error) {
+	seed, err := seedphrase.MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer seed.Destroy()
+	var master *ExtendedKey
+	err = seed.Use(func(data []byte) (err error) {
+		master, err = NewMasterKey(data)
+		return err
+	})
+	return master, err
+}
@@ -1,6 +1,7 @@
 package keys
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"github.com/mcmx73/easytron/common/base58"
 )
@@ -17,7 +18,27 @@ func tronNewAddress() (privateKeyHex, address string, err error) {
 	}
 	privateKeyBytes := BytesFromECDSAPrivateKey(key)
 	privateKeyHex = fmt.Sprintf("%x", privateKeyBytes)
-	addressBytes := pubKeyToKeccak256HashBytes(key.PublicKey)
-	address = base58.CheckEncode(addressBytes, TRON_NETID)
+	address = TronAddressFromPublicKey(&key.PublicKey)
 	return privateKeyHex, address, nil
 }
+
+// tronAddressBytes decodes base58 Tron address and returns 20 address bytes without version
+func tronAddressBytes(address string) ([]byte, error) {
+	addressBytes, version, err := base58.CheckDecode(address)
+	if err != nil || version != TRON_NETID || len(addressBytes) != 20 {
+		return nil, ErrInvalidAddress
+	}
+	return addressBytes, nil
+}
+
+// ValidateTronAddress checks base58check Tron address with 0x41 version byte
+func ValidateTronAddress(address string) error {
+	_, err := tronAddressBytes(address)
+	return err
+}
+
+// TronAddressFromPublicKey returns base58check encoded Tron address for the public key
+func TronAddressFromPublicKey(pub *ecdsa.PublicKey) string {
+	addressBytes := pubKeyToKeccak256HashBytes(*pub)
+	return base58.CheckEncode(addressBytes, TRON_NETID)
+}
@@ -0,0 +1,50 @@
+package keys
+
+import (
+	"bytes"
+	"github.com/mcmx73/easytron/keys/keccak"
+	"strconv"
+)
+
+const (
+	tronSignedMessagePrefix = "\x19TRON Signed Message:\n"
+)
+
+// TronMessageHash returns hash of the message as TronWeb hashMessage does:
+// keccak256("\x19TRON Signed Message:\n" + len(message) + message)
+func TronMessageHash(message []byte) []byte {
+	prefix := tronSignedMessagePrefix + strconv.Itoa(len(message))
+	return keccak.Keccak256([]byte(prefix), message)
+}
+
+// SignTronMessage signs the message compatible with TronWeb signMessageV2
+// The produced signature is in the 65-byte [R || S || V] format with V = 27 or 28
+func (k *Key) SignTronMessage(message []byte) ([]byte, error) {
+	return k.signHash(TronMessageHash(message))
+}
+
+// RecoverTronMessageAddress returns base58 address of the message signer
+func RecoverTronMessageAddress(message, signature []byte) (string, error) {
+	key, err := RecoverPublicKey(TronMessageHash(message), signature)
+	if err != nil {
+		return "", err
+	}
+	return key.TronAddress()
+}
+
+// VerifyTronMessage reports whether signMessageV2 signature of the message was made by address
+func VerifyTronMessage(message, signature []byte, address string) (bool, error) {
+	expected, err := tronAddressBytes(address)
+	if err != nil {
+		return false, err
+	}
+	signer, err := RecoverTronMessageAddress(message, signature)
+	if err != nil {
+		return false, err
+	}
+	signerBytes, err := tronAddressBytes(signer)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(expected, signerBytes), nil
+}
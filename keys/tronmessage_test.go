@@ -0,0 +1,65 @@
+package keys
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestTronMessage(t *testing.T) {
+	key := NewKey(WithPrivateKeyHex("0000000000000000000000000000000000000000000000000000000000000001"))
+	address, err := key.TronAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if address != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" {
+		t.Error("Tron address mismatch:", address)
+	}
+	message := []byte("I own this address")
+	if hash := hex.EncodeToString(TronMessageHash(message)); hash != "1cb49c4561fe08e880638780c39e3df4ea65fd1a7e5dad21718a62539427a45f" {
+		t.Error("TronMessageHash mismatch:", hash)
+	}
+	signature, err := key.SignTronMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// deterministic RFC 6979 low-s signature, as signMessageV2 returns it
+	want := "b7def1c5c352f690c7580c7af5237e9cb1327cf4ec27bf4d9e9a1acc85dd1cb2" +
+		"09f3a0212cdc8ae53d0ca19ee4074d911e77b6fdfc504647214ba7f2ae1d5f50" + "1b"
+	if hex.EncodeToString(signature) != want {
+		t.Errorf("SignTronMessage mismatch: %x", signature)
+	}
+	signer, err := RecoverTronMessageAddress(message, signature)
+	if err != nil || signer != address {
+		t.Error("RecoverTronMessageAddress mismatch:", signer, err)
+	}
+	valid, err := VerifyTronMessage(message, signature, address)
+	if err != nil || !valid {
+		t.Error("VerifyTronMessage failed", err)
+	}
+	// recovery id without 27 offset is accepted too
+	signature[64] -= 27
+	valid, err = VerifyTronMessage(message, signature, address)
+	if err != nil || !valid {
+		t.Error("VerifyTronMessage failed for recovery id", signature[64], err)
+	}
+	valid, err = VerifyTronMessage([]byte("I own this address!"), signature, address)
+	if err != nil || valid {
+		t.Error("VerifyTronMessage passed with other message", err)
+	}
+	// personal_sign signature of the same key must not be accepted as Tron message signature
+	ethSignature, err := key.SignEthereumMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, _ = VerifyTronMessage(message, ethSignature, address)
+	if valid {
+		t.Error("VerifyTronMessage passed with Ethereum message signature")
+	}
+	if _, err = VerifyTronMessage(message, signature, "TInvalidAddress"); !errors.Is(err, ErrInvalidAddress) {
+		t.Error("Invalid address is not detected:", err)
+	}
+	if _, err = RecoverTronMessageAddress(message, signature[:64]); !errors.Is(err, ErrInvalidSignature) {
+		t.Error("Short signature is not detected:", err)
+	}
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"github.com/mcmx73/easytron/boltstorage"
 	"github.com/mcmx73/easytron/frontrpc"
 	"github.com/mcmx73/easytron/keys"
 	"github.com/mcmx73/easytron/rpc"
 	"github.com/mcmx73/easytron/tronadapter"
 	"github.com/mcmx73/easytron/wallet"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 // TODO crypto module for generate private key and address for Tron/Ethereum
@@ -20,23 +25,62 @@ var (
 func main() {
 	//TODO read config or get from front app
 	tronRpcClient := rpc.NewClient(
-		rpc.WithUrl("https://api.trongrid.io"),
+		// TronGrid throttles clients without API key, several comma separated keys are rotated
+		rpc.WithEndpointConfig(&rpc.EndpointConfig{
+			Url:       "https://api.trongrid.io",
+			ApiKeys:   []rpc.Secret{"$TRONGRID_API_KEYS"},
+			RateLimit: 3,
+			Burst:     5,
+		}),
+		rpc.WithProbe(rpc.TronGenesisProbe, rpc.TronMainnetGenesis),
+		rpc.WithHealthCheckInterval(time.Minute),
 	)
+	initCtx, cancelInit := context.WithTimeout(context.Background(), rpc.DefaultRequestTimeout)
+	err := tronRpcClient.Init(initCtx)
+	cancelInit()
+	// the wallet works offline on cached data, a node of other chain is misconfiguration
+	if errors.Is(err, rpc.ErrChainMismatch) {
+		os.Exit(-1)
+	}
 	tronAdapter := tronadapter.NewClient(
 		tronadapter.WithRpcClient(tronRpcClient),
 	)
-	keyManager := keys.NewManager()
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		os.Exit(-1)
+	}
+	storage, err := boltstorage.Open(filepath.Join(configDir, "easytron", "wallet.db"))
+	if err != nil {
+		os.Exit(-1)
+	}
+	// private keys are kept encrypted in key files, they are unlocked with their passwords
+	keyManager := keys.NewManager(
+		keys.WithKeyStore(keys.NewKeyStore(filepath.Join(configDir, "easytron", "keystore"))),
+	)
 	walletManager = wallet.NewManager(
 		wallet.WithKeyManager(keyManager),
+		wallet.WithStorage(storage),
+		wallet.WithPolicyFile(filepath.Join(configDir, "easytron", "policy.json")),
 	)
 	walletManager.AddCoin(tronAdapter)
+	// cached accounts are available at once, network refresh happens on requests
+	if err = walletManager.Load(); err != nil {
+		os.Exit(-1)
+	}
+
+	syncer := wallet.NewSyncer(walletManager)
+	syncer.Start(context.Background())
 
 	serverOptions := []frontrpc.WithServerOption{
 		frontrpc.WithWalletManager(walletManager),
+		frontrpc.WithSyncer(syncer),
 	}
 
 	frontServer := frontrpc.NewServer(serverOptions...)
-	err := frontServer.Start()
+	err = frontServer.Start()
+	syncer.Stop()
+	tronRpcClient.Close()
+	_ = walletManager.Close()
 	if err != nil {
 		os.Exit(-1)
 	}
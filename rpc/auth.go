@@ -0,0 +1,231 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TronGridApiKeyHeader carries TronGrid API key, requests without it are throttled
+	TronGridApiKeyHeader = "TRON-PRO-API-KEY"
+
+	// DefaultApiKeyRest is how long API key over its quota is not used when the endpoint did not
+	// tell with Retry-After
+	DefaultApiKeyRest = time.Minute
+
+	redacted = "***"
+)
+
+// Secret is a credential printed as *** by fmt and encoding/json, so configs can be logged
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Expand replaces $NAME and ${NAME} with environment variables, e.g. "$TRONGRID_API_KEY"
+func (s Secret) Expand() string {
+	return os.ExpandEnv(string(s))
+}
+
+// EnvList returns comma separated values of the environment variable, e.g. several API keys
+func EnvList(name string) []string {
+	return splitList(os.Getenv(name))
+}
+
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// EndpointConfig is endpoint settings of a config file. Secrets may reference environment
+// variables, e.g. "api_keys": ["$TRONGRID_API_KEYS"] with comma separated keys in the variable.
+type EndpointConfig struct {
+	Url          string            `json:"url"`
+	Headers      map[string]Secret `json:"headers,omitempty"`
+	ApiKeyHeader string            `json:"api_key_header,omitempty"`
+	ApiKeys      []Secret          `json:"api_keys,omitempty"`
+	BasicAuth    *BasicAuthConfig  `json:"basic_auth,omitempty"`
+	BearerToken  Secret            `json:"bearer_token,omitempty"`
+	RateLimit    float64           `json:"rate_limit,omitempty"`
+	Burst        int               `json:"burst,omitempty"`
+}
+
+type BasicAuthConfig struct {
+	User     string `json:"user"`
+	Password Secret `json:"password"`
+}
+
+// Options returns endpoint options of the config with environment variables expanded
+func (c *EndpointConfig) Options() []WithEndpointOption {
+	var options []WithEndpointOption
+	for name, value := range c.Headers {
+		options = append(options, WithEndpointHeader(name, value.Expand()))
+	}
+	var keys []string
+	for _, key := range c.ApiKeys {
+		keys = append(keys, splitList(key.Expand())...)
+	}
+	if len(keys) > 0 {
+		header := c.ApiKeyHeader
+		if header == "" {
+			header = TronGridApiKeyHeader
+		}
+		options = append(options, WithEndpointApiKeys(header, keys...))
+	}
+	if c.BasicAuth != nil {
+		options = append(options, WithEndpointBasicAuth(c.BasicAuth.User, c.BasicAuth.Password.Expand()))
+	}
+	if token := c.BearerToken.Expand(); token != "" {
+		options = append(options, WithEndpointBearerToken(token))
+	}
+	if c.RateLimit > 0 {
+		options = append(options, WithEndpointRateLimit(c.RateLimit, c.Burst))
+	}
+	return options
+}
+
+// WithEndpointApiKeys sends one of the keys in the header. Keys are rotated when the endpoint
+// answers 429, 401 or 403 to the key in use, the key rests until Retry-After or for DefaultApiKeyRest.
+func WithEndpointApiKeys(header string, keys ...string) WithEndpointOption {
+	return func(e *Endpoint) {
+		e.keyHeader = header
+		for _, key := range keys {
+			if key != "" {
+				e.keys = append(e.keys, key)
+				e.keysRestUntil = append(e.keysRestUntil, time.Time{})
+				e.secrets = append(e.secrets, key)
+			}
+		}
+	}
+}
+
+// WithTronGridApiKeys sends TronGrid API keys in TronGridApiKeyHeader
+func WithTronGridApiKeys(keys ...string) WithEndpointOption {
+	return WithEndpointApiKeys(TronGridApiKeyHeader, keys...)
+}
+
+func WithEndpointBasicAuth(user, password string) WithEndpointOption {
+	return func(e *Endpoint) {
+		request := &http.Request{Header: make(http.Header)}
+		request.SetBasicAuth(user, password)
+		e.headers.Set("Authorization", request.Header.Get("Authorization"))
+		e.secrets = append(e.secrets, password, strings.TrimPrefix(request.Header.Get("Authorization"), "Basic "))
+	}
+}
+
+func WithEndpointBearerToken(token string) WithEndpointOption {
+	return func(e *Endpoint) {
+		e.headers.Set("Authorization", "Bearer "+token)
+		e.secrets = append(e.secrets, token)
+	}
+}
+
+// WithEndpointSecret redacts the values from errors and statuses of the endpoint, e.g. API key
+// which is a part of the endpoint URL
+func WithEndpointSecret(secrets ...string) WithEndpointOption {
+	return func(e *Endpoint) {
+		e.secrets = append(e.secrets, secrets...)
+	}
+}
+
+// quotaStatus reports responses to API key over its quota or rejected
+func quotaStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// apiKey returns index and value of the key to use, the current one unless it rests. Index is -1
+// for endpoints without keys.
+func (e *Endpoint) apiKey(now time.Time) (int, string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if len(e.keys) == 0 {
+		return -1, ""
+	}
+	best := e.nextKey
+	for i := range e.keys {
+		index := (e.nextKey + i) % len(e.keys)
+		if !now.Before(e.keysRestUntil[index]) {
+			best = index
+			break
+		}
+		if e.keysRestUntil[index].Before(e.keysRestUntil[best]) {
+			best = index
+		}
+	}
+	e.nextKey = best
+	return best, e.keys[best]
+}
+
+// keyOverQuota rests the key and reports whether other key is ready to use
+func (e *Endpoint) keyOverQuota(index int, now, retryAt time.Time) bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if retryAt.IsZero() {
+		retryAt = now.Add(DefaultApiKeyRest)
+	}
+	e.keysRestUntil[index] = retryAt
+	for i := 1; i < len(e.keys); i++ {
+		next := (index + i) % len(e.keys)
+		if !now.Before(e.keysRestUntil[next]) {
+			e.nextKey = next
+			return true
+		}
+	}
+	return false
+}
+
+// redact replaces secrets of the endpoint in s with ***
+func (e *Endpoint) redact(s string) string {
+	for _, secret := range e.secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
+
+// redactError returns error with secrets of the endpoint redacted from its message
+func (e *Endpoint) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := e.redact(err.Error())
+	if message == err.Error() {
+		return err
+	}
+	return &redactedError{err: err, message: message}
+}
+
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
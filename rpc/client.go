@@ -1,22 +1,408 @@
 package rpc
 
-import "net/http"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRequestTimeout      = 15 * time.Second
+	DefaultMaxRetries          = 3
+	DefaultRetryBackoff        = 250 * time.Millisecond
+	DefaultMaxRetryBackoff     = 5 * time.Second
+	DefaultEndpointCooldown    = 10 * time.Second
+	DefaultMaxEndpointCooldown = 5 * time.Minute
+
+	maxResponseSize = 32 << 20
+)
 
 type WithOption func(*Client)
 
 func NewClient(options ...WithOption) (c *Client) {
-	c = &Client{}
+	c = &Client{
+		headers:         make(http.Header),
+		httpClient:      http.DefaultClient,
+		requestTimeout:  DefaultRequestTimeout,
+		maxRetries:      DefaultMaxRetries,
+		retryBackoff:    DefaultRetryBackoff,
+		maxRetryBackoff: DefaultMaxRetryBackoff,
+		cooldown:        DefaultEndpointCooldown,
+		maxCooldown:     DefaultMaxEndpointCooldown,
+		stop:            make(chan struct{}),
+	}
 	for _, opt := range options {
 		opt(c)
 	}
 	return c
 }
 
+// Client sends requests to the first healthy endpoint of the list. Failed endpoints are taken out
+// of rotation for a cooldown, idempotent requests are retried on the next endpoint or after
+// exponential backoff when all endpoints are down.
 type Client struct {
-	nodeUrl    string
-	httpClient *http.Client
+	endpoints       []*Endpoint
+	headers         http.Header
+	httpClient      *http.Client
+	requestTimeout  time.Duration
+	maxRetries      int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	cooldown        time.Duration
+	maxCooldown     time.Duration
+
+	jsonRpcPath string
+	lastId      uint64
+
+	probe               Probe
+	identity            string
+	healthCheckInterval time.Duration
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// Request is HTTP request to the path relative to endpoint URL
+type Request struct {
+	// Method is HTTP method, POST by default
+	Method string
+	Path   string
+	Body   []byte
+	Header http.Header
+	// Idempotent requests, e.g. queries, are retried. Others, e.g. transaction broadcasts, are
+	// only retried when the endpoint refused them with 429 status.
+	Idempotent bool
+}
+
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Endpoint is URL of the endpoint which responded
+	Endpoint string
+}
+
+// DoFunc sends the request, probes use it to request the endpoint they check
+type DoFunc func(ctx context.Context, request *Request) (*Response, error)
+
+// Init checks connectivity and chain identity of endpoints and starts periodic health checks.
+// It fails when no endpoint responds or any endpoint serves other chain than expected. Health
+// checks are started when endpoints are down, so the client recovers when the network is back.
+func (c *Client) Init(ctx context.Context) error {
+	if len(c.endpoints) == 0 {
+		return ErrNoEndpoints
+	}
+	err := c.CheckHealth(ctx)
+	if errors.Is(err, ErrChainMismatch) {
+		return err
+	}
+	if c.healthCheckInterval > 0 {
+		c.startOnce.Do(func() {
+			c.wg.Add(1)
+			go c.healthLoop()
+		})
+	}
+	return err
+}
+
+// Close stops health checks
+func (c *Client) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	c.wg.Wait()
+}
+
+// CheckHealth probes every endpoint, unreachable endpoints are taken out of rotation and endpoints
+// of other chain are disabled for good
+func (c *Client) CheckHealth(ctx context.Context) error {
+	type result struct {
+		endpoint *Endpoint
+		err      error
+	}
+	results := make(chan result, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		go func(endpoint *Endpoint) {
+			results <- result{endpoint, c.checkEndpoint(ctx, endpoint)}
+		}(endpoint)
+	}
+	var mismatch, lastErr error
+	healthy := 0
+	for range c.endpoints {
+		r := <-results
+		switch {
+		case r.err == nil:
+			healthy++
+		case errors.Is(r.err, ErrChainMismatch):
+			mismatch = r.err
+		default:
+			lastErr = r.err
+		}
+	}
+	if mismatch != nil {
+		return mismatch
+	}
+	if healthy == 0 {
+		return fmt.Errorf("%w: %v", ErrEndpointsDown, lastErr)
+	}
+	return nil
 }
 
-func (c *Client) Init() error {
+func (c *Client) checkEndpoint(ctx context.Context, endpoint *Endpoint) error {
+	if err := endpoint.disabledError(); err != nil {
+		return err
+	}
+	probe := c.probe
+	if probe == nil {
+		probe = connectivityProbe
+	}
+	identity, err := probe(ctx, func(ctx context.Context, request *Request) (*Response, error) {
+		return c.doEndpoint(ctx, endpoint, request)
+	})
+	if err != nil {
+		return endpoint.redactError(fmt.Errorf("%s: %w", endpoint.url, err))
+	}
+	endpoint.mux.Lock()
+	endpoint.identity = identity
+	endpoint.mux.Unlock()
+	if c.identity != "" && identity != c.identity {
+		err = endpoint.redactError(fmt.Errorf("%w: %s serves %s, expected %s", ErrChainMismatch, endpoint.url, identity, c.identity))
+		endpoint.disable(err)
+		return err
+	}
 	return nil
 }
+
+func (c *Client) healthLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.requestTimeout)
+			// failures are recorded in endpoint status
+			_ = c.CheckHealth(ctx)
+			cancel()
+		}
+	}
+}
+
+// Endpoints returns health of the endpoints in the configured order
+func (c *Client) Endpoints() []EndpointStatus {
+	now := time.Now()
+	statuses := make([]EndpointStatus, 0, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		statuses = append(statuses, endpoint.status(now))
+	}
+	return statuses
+}
+
+// Do sends the request to the best endpoint: the first healthy one able to send at once under its
+// rate limit. HTTP error statuses return the response together with *StatusError.
+func (c *Client) Do(ctx context.Context, request *Request) (*Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		endpoint, allDown, err := c.pick(time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if attempt > 0 && allDown {
+			if err = sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+		response, err := c.doEndpoint(ctx, endpoint, request)
+		if err == nil {
+			return response, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !retryable(err) || !request.Idempotent && !errors.Is(err, ErrRateLimited) {
+			return response, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// pick returns the healthy endpoint with the shortest rate limit delay, preferring earlier
+// endpoints, or the endpoint coming back first when all are down. Disabled endpoints are skipped.
+func (c *Client) pick(now time.Time) (*Endpoint, bool, error) {
+	if len(c.endpoints) == 0 {
+		return nil, false, ErrNoEndpoints
+	}
+	var up, firstUp *Endpoint
+	var upDelay time.Duration
+	var firstUpAt time.Time
+	for _, endpoint := range c.endpoints {
+		if endpoint.disabledError() != nil {
+			continue
+		}
+		if down, until := endpoint.down(now); down {
+			if firstUp == nil || until.Before(firstUpAt) {
+				firstUp, firstUpAt = endpoint, until
+			}
+			continue
+		}
+		delay := endpoint.limiter.delay(now)
+		if up == nil || delay < upDelay {
+			up, upDelay = endpoint, delay
+		}
+		if delay == 0 {
+			break
+		}
+	}
+	if up != nil {
+		return up, false, nil
+	}
+	if firstUp == nil {
+		return nil, true, fmt.Errorf("%w: endpoints serve other chain", ErrEndpointsDown)
+	}
+	return firstUp, true, nil
+}
+
+// doEndpoint sends the request to the endpoint within its rate limit and the request timeout
+// and records health of the endpoint
+func (c *Client) doEndpoint(ctx context.Context, endpoint *Endpoint, request *Request) (*Response, error) {
+	if err := endpoint.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+	method := request.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	var body io.Reader
+	if request.Body != nil {
+		body = bytes.NewReader(request.Body)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, method, endpoint.url+request.Path, body)
+	if err != nil {
+		return nil, endpoint.redactError(fmt.Errorf("%w: %v", ErrInvalidEndpoint, err))
+	}
+	for _, headers := range []http.Header{c.headers, endpoint.headers} {
+		for name, values := range headers {
+			httpRequest.Header[name] = append([]string(nil), values...)
+		}
+	}
+	keyIndex, key := endpoint.apiKey(time.Now())
+	if keyIndex >= 0 {
+		httpRequest.Header.Set(endpoint.keyHeader, key)
+	}
+	for name, values := range request.Header {
+		httpRequest.Header[name] = append([]string(nil), values...)
+	}
+	if request.Body != nil && httpRequest.Header.Get("Content-Type") == "" {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		// url.Error message contains the URL
+		err = endpoint.redactError(err)
+		endpoint.fail(err, time.Now(), c.cooldown, c.maxCooldown, time.Time{})
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxResponseSize))
+	if err != nil {
+		err = endpoint.redactError(err)
+		endpoint.fail(err, time.Now(), c.cooldown, c.maxCooldown, time.Time{})
+		return nil, err
+	}
+	response := &Response{
+		StatusCode: httpResponse.StatusCode,
+		Header:     httpResponse.Header,
+		Body:       data,
+		Endpoint:   endpoint.Url(),
+	}
+	if httpResponse.StatusCode < http.StatusBadRequest {
+		endpoint.succeed()
+		return response, nil
+	}
+	statusErr := &StatusError{StatusCode: httpResponse.StatusCode, Status: httpResponse.Status, Body: data}
+	now := time.Now()
+	if keyIndex >= 0 && quotaStatus(httpResponse.StatusCode) {
+		statusErr.QuotaExceeded = true
+		if endpoint.keyOverQuota(keyIndex, now, retryAfter(httpResponse.Header, now)) {
+			// the endpoint stays in rotation with the next key
+			return response, statusErr
+		}
+	}
+	if statusErr.Temporary() {
+		endpoint.fail(statusErr, now, c.cooldown, c.maxCooldown, retryAfter(httpResponse.Header, now))
+	} else {
+		// the endpoint works, the request is wrong
+		endpoint.succeed()
+	}
+	return response, statusErr
+}
+
+// backoff doubles retry backoff with every attempt up to max backoff, with jitter spreading
+// retries of concurrent requests
+func (c *Client) backoff(attempt int) time.Duration {
+	backoff := c.retryBackoff
+	for i := 1; i < attempt && backoff < c.maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > c.maxRetryBackoff {
+		backoff = c.maxRetryBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	return !errors.Is(err, ErrInvalidEndpoint)
+}
+
+// retryAfter parses Retry-After header of seconds or HTTP date, zero time when there is none
+func retryAfter(header http.Header, now time.Time) time.Time {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at
+	}
+	return time.Time{}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,7 +1,89 @@
 package rpc
 
+import (
+	"net/http"
+	"time"
+)
+
+// WithUrl adds endpoint of the node without options
 func WithUrl(url string) WithOption {
 	return func(c *Client) {
-		c.nodeUrl = url
+		c.endpoints = append(c.endpoints, newEndpoint(url))
+	}
+}
+
+// WithEndpoint adds endpoint of the node, endpoints are preferred in the order they are added
+func WithEndpoint(url string, options ...WithEndpointOption) WithOption {
+	return func(c *Client) {
+		c.endpoints = append(c.endpoints, newEndpoint(url, options...))
+	}
+}
+
+// WithEndpointConfig adds endpoint of the config, see EndpointConfig
+func WithEndpointConfig(config *EndpointConfig) WithOption {
+	return WithEndpoint(config.Url, config.Options()...)
+}
+
+// WithHeader adds header sent with every request to all endpoints, endpoint headers override it
+func WithHeader(name, value string) WithOption {
+	return func(c *Client) {
+		c.headers.Add(name, value)
+	}
+}
+
+func WithHttpClient(httpClient *http.Client) WithOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithJsonRpcPath sets path of JSON-RPC endpoint relative to endpoint URLs, e.g. "/jsonrpc" of
+// java-tron, Ethereum nodes serve it at the root
+func WithJsonRpcPath(path string) WithOption {
+	return func(c *Client) {
+		c.jsonRpcPath = path
+	}
+}
+
+// WithRequestTimeout limits every attempt of a request, zero disables the limit and leaves
+// timeouts to the caller context
+func WithRequestTimeout(timeout time.Duration) WithOption {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithRetries sets number of retries of failed requests and their exponential backoff
+func WithRetries(maxRetries int, backoff, maxBackoff time.Duration) WithOption {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+		c.maxRetryBackoff = maxBackoff
+	}
+}
+
+// WithEndpointCooldown sets how long failed endpoint is out of rotation, the cooldown doubles with
+// every failure in a row up to maxCooldown
+func WithEndpointCooldown(cooldown, maxCooldown time.Duration) WithOption {
+	return func(c *Client) {
+		c.cooldown = cooldown
+		c.maxCooldown = maxCooldown
+	}
+}
+
+// WithProbe sets health check of endpoints, with non-empty identity endpoints of other chains
+// are refused, e.g. WithProbe(TronGenesisProbe, TronMainnetGenesis)
+func WithProbe(probe Probe, identity string) WithOption {
+	return func(c *Client) {
+		c.probe = probe
+		c.identity = identity
+	}
+}
+
+// WithHealthCheckInterval probes endpoints periodically after Init, so endpoints come back into
+// rotation before requests hit them
+func WithHealthCheckInterval(interval time.Duration) WithOption {
+	return func(c *Client) {
+		c.healthCheckInterval = interval
 	}
 }
@@ -0,0 +1,188 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func genesisHandler(blockId string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"blockID":"` + blockId + `"}`))
+	}
+}
+
+func TestFailover(t *testing.T) {
+	failing, failingCalls := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	working, workingCalls := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "yes" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`ok`))
+	})
+	c := NewClient(
+		WithUrl(failing.URL),
+		WithEndpoint(working.URL, WithEndpointHeader("X-Test", "yes")),
+		WithRetries(2, time.Millisecond, time.Millisecond),
+	)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		response, err := c.Do(ctx, &Request{Path: "/wallet/getnowblock", Idempotent: true})
+		if err != nil || string(response.Body) != "ok" || response.Endpoint != working.URL {
+			t.Fatalf("response %v, %v", response, err)
+		}
+	}
+	// the failing endpoint is out of rotation after the first failure
+	if *failingCalls != 1 || *workingCalls != 3 {
+		t.Errorf("calls %d and %d", *failingCalls, *workingCalls)
+	}
+	statuses := c.Endpoints()
+	if statuses[0].Healthy || statuses[0].Failures != 1 || !statuses[1].Healthy {
+		t.Errorf("statuses %+v", statuses)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	server, calls := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	c := NewClient(WithUrl(server.URL), WithRetries(2, time.Millisecond, time.Millisecond))
+	_, err := c.Do(context.Background(), &Request{Idempotent: true})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable || *calls != 3 {
+		t.Errorf("err %v after %d calls", err, *calls)
+	}
+	// broadcasts are not repeated unless the endpoint refused them
+	atomic.StoreInt32(calls, 0)
+	if _, err = c.Do(context.Background(), &Request{Path: "/wallet/broadcasttransaction"}); err == nil || *calls != 1 {
+		t.Errorf("err %v after %d calls", err, *calls)
+	}
+
+	limited, limitedCalls := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	c = NewClient(WithUrl(limited.URL), WithRetries(1, time.Millisecond, time.Millisecond))
+	if _, err = c.Do(context.Background(), &Request{}); !errors.Is(err, ErrRateLimited) || *limitedCalls != 2 {
+		t.Errorf("err %v after %d calls", err, *limitedCalls)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {})
+	c := NewClient(WithEndpoint(server.URL, WithEndpointRateLimit(20, 2)))
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := c.Do(context.Background(), &Request{Idempotent: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// two requests of the burst go at once, two more wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("4 requests took %s", elapsed)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		_, err := c.Do(ctx, &Request{Idempotent: true})
+		if errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+	}
+	t.Error("rate limit wait ignores context")
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	c := NewClient(WithUrl(server.URL), WithRequestTimeout(20*time.Millisecond), WithRetries(0, 0, 0))
+	start := time.Now()
+	if _, err := c.Do(context.Background(), &Request{Idempotent: true}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request took %s", elapsed)
+	}
+}
+
+func TestInit(t *testing.T) {
+	mainnet, _ := testServer(t, genesisHandler(TronMainnetGenesis))
+	other, _ := testServer(t, genesisHandler("0000000000000000d698d4192c56cb6be724a558448e2684802de4d6cd8690dc"))
+	down, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	ctx := context.Background()
+	c := NewClient(WithUrl(down.URL), WithUrl(mainnet.URL), WithProbe(TronGenesisProbe, TronMainnetGenesis))
+	if err := c.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if statuses := c.Endpoints(); statuses[0].Healthy || statuses[1].Identity != TronMainnetGenesis {
+		t.Errorf("statuses %+v", statuses)
+	}
+	c = NewClient(WithUrl(mainnet.URL), WithUrl(other.URL), WithProbe(TronGenesisProbe, TronMainnetGenesis))
+	if err := c.Init(ctx); !errors.Is(err, ErrChainMismatch) {
+		t.Errorf("err %v, want %v", err, ErrChainMismatch)
+	}
+	c = NewClient(WithUrl(down.URL), WithRetries(0, 0, 0))
+	if err := c.Init(ctx); !errors.Is(err, ErrEndpointsDown) {
+		t.Errorf("err %v, want %v", err, ErrEndpointsDown)
+	}
+	if err := NewClient().Init(ctx); !errors.Is(err, ErrNoEndpoints) {
+		t.Errorf("err %v, want %v", err, ErrNoEndpoints)
+	}
+}
+
+func TestChainMismatchDisablesEndpoint(t *testing.T) {
+	mainnet, mainnetCalls := testServer(t, genesisHandler(TronMainnetGenesis))
+	other, otherCalls := testServer(t, genesisHandler("0000000000000000d698d4192c56cb6be724a558448e2684802de4d6cd8690dc"))
+	ctx := context.Background()
+	c := NewClient(WithUrl(other.URL), WithUrl(mainnet.URL), WithProbe(TronGenesisProbe, TronMainnetGenesis),
+		WithEndpointCooldown(time.Millisecond, time.Millisecond))
+	if err := c.Init(ctx); !errors.Is(err, ErrChainMismatch) {
+		t.Fatalf("err %v, want %v", err, ErrChainMismatch)
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.StoreInt32(otherCalls, 0)
+	for i := 0; i < 3; i++ {
+		if _, err := c.Do(ctx, &Request{Path: "/wallet/getnowblock", Idempotent: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := atomic.LoadInt32(otherCalls); calls != 0 {
+		t.Errorf("endpoint of other chain got %d requests after cooldown", calls)
+	}
+	if calls := atomic.LoadInt32(mainnetCalls); calls < 3 {
+		t.Errorf("mainnet endpoint got %d requests", calls)
+	}
+	if err := c.CheckHealth(ctx); !errors.Is(err, ErrChainMismatch) {
+		t.Errorf("err %v, want %v", err, ErrChainMismatch)
+	}
+	if statuses := c.Endpoints(); !statuses[0].Disabled || statuses[0].Healthy {
+		t.Errorf("statuses %+v", statuses)
+	}
+	c = NewClient(WithUrl(other.URL), WithProbe(TronGenesisProbe, TronMainnetGenesis), WithRetries(0, 0, 0))
+	_ = c.Init(ctx)
+	if _, err := c.Do(ctx, &Request{Path: "/wallet/getnowblock", Idempotent: true}); !errors.Is(err, ErrEndpointsDown) {
+		t.Errorf("err %v, want %v", err, ErrEndpointsDown)
+	}
+}
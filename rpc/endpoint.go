@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type WithEndpointOption func(*Endpoint)
+
+// WithEndpointHeader adds header sent with every request to the endpoint
+func WithEndpointHeader(name, value string) WithEndpointOption {
+	return func(e *Endpoint) {
+		e.headers.Add(name, value)
+	}
+}
+
+// WithEndpointRateLimit limits requests to the endpoint to rate per second on average with bursts
+// of up to burst requests, e.g. TronGrid throttles clients without API key aggressively
+func WithEndpointRateLimit(rate float64, burst int) WithEndpointOption {
+	return func(e *Endpoint) {
+		if rate > 0 {
+			e.limiter = newTokenBucket(rate, burst)
+		}
+	}
+}
+
+// Endpoint is a node URL with its headers, credentials, rate limit and health
+type Endpoint struct {
+	url     string
+	headers http.Header
+	limiter *tokenBucket
+	// secrets are redacted from statuses and errors
+	secrets []string
+
+	keyHeader     string
+	keys          []string
+	keysRestUntil []time.Time
+	nextKey       int
+
+	mux       sync.Mutex
+	failures  int
+	downUntil time.Time
+	lastError error
+	identity  string
+	// disabled endpoints serve other chain, they never return to rotation
+	disabled bool
+}
+
+func newEndpoint(url string, options ...WithEndpointOption) *Endpoint {
+	e := &Endpoint{url: strings.TrimRight(url, "/"), headers: make(http.Header)}
+	if parsed, err := neturl.Parse(url); err == nil && parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			e.secrets = append(e.secrets, password)
+		}
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+// EndpointStatus describes health of the endpoint
+type EndpointStatus struct {
+	Url       string    `json:"url"`
+	Healthy   bool      `json:"healthy"`
+	Failures  int       `json:"failures"`
+	DownUntil time.Time `json:"down_until,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Identity  string    `json:"identity,omitempty"`
+	Disabled  bool      `json:"disabled,omitempty"`
+}
+
+// Url returns the endpoint URL with secrets redacted
+func (e *Endpoint) Url() string {
+	return e.redact(e.url)
+}
+
+func (e *Endpoint) status(now time.Time) EndpointStatus {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	status := EndpointStatus{
+		Url:       e.redact(e.url),
+		Healthy:   !e.disabled && !now.Before(e.downUntil),
+		Failures:  e.failures,
+		DownUntil: e.downUntil,
+		Identity:  e.identity,
+		Disabled:  e.disabled,
+	}
+	if e.lastError != nil {
+		status.LastError = e.lastError.Error()
+	}
+	return status
+}
+
+// down reports whether the endpoint failed recently and returns when it is tried again
+func (e *Endpoint) down(now time.Time) (bool, time.Time) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return now.Before(e.downUntil), e.downUntil
+}
+
+// disable takes the endpoint out of rotation for good, success of later requests does not return it
+func (e *Endpoint) disable(err error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.disabled = true
+	e.lastError = e.redactError(err)
+}
+
+// disabledError returns the error which disabled the endpoint, nil for endpoints in rotation
+func (e *Endpoint) disabledError() error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if !e.disabled {
+		return nil
+	}
+	return e.lastError
+}
+
+// fail takes the endpoint out of rotation for the cooldown doubled with every failure in a row
+// up to maxCooldown, or until retryAt the endpoint asked for
+func (e *Endpoint) fail(err error, now time.Time, cooldown, maxCooldown time.Duration, retryAt time.Time) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.failures++
+	e.lastError = e.redactError(err)
+	for i := 1; i < e.failures && cooldown < maxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > maxCooldown {
+		cooldown = maxCooldown
+	}
+	e.downUntil = now.Add(cooldown)
+	if retryAt.After(e.downUntil) {
+		e.downUntil = retryAt
+	}
+}
+
+func (e *Endpoint) succeed() {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.failures = 0
+	e.lastError = nil
+	e.downUntil = time.Time{}
+}
@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrNoEndpoints     = errors.New("no rpc endpoints configured")
+	ErrEndpointsDown   = errors.New("all rpc endpoints are down")
+	ErrChainMismatch   = errors.New("rpc endpoint serves other chain")
+	ErrRateLimited     = errors.New("rpc endpoint rate limit exceeded")
+	ErrInvalidEndpoint = errors.New("invalid rpc endpoint")
+	ErrInvalidIdentity = errors.New("invalid chain identity response")
+	ErrInvalidResponse = errors.New("invalid rpc response")
+	ErrMissingResponse = errors.New("no response to rpc call")
+)
+
+// StatusError is returned for HTTP responses with status 400 and above, the response is
+// returned as well
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+	// QuotaExceeded is set when the endpoint refused API key of the request, see WithEndpointApiKeys
+	QuotaExceeded bool
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("rpc endpoint responded %s", e.Status)
+}
+
+// Temporary reports statuses worth retrying on the same or other endpoint
+func (e *StatusError) Temporary() bool {
+	return e.QuotaExceeded || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+func (e *StatusError) Unwrap() error {
+	if e.QuotaExceeded || e.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const jsonRpcVersion = "2.0"
+
+// JSON-RPC 2.0 error codes, servers use -32000 to -32099 for their own errors
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// nonIdempotentMethods change state of the chain, they are not retried after failures
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendRawTransaction": true,
+	"eth_sendTransaction":    true,
+}
+
+// Error is JSON-RPC 2.0 error object of the response
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("json-rpc error %d: %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// DecodeData unmarshals error data, e.g. revert reason of eth_call
+func (e *Error) DecodeData(v interface{}) error {
+	if len(e.Data) == 0 {
+		return fmt.Errorf("%w: no error data", ErrInvalidResponse)
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// BatchElem is a call of BatchCall, Error is set when the call failed
+type BatchElem struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Error  error
+}
+
+type jsonRpcRequest struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Id      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type jsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *Error          `json:"error"`
+}
+
+// Call calls JSON-RPC 2.0 method and decodes its result into result, nil result drops it. Errors
+// of the method are returned as *Error. Params are an array or an object, nil means no params.
+func (c *Client) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	batch := []BatchElem{{Method: method, Params: params, Result: result}}
+	if err := c.BatchCall(ctx, batch); err != nil {
+		return err
+	}
+	return batch[0].Error
+}
+
+// BatchCall sends the calls in one request. Errors of single calls are set to their Error, the
+// returned error is a failure of the whole batch.
+func (c *Client) BatchCall(ctx context.Context, batch []BatchElem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	requests := make([]*jsonRpcRequest, len(batch))
+	byId := make(map[uint64]*BatchElem, len(batch))
+	idempotent := true
+	for i := range batch {
+		params := batch[i].Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		requests[i] = &jsonRpcRequest{JsonRpc: jsonRpcVersion, Id: c.nextId(), Method: batch[i].Method, Params: params}
+		byId[requests[i].Id] = &batch[i]
+		idempotent = idempotent && !nonIdempotentMethods[batch[i].Method]
+	}
+	var body []byte
+	var err error
+	// single calls are sent as request objects, some servers do not support batches
+	if len(requests) == 1 {
+		body, err = json.Marshal(requests[0])
+	} else {
+		body, err = json.Marshal(requests)
+	}
+	if err != nil {
+		return err
+	}
+	response, err := c.Do(ctx, &Request{Path: c.jsonRpcPath, Body: body, Idempotent: idempotent})
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		// servers answer some errors with HTTP error status and JSON-RPC error body
+		if single := decodeSingleResponse(statusErr.Body); single != nil && single.Error != nil {
+			return single.Error
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	responses, err := decodeResponses(response.Body)
+	if err != nil {
+		return err
+	}
+	for _, r := range responses {
+		// the error of the whole request, e.g. parse error, has null id
+		if len(r.Id) == 0 || string(r.Id) == "null" {
+			if r.Error != nil {
+				return r.Error
+			}
+			return fmt.Errorf("%w: response without id", ErrInvalidResponse)
+		}
+		var id uint64
+		if err = json.Unmarshal(r.Id, &id); err != nil {
+			return fmt.Errorf("%w: id %s", ErrInvalidResponse, r.Id)
+		}
+		elem, found := byId[id]
+		if !found {
+			return fmt.Errorf("%w: unexpected id %d", ErrInvalidResponse, id)
+		}
+		delete(byId, id)
+		switch {
+		case r.Error != nil:
+			elem.Error = r.Error
+		case elem.Result != nil:
+			if err = json.Unmarshal(r.Result, elem.Result); err != nil {
+				elem.Error = fmt.Errorf("%w: %s result: %v", ErrInvalidResponse, elem.Method, err)
+			}
+		}
+	}
+	for _, elem := range byId {
+		elem.Error = fmt.Errorf("%w: %s", ErrMissingResponse, elem.Method)
+	}
+	return nil
+}
+
+func (c *Client) nextId() uint64 {
+	return atomic.AddUint64(&c.lastId, 1)
+}
+
+// decodeResponses decodes response object or array of batch responses
+func decodeResponses(body []byte) ([]*jsonRpcResponse, error) {
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "[") {
+		var responses []*jsonRpcResponse
+		if err := json.Unmarshal(body, &responses); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		return responses, nil
+	}
+	single := decodeSingleResponse(body)
+	if single == nil {
+		return nil, fmt.Errorf("%w: %.200s", ErrInvalidResponse, body)
+	}
+	return []*jsonRpcResponse{single}, nil
+}
+
+func decodeSingleResponse(body []byte) *jsonRpcResponse {
+	response := &jsonRpcResponse{}
+	if err := json.Unmarshal(body, response); err != nil || response.JsonRpc != jsonRpcVersion {
+		return nil
+	}
+	return response
+}
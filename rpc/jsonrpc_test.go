@@ -0,0 +1,162 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// jsonRpcHandler answers eth_chainId and eth_blockNumber, other methods fail with data
+func jsonRpcHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jsonrpc" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var requests []*jsonRpcRequest
+		batch := json.Unmarshal(body, &requests) == nil
+		if !batch {
+			request := &jsonRpcRequest{}
+			if err := json.Unmarshal(body, request); err != nil {
+				w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`))
+				return
+			}
+			requests = []*jsonRpcRequest{request}
+		}
+		var responses []map[string]interface{}
+		for _, request := range requests {
+			response := map[string]interface{}{"jsonrpc": "2.0", "id": request.Id}
+			switch request.Method {
+			case "eth_chainId":
+				response["result"] = "0x2b6653dc"
+			case "eth_blockNumber":
+				response["result"] = "0x10"
+			case "eth_skipped":
+				continue
+			default:
+				response["error"] = map[string]interface{}{"code": 3, "message": "execution reverted", "data": "0x08c379a0"}
+			}
+			responses = append(responses, response)
+		}
+		if batch {
+			json.NewEncoder(w).Encode(responses)
+		} else {
+			json.NewEncoder(w).Encode(responses[0])
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	server, _ := testServer(t, jsonRpcHandler())
+	c := NewClient(WithUrl(server.URL), WithJsonRpcPath("/jsonrpc"))
+	ctx := context.Background()
+	var chainId string
+	if err := c.Call(ctx, "eth_chainId", nil, &chainId); err != nil || chainId != "0x2b6653dc" {
+		t.Fatalf("chain id %q, %v", chainId, err)
+	}
+	err := c.Call(ctx, "eth_call", []interface{}{map[string]string{"to": "0x0"}, "latest"}, nil)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != 3 || rpcErr.Message != "execution reverted" {
+		t.Fatalf("err %v", err)
+	}
+	var data string
+	if err = rpcErr.DecodeData(&data); err != nil || data != "0x08c379a0" {
+		t.Errorf("data %q, %v", data, err)
+	}
+
+	var blockNumber string
+	batch := []BatchElem{
+		{Method: "eth_chainId", Result: &chainId},
+		{Method: "eth_blockNumber", Result: &blockNumber},
+		{Method: "eth_call"},
+		{Method: "eth_skipped"},
+	}
+	if err = c.BatchCall(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].Error != nil || batch[1].Error != nil || blockNumber != "0x10" {
+		t.Errorf("batch %+v", batch)
+	}
+	if !errors.As(batch[2].Error, &rpcErr) || !errors.Is(batch[3].Error, ErrMissingResponse) {
+		t.Errorf("errors %v and %v", batch[2].Error, batch[3].Error)
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}}`))
+	})
+	c := NewClient(WithUrl(server.URL))
+	var rpcErr *Error
+	if err := c.Call(context.Background(), "eth_chainId", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidRequest {
+		t.Errorf("err %v", err)
+	}
+	html, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html></html>`))
+	})
+	c = NewClient(WithUrl(html.URL))
+	if err := c.Call(context.Background(), "eth_chainId", nil, nil); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("err %v", err)
+	}
+}
+
+func TestRest(t *testing.T) {
+	var broadcasts int
+	server, _ := testServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wallet/getnowblock":
+			w.Write([]byte(`{"blockID":"0000000003a1b2c3"}`))
+		case "/wallet/getaccount":
+			w.Write([]byte(`{"Error":"class java.lang.IllegalArgumentException : invalid address"}`))
+		case "/wallet/broadcasttransaction":
+			broadcasts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/v1/accounts/TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY/transactions":
+			if r.Method != http.MethodGet || r.URL.Query().Get("only_confirmed") != "true" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"success":false,"error":"bad request","statusCode":400}`))
+				return
+			}
+			w.Write([]byte(`{"data":[{"txID":"abc"}],"success":true,"meta":{"page_size":1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c := NewClient(WithUrl(server.URL), WithRetries(2, time.Millisecond, time.Millisecond))
+	ctx := context.Background()
+	var block struct {
+		BlockId string `json:"blockID"`
+	}
+	if err := c.Post(ctx, "/wallet/getnowblock", nil, &block); err != nil || block.BlockId != "0000000003a1b2c3" {
+		t.Fatalf("block %+v, %v", block, err)
+	}
+	var restErr *RestError
+	err := c.Post(ctx, "/wallet/getaccount", map[string]interface{}{"address": "T", "visible": true}, nil)
+	if !errors.As(err, &restErr) || restErr.StatusCode != http.StatusOK {
+		t.Errorf("err %v", err)
+	}
+	if err = c.Post(ctx, "/wallet/broadcasttransaction", map[string]string{}, nil); err == nil || broadcasts != 1 {
+		t.Errorf("err %v after %d broadcasts", err, broadcasts)
+	}
+
+	path := "/v1/accounts/TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY/transactions"
+	var page struct {
+		Data []struct {
+			TxId string `json:"txID"`
+		} `json:"data"`
+	}
+	if err = c.Get(ctx, path, url.Values{"only_confirmed": {"true"}}, &page); err != nil || len(page.Data) != 1 || page.Data[0].TxId != "abc" {
+		t.Errorf("page %+v, %v", page, err)
+	}
+	var statusErr *StatusError
+	if err = c.Get(ctx, path, nil, &page); !errors.As(err, &restErr) || restErr.Message != "bad request" || !errors.As(err, &statusErr) {
+		t.Errorf("err %v", err)
+	}
+}
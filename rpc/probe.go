@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// TronMainnetGenesis is the genesis block id of Tron mainnet
+	TronMainnetGenesis = "00000000000000001ebf88508a03865c71d452e25f4d51194196a1d22b6653dc"
+	// EthereumMainnetChainId is eth_chainId of Ethereum mainnet
+	EthereumMainnetChainId = "0x1"
+)
+
+// Probe requests the endpoint and returns identity of the chain it serves
+type Probe func(ctx context.Context, do DoFunc) (string, error)
+
+// TronGenesisProbe returns genesis block id of java-tron HTTP API
+func TronGenesisProbe(ctx context.Context, do DoFunc) (string, error) {
+	response, err := do(ctx, &Request{Path: "/wallet/getblockbynum", Body: []byte(`{"num":0}`), Idempotent: true})
+	if err != nil {
+		return "", err
+	}
+	block := struct {
+		BlockId string `json:"blockID"`
+	}{}
+	if err = json.Unmarshal(response.Body, &block); err != nil || block.BlockId == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidIdentity, response.Body)
+	}
+	return block.BlockId, nil
+}
+
+// EthereumChainIdProbe returns eth_chainId of JSON-RPC endpoint
+func EthereumChainIdProbe(ctx context.Context, do DoFunc) (string, error) {
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+	response, err := do(ctx, &Request{Body: body, Idempotent: true})
+	if err != nil {
+		return "", err
+	}
+	result := struct {
+		Result string `json:"result"`
+	}{}
+	if err = json.Unmarshal(response.Body, &result); err != nil || result.Result == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidIdentity, response.Body)
+	}
+	return result.Result, nil
+}
+
+// connectivityProbe only checks that the endpoint responds, any status below 500 will do
+func connectivityProbe(ctx context.Context, do DoFunc) (string, error) {
+	_, err := do(ctx, &Request{Method: http.MethodGet, Idempotent: true})
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && !statusErr.Temporary() {
+		return "", nil
+	}
+	return "", err
+}
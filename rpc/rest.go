@@ -0,0 +1,101 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// nonIdempotentPaths broadcast transactions, they are not retried after failures
+var nonIdempotentPaths = map[string]bool{
+	"/wallet/broadcasttransaction": true,
+	"/wallet/broadcasthex":         true,
+}
+
+// RestError is error reported by java-tron HTTP API or TronGrid in the response body
+type RestError struct {
+	StatusCode int
+	Message    string
+
+	err error
+}
+
+func (e *RestError) Error() string {
+	return fmt.Sprintf("rpc error: %s", e.Message)
+}
+
+// Unwrap returns *StatusError of responses with HTTP error status
+func (e *RestError) Unwrap() error {
+	return e.err
+}
+
+// Post sends body as JSON to java-tron HTTP API path like "/wallet/getaccount" and decodes JSON
+// response into result, nil result drops it. Errors in response body are returned as *RestError.
+func (c *Client) Post(ctx context.Context, path string, body interface{}, result interface{}) error {
+	data := []byte("{}")
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	return c.rest(ctx, &Request{Path: path, Body: data, Idempotent: !nonIdempotentPaths[path]}, result)
+}
+
+// Get requests TronGrid API path like "/v1/accounts/<address>/transactions" with query parameters
+// and decodes JSON response into result
+func (c *Client) Get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return c.rest(ctx, &Request{Method: http.MethodGet, Path: path, Idempotent: true}, result)
+}
+
+func (c *Client) rest(ctx context.Context, request *Request, result interface{}) error {
+	response, err := c.Do(ctx, request)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if restErr := decodeRestError(statusErr.StatusCode, statusErr.Body); restErr != nil {
+			restErr.err = statusErr
+			return restErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	// java-tron answers errors with 200 status
+	if restErr := decodeRestError(response.StatusCode, response.Body); restErr != nil {
+		return restErr
+	}
+	if result == nil {
+		return nil
+	}
+	if err = json.Unmarshal(response.Body, result); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidResponse, request.Path, err)
+	}
+	return nil
+}
+
+// decodeRestError decodes java-tron {"Error": "..."} and TronGrid {"success": false, "error": "..."}
+// bodies, nil when the body is not an error
+func decodeRestError(statusCode int, body []byte) *RestError {
+	var response struct {
+		JavaTronError string `json:"Error"`
+		Success       *bool  `json:"success"`
+		Error         string `json:"error"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return nil
+	}
+	switch {
+	case response.JavaTronError != "":
+		return &RestError{StatusCode: statusCode, Message: response.JavaTronError}
+	case response.Success != nil && !*response.Success && response.Error != "":
+		return &RestError{StatusCode: statusCode, Message: response.Error}
+	}
+	return nil
+}
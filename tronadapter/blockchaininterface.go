@@ -1,6 +1,7 @@
 package tronadapter
 
 import (
+	"fmt"
 	"github.com/mcmx73/easytron/keys"
 	"github.com/mcmx73/easytron/wallet"
 )
@@ -24,3 +25,15 @@ func (c *Client) GetAddressTransactions(address string) (transactions []*wallet.
 	//TODO implement me
 	panic("implement me")
 }
+
+func (c *Client) PrepareTransfer(request *wallet.TransferRequest) (prepared *wallet.PreparedTransfer, err error) {
+	return nil, fmt.Errorf("%w: tron transfers", wallet.ErrNotImplemented)
+}
+
+func (c *Client) SignTransfer(prepared *wallet.PreparedTransfer, key *keys.Key) (signed *wallet.SignedTransfer, err error) {
+	return nil, fmt.Errorf("%w: tron transfers", wallet.ErrNotImplemented)
+}
+
+func (c *Client) BroadcastTransfer(signed *wallet.SignedTransfer) (hash string, err error) {
+	return "", fmt.Errorf("%w: tron transfers", wallet.ErrNotImplemented)
+}
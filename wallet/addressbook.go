@@ -0,0 +1,347 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Chain of contact address, tokens share addresses with the native coin of their chain
+type Chain string
+
+const (
+	ChainTron     Chain = "tron"
+	ChainEthereum Chain = "ethereum"
+)
+
+// Addresses resembling each other in lookAlikePrefix first and lookAlikeSuffix last characters
+// after "T" or "0x" are confused by users, poisoning attacks generate vanity addresses matching them
+const (
+	lookAlikePrefix = 3
+	lookAlikeSuffix = 4
+)
+
+type ContactAddress struct {
+	Chain   Chain  `json:"chain"`
+	Address string `json:"address"`
+}
+
+// Contact is a named entry of the address book
+type Contact struct {
+	Id        string           `json:"id"`
+	Name      string           `json:"name"`
+	Addresses []ContactAddress `json:"addresses"`
+	Notes     string           `json:"notes,omitempty"`
+	Tags      []string         `json:"tags,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type RecipientWarningType string
+
+const (
+	RecipientWarningNew       RecipientWarningType = "new_recipient"
+	RecipientWarningLookAlike RecipientWarningType = "look_alike"
+	RecipientWarningContract  RecipientWarningType = "contract"
+	RecipientWarningPoisoning RecipientWarningType = "poisoning"
+)
+
+// RecipientWarning is shown before sending to the address. Look-alike warnings name the resembled
+// address and its contact when it is in the address book.
+type RecipientWarning struct {
+	Type       RecipientWarningType `json:"type"`
+	Message    string               `json:"message"`
+	Resembles  string               `json:"resembles,omitempty"`
+	Contact    *Contact             `json:"contact,omitempty"`
+	ContractOf string               `json:"contract_of,omitempty"`
+	// Transaction is the suspected address poisoning transaction the address comes from
+	Transaction *Transaction `json:"transaction,omitempty"`
+}
+
+// ContractDetector is implemented by Blockchain clients able to tell contract addresses
+type ContractDetector interface {
+	IsContract(address string) (bool, error)
+}
+
+// knownContracts are popular token contracts users paste instead of the recipient address
+var knownContracts = map[string]string{
+	"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t":         "USDT TRC20",
+	"TEkxiTehnzSmSe2XqrBj4w32RUN966rdz8":         "USDC TRC20",
+	"0xdac17f958d2ee523a2206206994597c13d831ec7": "USDT ERC20",
+	"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48": "USDC ERC20",
+}
+
+// AddressChain returns chain of 0x hex Ethereum or base58 Tron address
+func AddressChain(address string) Chain {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return ChainEthereum
+	}
+	return ChainTron
+}
+
+// ValidateChainAddress checks Tron base58check address with 0x41 version or Ethereum address
+// with EIP-55 checksum
+func ValidateChainAddress(chain Chain, address string) error {
+	switch chain {
+	case ChainTron:
+		return keys.ValidateTronAddress(address)
+	case ChainEthereum:
+		return keys.ValidateEthereumAddress(address)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownChain, chain)
+}
+
+// SaveContact validates and stores new contact or updates the one with the same Id. The name is
+// required, empty chain of an address is detected from its format.
+func (m *Manager) SaveContact(contact *Contact) (*Contact, error) {
+	saved := *contact
+	saved.Name = strings.TrimSpace(saved.Name)
+	if saved.Name == "" {
+		return nil, ErrContactName
+	}
+	saved.Addresses = make([]ContactAddress, 0, len(contact.Addresses))
+	for _, address := range contact.Addresses {
+		address.Address = strings.TrimSpace(address.Address)
+		if address.Chain == "" {
+			address.Chain = AddressChain(address.Address)
+		}
+		if err := ValidateChainAddress(address.Chain, address.Address); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, address.Address)
+		}
+		saved.Addresses = append(saved.Addresses, address)
+	}
+	saved.Tags = append([]string(nil), contact.Tags...)
+	now := time.Now().UTC()
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if saved.Id == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
+		saved.Id = hex.EncodeToString(id)
+		saved.CreatedAt = now
+	} else if existing, found := m.contacts[saved.Id]; found {
+		saved.CreatedAt = existing.CreatedAt
+	} else {
+		return nil, fmt.Errorf("%w: %s", ErrContactNotFound, saved.Id)
+	}
+	saved.UpdatedAt = now
+	if err := m.storage.SaveContact(&saved); err != nil {
+		return nil, err
+	}
+	m.contacts[saved.Id] = &saved
+	result := saved
+	return &result, nil
+}
+
+func (m *Manager) GetContact(id string) (*Contact, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	contact, found := m.contacts[id]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrContactNotFound, id)
+	}
+	result := *contact
+	return &result, nil
+}
+
+func (m *Manager) DeleteContact(id string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, found := m.contacts[id]; !found {
+		return fmt.Errorf("%w: %s", ErrContactNotFound, id)
+	}
+	if err := m.storage.DeleteContact(id); err != nil {
+		return err
+	}
+	delete(m.contacts, id)
+	return nil
+}
+
+// Contacts returns the address book sorted by name, with tag only contacts having the tag
+func (m *Manager) Contacts(tag string) []*Contact {
+	m.mux.RLock()
+	contacts := make([]*Contact, 0, len(m.contacts))
+	for _, contact := range m.contacts {
+		if tag == "" || hasTag(contact, tag) {
+			result := *contact
+			contacts = append(contacts, &result)
+		}
+	}
+	m.mux.RUnlock()
+	sort.Slice(contacts, func(i, j int) bool {
+		if contacts[i].Name != contacts[j].Name {
+			return contacts[i].Name < contacts[j].Name
+		}
+		return contacts[i].Id < contacts[j].Id
+	})
+	return contacts
+}
+
+// FindContact returns contact having the address
+func (m *Manager) FindContact(address string) (*Contact, bool) {
+	address = normalizeAddress(address)
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	for _, contact := range m.contacts {
+		for _, contactAddress := range contact.Addresses {
+			if normalizeAddress(contactAddress.Address) == address {
+				result := *contact
+				return &result, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// CheckRecipient warns when the address is not in the address book and was never sent to, resembles
+// a contact or an own address without being it, comes from suspected address poisoning transaction
+// or is a contract
+func (m *Manager) CheckRecipient(coinId CoinId, address string) ([]*RecipientWarning, error) {
+	chain := AddressChain(address)
+	if err := ValidateChainAddress(chain, address); err != nil {
+		return nil, err
+	}
+	var warnings []*RecipientWarning
+	_, isContact := m.FindContact(address)
+	own, err := m.TrackedAddresses(coinId)
+	if err != nil {
+		return nil, err
+	}
+	known, err := m.sentTo(coinId, own, address)
+	if err != nil {
+		return nil, err
+	}
+	if !isContact && !known && !containsAddress(own, address) {
+		warnings = append(warnings, &RecipientWarning{
+			Type:    RecipientWarningNew,
+			Message: "you have never sent to this address and it is not in the address book",
+		})
+	}
+	for _, contact := range m.Contacts("") {
+		for _, contactAddress := range contact.Addresses {
+			if lookAlike(contactAddress.Address, address) {
+				warnings = append(warnings, &RecipientWarning{
+					Type:      RecipientWarningLookAlike,
+					Message:   fmt.Sprintf("address resembles %s of contact %s but differs", contactAddress.Address, contact.Name),
+					Resembles: contactAddress.Address,
+					Contact:   contact,
+				})
+			}
+		}
+	}
+	for _, ownAddress := range own {
+		if lookAlike(ownAddress, address) {
+			warnings = append(warnings, &RecipientWarning{
+				Type:      RecipientWarningLookAlike,
+				Message:   fmt.Sprintf("address resembles your address %s but differs", ownAddress),
+				Resembles: ownAddress,
+			})
+		}
+	}
+	if !isContact {
+		warning, err := m.poisoningWarning(coinId, own, address)
+		if err != nil {
+			return nil, err
+		}
+		if warning != nil {
+			warnings = append(warnings, warning)
+		}
+	}
+	contractOf, err := m.contractOf(coinId, address)
+	if err != nil {
+		return nil, err
+	}
+	if contractOf != "" {
+		warnings = append(warnings, &RecipientWarning{
+			Type:       RecipientWarningContract,
+			Message:    "address is a contract, funds sent to it may be lost",
+			ContractOf: contractOf,
+		})
+	}
+	return warnings, nil
+}
+
+// sentTo reports whether cached history of own addresses has outgoing transaction to the address,
+// transferFrom spam looks like outgoing transaction and is skipped
+func (m *Manager) sentTo(coinId CoinId, own []string, address string) (bool, error) {
+	address = normalizeAddress(address)
+	for _, ownAddress := range own {
+		transactions, err := m.storage.Transactions(coinId, ownAddress)
+		if err != nil {
+			return false, err
+		}
+		for _, transaction := range transactions {
+			if transaction.Outgoing && transaction.Poisoning == "" && normalizeAddress(transaction.To) == address {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// contractOf returns name of known token contract, "contract" for other contracts reported by
+// the chain client and empty string for regular addresses
+func (m *Manager) contractOf(coinId CoinId, address string) (string, error) {
+	if name, found := knownContracts[normalizeAddress(address)]; found {
+		return name, nil
+	}
+	client, err := m.client(coinId)
+	if err != nil {
+		return "", err
+	}
+	detector, ok := client.(ContractDetector)
+	if !ok {
+		return "", nil
+	}
+	isContract, err := detector.IsContract(address)
+	if err != nil || !isContract {
+		return "", err
+	}
+	return "contract", nil
+}
+
+// lookAlike reports different addresses of the same chain sharing first and last characters
+func lookAlike(a, b string) bool {
+	a, b = normalizeAddress(a), normalizeAddress(b)
+	if a == b || AddressChain(a) != AddressChain(b) || len(a) != len(b) {
+		return false
+	}
+	a, b = addressBody(a), addressBody(b)
+	if len(a) < lookAlikePrefix+lookAlikeSuffix {
+		return false
+	}
+	return a[:lookAlikePrefix] == b[:lookAlikePrefix] && a[len(a)-lookAlikeSuffix:] == b[len(b)-lookAlikeSuffix:]
+}
+
+// addressBody strips "0x" of Ethereum and leading "T" of Tron addresses, they are the same for all
+func addressBody(address string) string {
+	if AddressChain(address) == ChainEthereum {
+		return address[2:]
+	}
+	return strings.TrimPrefix(address, "T")
+}
+
+func containsAddress(addresses []string, address string) bool {
+	address = normalizeAddress(address)
+	for _, a := range addresses {
+		if normalizeAddress(a) == address {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(contact *Contact, tag string) bool {
+	for _, t := range contact.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
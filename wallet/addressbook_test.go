@@ -0,0 +1,99 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/mcmx73/easytron/keys"
+	"testing"
+)
+
+const (
+	contactAddress   = "0x52908400098527886e0f7030069857d2e4169ee7"
+	lookAlikeAddress = "0x529aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa9ee7"
+)
+
+func TestSaveContact(t *testing.T) {
+	storage := NewMemoryStorage()
+	m := NewManager(WithStorage(storage))
+	if _, err := m.SaveContact(&Contact{Name: " "}); !errors.Is(err, ErrContactName) {
+		t.Errorf("err %v, want %v", err, ErrContactName)
+	}
+	// EIP-55 test vector with broken checksum
+	broken := &Contact{Name: "Bob", Addresses: []ContactAddress{{Address: "0x52908400098527886E0F7030069857D2E4169eE7"}}}
+	if _, err := m.SaveContact(broken); !errors.Is(err, keys.ErrAddressChecksum) {
+		t.Errorf("err %v, want %v", err, keys.ErrAddressChecksum)
+	}
+	tron := &Contact{Name: "Bob", Addresses: []ContactAddress{{Chain: ChainEthereum, Address: syncTestAddress}}}
+	if _, err := m.SaveContact(tron); !errors.Is(err, keys.ErrInvalidAddress) {
+		t.Errorf("err %v, want %v", err, keys.ErrInvalidAddress)
+	}
+	contact, err := m.SaveContact(&Contact{
+		Name:      "Alice",
+		Addresses: []ContactAddress{{Address: syncTestAddress}, {Address: "0x52908400098527886E0F7030069857D2E4169EE7"}},
+		Tags:      []string{"Friends"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Id == "" || contact.Addresses[0].Chain != ChainTron || contact.Addresses[1].Chain != ChainEthereum {
+		t.Errorf("contact %+v", contact)
+	}
+	contact.Notes = "met at conference"
+	if _, err = m.SaveContact(contact); err != nil {
+		t.Fatal(err)
+	}
+	restarted := NewManager(WithStorage(storage))
+	if err = restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if found, ok := restarted.FindContact(contactAddress); !ok || found.Notes != "met at conference" {
+		t.Errorf("contact %+v", found)
+	}
+	if contacts := restarted.Contacts("friends"); len(contacts) != 1 {
+		t.Errorf("contacts %v", contacts)
+	}
+	if err = restarted.DeleteContact(contact.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = restarted.GetContact(contact.Id); !errors.Is(err, ErrContactNotFound) {
+		t.Errorf("err %v, want %v", err, ErrContactNotFound)
+	}
+}
+
+func TestCheckRecipient(t *testing.T) {
+	m := NewManager()
+	m.AddCoin(&discoveryTestClient{})
+	if _, err := m.SaveContact(&Contact{Name: "Alice", Addresses: []ContactAddress{{Address: contactAddress}}}); err != nil {
+		t.Fatal(err)
+	}
+	warnings, err := m.CheckRecipient("eth", contactAddress)
+	if err != nil || len(warnings) != 0 {
+		t.Errorf("warnings %v, %v", warnings, err)
+	}
+	warnings, err = m.CheckRecipient("eth", lookAlikeAddress)
+	if err != nil || len(warnings) != 2 || warnings[0].Type != RecipientWarningNew ||
+		warnings[1].Type != RecipientWarningLookAlike || warnings[1].Contact.Name != "Alice" {
+		t.Fatalf("warnings %+v, %v", warnings, err)
+	}
+	warnings, err = m.CheckRecipient("eth", "0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	if err != nil || len(warnings) != 2 || warnings[1].Type != RecipientWarningContract || warnings[1].ContractOf != "USDT ERC20" {
+		t.Errorf("warnings %+v, %v", warnings, err)
+	}
+}
+
+func TestLookAlike(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY", "TPL6xxxxxxxxxxxxxxxxxxxxxxxxxxhUZY", true},
+		{"TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY", "TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY", false},
+		{"TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY", "TPLxxxxxxxxxxxxxxxxxxxxxxxxxxxhUZY", false},
+		{"TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY", "TPL6xxxxxxxxxxxxxxxxxxxxxxxxxxxUZY", false},
+		{contactAddress, "0x529AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA9EE7", true},
+	}
+	for _, test := range tests {
+		if got := lookAlike(test.a, test.b); got != test.want {
+			t.Errorf("lookAlike(%s, %s) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
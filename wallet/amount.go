@@ -1,5 +1,212 @@
 package wallet
 
-type Amount uint64
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
 
-// math functions for Amount
+// Amount is a non-negative number of coin base units, e.g. sun or wei, with the number of decimals
+// of the coin. Amounts are immutable, arithmetic returns new values. Zero value is 0 with 0 decimals.
+type Amount struct {
+	units    *big.Int
+	decimals int
+}
+
+// NewAmount returns amount of units base units with decimals of CoinDescription.Decimals
+func NewAmount(units *big.Int, decimals int) (Amount, error) {
+	if units == nil || units.Sign() < 0 {
+		return Amount{}, fmt.Errorf("%w: %v", ErrNegativeAmount, units)
+	}
+	if decimals < 0 {
+		return Amount{}, fmt.Errorf("%w: %d decimals", ErrInvalidAmount, decimals)
+	}
+	return Amount{units: new(big.Int).Set(units), decimals: decimals}, nil
+}
+
+// NewAmountFromUint64 returns amount of units base units
+func NewAmountFromUint64(units uint64, decimals int) Amount {
+	return Amount{units: new(big.Int).SetUint64(units), decimals: decimals}
+}
+
+// ParseAmount parses decimal string like "12.5" exactly. Signs, exponents, digit grouping and
+// more fractional digits than decimals are rejected, the decimal separator is always ".".
+func ParseAmount(s string, decimals int) (Amount, error) {
+	if decimals < 0 {
+		return Amount{}, fmt.Errorf("%w: %d decimals", ErrInvalidAmount, decimals)
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	if integer == "" && fraction == "" || !isDigits(integer) || !isDigits(fraction) {
+		return Amount{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > decimals {
+		return Amount{}, fmt.Errorf("%w: %q has more than %d decimals", ErrAmountPrecision, s, decimals)
+	}
+	units, _ := new(big.Int).SetString(integer+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	return Amount{units: units, decimals: decimals}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Units returns copy of the number of base units
+func (a Amount) Units() *big.Int {
+	return new(big.Int).Set(a.value())
+}
+
+func (a Amount) Decimals() int {
+	return a.decimals
+}
+
+func (a Amount) value() *big.Int {
+	if a.units == nil {
+		return new(big.Int)
+	}
+	return a.units
+}
+
+// Rescale converts amount to other number of decimals, dropping nonzero digits is an error
+func (a Amount) Rescale(decimals int) (Amount, error) {
+	if decimals < 0 {
+		return Amount{}, fmt.Errorf("%w: %d decimals", ErrInvalidAmount, decimals)
+	}
+	units := new(big.Int).Set(a.value())
+	if decimals >= a.decimals {
+		units.Mul(units, pow10(decimals-a.decimals))
+		return Amount{units: units, decimals: decimals}, nil
+	}
+	units, remainder := units.QuoRem(units, pow10(a.decimals-decimals), new(big.Int))
+	if remainder.Sign() != 0 {
+		return Amount{}, fmt.Errorf("%w: %s has more than %d decimals", ErrAmountPrecision, a, decimals)
+	}
+	return Amount{units: units, decimals: decimals}, nil
+}
+
+// Add returns a + b, amounts must have the same decimals
+func (a Amount) Add(b Amount) (Amount, error) {
+	if err := a.checkDecimals(b); err != nil {
+		return Amount{}, err
+	}
+	return Amount{units: new(big.Int).Add(a.value(), b.value()), decimals: a.decimals}, nil
+}
+
+// Sub returns a - b, the result can't be negative
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if err := a.checkDecimals(b); err != nil {
+		return Amount{}, err
+	}
+	units := new(big.Int).Sub(a.value(), b.value())
+	if units.Sign() < 0 {
+		return Amount{}, fmt.Errorf("%w: %s - %s", ErrNegativeAmount, a, b)
+	}
+	return Amount{units: units, decimals: a.decimals}, nil
+}
+
+// Mul returns amount multiplied by non-negative factor, e.g. fee per unit by units used
+func (a Amount) Mul(factor uint64) Amount {
+	return Amount{units: new(big.Int).Mul(a.value(), new(big.Int).SetUint64(factor)), decimals: a.decimals}
+}
+
+// Div returns amount divided by divisor rounded down and the remaining base units
+func (a Amount) Div(divisor uint64) (quotient Amount, remainder Amount, err error) {
+	if divisor == 0 {
+		return Amount{}, Amount{}, ErrDivisionByZero
+	}
+	q, r := new(big.Int).QuoRem(a.value(), new(big.Int).SetUint64(divisor), new(big.Int))
+	return Amount{units: q, decimals: a.decimals}, Amount{units: r, decimals: a.decimals}, nil
+}
+
+func (a Amount) checkDecimals(b Amount) error {
+	if a.decimals != b.decimals {
+		return fmt.Errorf("%w: %d and %d", ErrDecimalsMismatch, a.decimals, b.decimals)
+	}
+	return nil
+}
+
+// Cmp compares amounts by value, amounts with different decimals are compared scaled
+func (a Amount) Cmp(b Amount) int {
+	x, y := a.value(), b.value()
+	switch {
+	case a.decimals < b.decimals:
+		x = new(big.Int).Mul(x, pow10(b.decimals-a.decimals))
+	case a.decimals > b.decimals:
+		y = new(big.Int).Mul(y, pow10(a.decimals-b.decimals))
+	}
+	return x.Cmp(y)
+}
+
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+func (a Amount) LessThan(b Amount) bool {
+	return a.Cmp(b) < 0
+}
+
+func (a Amount) GreaterThan(b Amount) bool {
+	return a.Cmp(b) > 0
+}
+
+func (a Amount) IsZero() bool {
+	return a.value().Sign() == 0
+}
+
+// String formats amount as decimal string without trailing fractional zeros, e.g. "12.5"
+func (a Amount) String() string {
+	s := a.fixedString()
+	if a.decimals == 0 {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// fixedString formats amount with all decimals, e.g. "12.500000" for 6 decimals
+func (a Amount) fixedString() string {
+	digits := a.value().String()
+	if a.decimals == 0 {
+		return digits
+	}
+	if len(digits) <= a.decimals {
+		digits = strings.Repeat("0", a.decimals-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-a.decimals] + "." + digits[len(digits)-a.decimals:]
+}
+
+// MarshalJSON encodes amount as decimal string with all decimals, so they survive the round trip
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.fixedString())
+}
+
+// UnmarshalJSON decodes decimal string, decimals are the number of fractional digits. Amounts
+// written by MarshalJSON keep their decimals, amounts of users like "1.5" need Rescale to the coin.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+	}
+	decimals := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		decimals = len(s) - i - 1
+	}
+	amount, err := ParseAmount(s, decimals)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimals int
+		units    string
+		output   string
+		err      error
+	}{
+		{"12.5", 6, "12500000", "12.5", nil},
+		{"0.000001", 6, "1", "0.000001", nil},
+		{".5", 1, "5", "0.5", nil},
+		{"7.", 0, "7", "7", nil},
+		{"1.230", 2, "123", "1.23", nil},
+		{"123456789.123456789012345678", 18, "123456789123456789012345678", "123456789.123456789012345678", nil},
+		{"0.0000001", 6, "", "", ErrAmountPrecision},
+		{"-1", 6, "", "", ErrInvalidAmount},
+		{"1,5", 6, "", "", ErrInvalidAmount},
+		{"1e3", 6, "", "", ErrInvalidAmount},
+		{".", 6, "", "", ErrInvalidAmount},
+		{"", 6, "", "", ErrInvalidAmount},
+	}
+	for _, test := range tests {
+		amount, err := ParseAmount(test.input, test.decimals)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: err %v, want %v", test.input, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if amount.Units().String() != test.units || amount.String() != test.output {
+			t.Errorf("%q: got %s units %s, want %s units %s", test.input, amount, amount.Units(), test.output, test.units)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a, _ := ParseAmount("1.5", 18)
+	b, _ := ParseAmount("0.25", 18)
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "1.75" {
+		t.Errorf("sum %s, %v", sum, err)
+	}
+	difference, err := a.Sub(b)
+	if err != nil || difference.String() != "1.25" {
+		t.Errorf("difference %s, %v", difference, err)
+	}
+	if _, err = b.Sub(a); !errors.Is(err, ErrNegativeAmount) {
+		t.Errorf("err %v, want %v", err, ErrNegativeAmount)
+	}
+	if _, err = a.Add(NewAmountFromUint64(1, 6)); !errors.Is(err, ErrDecimalsMismatch) {
+		t.Errorf("err %v, want %v", err, ErrDecimalsMismatch)
+	}
+	if product := b.Mul(3); product.String() != "0.75" {
+		t.Errorf("product %s", product)
+	}
+	quotient, remainder, err := NewAmountFromUint64(10, 0).Div(3)
+	if err != nil || quotient.String() != "3" || remainder.String() != "1" {
+		t.Errorf("quotient %s remainder %s, %v", quotient, remainder, err)
+	}
+	if _, _, err = a.Div(0); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("err %v, want %v", err, ErrDivisionByZero)
+	}
+	if !a.GreaterThan(b) || !b.LessThan(a) || a.Equal(b) {
+		t.Error("wrong comparison")
+	}
+	if !NewAmountFromUint64(1500000, 6).Equal(a) {
+		t.Error("amounts with different decimals are not equal")
+	}
+	if !(Amount{}).IsZero() || (Amount{}).String() != "0" {
+		t.Error("zero value is not zero")
+	}
+}
+
+func TestAmountRescale(t *testing.T) {
+	a := NewAmountFromUint64(1500000, 6)
+	scaled, err := a.Rescale(18)
+	if err != nil || scaled.Units().Cmp(new(big.Int).Mul(big.NewInt(15), pow10(17))) != 0 {
+		t.Errorf("scaled %s, %v", scaled.Units(), err)
+	}
+	if _, err = a.Rescale(0); !errors.Is(err, ErrAmountPrecision) {
+		t.Errorf("err %v, want %v", err, ErrAmountPrecision)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	balances := map[string]Amount{"usdt": NewAmountFromUint64(12500000, 6)}
+	data, err := json.Marshal(balances)
+	if err != nil || string(data) != `{"usdt":"12.500000"}` {
+		t.Fatalf("json %s, %v", data, err)
+	}
+	var decoded map[string]Amount
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded["usdt"].Equal(balances["usdt"]) || decoded["usdt"].Decimals() != 6 {
+		t.Errorf("decoded %s with %d decimals", decoded["usdt"], decoded["usdt"].Decimals())
+	}
+	if data, err = json.Marshal(NewAmountFromUint64(5, 18)); err != nil || string(data) != `"0.000000000000000005"` {
+		t.Errorf("json %s, %v", data, err)
+	}
+	if data, err = json.Marshal(Amount{}); err != nil || string(data) != `"0"` {
+		t.Errorf("json %s, %v", data, err)
+	}
+	if err = json.Unmarshal([]byte(`{"usdt":12.5}`), &decoded); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("err %v, want %v", err, ErrInvalidAmount)
+	}
+}
@@ -7,4 +7,11 @@ type Blockchain interface {
 	CreateNewAddress(privateKey *keys.Key) (address string, err error)
 	GetAddressBalance(address string) (amounts map[string]Amount, err error)
 	GetAddressTransactions(address string) (transactions []*Transaction, err error)
+	// PrepareTransfer builds unsigned transaction and estimates its fee
+	PrepareTransfer(request *TransferRequest) (prepared *PreparedTransfer, err error)
+	// SignTransfer signs the transaction, the signed transfer carries its hash
+	SignTransfer(prepared *PreparedTransfer, key *keys.Key) (signed *SignedTransfer, err error)
+	// BroadcastTransfer submits signed transaction and returns its hash, errors matching
+	// ErrTransferRejected mean the transaction surely did not reach the network
+	BroadcastTransfer(signed *SignedTransfer) (hash string, err error)
 }
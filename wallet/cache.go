@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"sort"
+	"time"
+)
+
+// CachedTransactions returns stored history of the address without network requests, amounts
+// are restored with decimals of the coin. Poisoning is checked again, contacts may have changed.
+// Pending transfers the chain did not return within the pending TTL are left out.
+func (m *Manager) CachedTransactions(coinId CoinId, address string) ([]*Transaction, error) {
+	cached, err := m.storage.Transactions(coinId, normalizeAddress(address))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	transactions := cached[:0]
+	for _, transaction := range cached {
+		if !m.pendingExpired(transaction, now) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	m.mux.RLock()
+	coin, found := m.coinsById[coinId]
+	m.mux.RUnlock()
+	if !found {
+		return transactions, nil
+	}
+	for _, transaction := range transactions {
+		if transaction.Amount, err = transaction.Amount.Rescale(coin.Decimals); err != nil {
+			return nil, err
+		}
+		if transaction.Fee, err = transaction.Fee.Rescale(coin.Decimals); err != nil {
+			return nil, err
+		}
+	}
+	if err = m.detectPoisoning(coinId, address, transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// CachedBalances returns balances of the address stored by the last refresh without network
+// requests, nil when it was never refreshed
+func (m *Manager) CachedBalances(coinId CoinId, address string) (map[string]Amount, error) {
+	return m.storage.Balances(coinId, normalizeAddress(address))
+}
+
+// DerivedAddresses returns addresses of the coin found by discovery, ordered by account and index
+func (m *Manager) DerivedAddresses(coinId CoinId) ([]*DiscoveredAddress, error) {
+	return m.storage.Addresses(coinId)
+}
+
+// SetLabel names any address, own or foreign, empty label removes it
+func (m *Manager) SetLabel(address, label string) error {
+	address = normalizeAddress(address)
+	if err := m.storage.SetLabel(address, label); err != nil {
+		return err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if label == "" {
+		delete(m.labels, address)
+	} else {
+		m.labels[address] = label
+	}
+	return nil
+}
+
+func (m *Manager) Label(address string) string {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.labels[normalizeAddress(address)]
+}
+
+// TrackedAddresses returns normalized watch-only and discovered addresses of the coin
+func (m *Manager) TrackedAddresses(coinId CoinId) ([]string, error) {
+	derived, err := m.storage.Addresses(coinId)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	m.mux.RLock()
+	for address, account := range m.watchOnly {
+		if account.CoinId == coinId {
+			addresses = append(addresses, address)
+		}
+	}
+	m.mux.RUnlock()
+	sort.Strings(addresses)
+	for _, discovered := range derived {
+		addresses = append(addresses, normalizeAddress(discovered.Address))
+	}
+	return addresses, nil
+}
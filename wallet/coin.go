@@ -1,5 +1,7 @@
 package wallet
 
+import "math/big"
+
 type CoinId string
 type CoinDescription struct {
 	Id       CoinId `json:"id"`
@@ -9,3 +11,13 @@ type CoinDescription struct {
 	Coin     bool   `json:"coin"`
 	Token    bool   `json:"token"`
 }
+
+// NewAmount returns amount of base units with decimals of the coin
+func (c *CoinDescription) NewAmount(units *big.Int) (Amount, error) {
+	return NewAmount(units, c.Decimals)
+}
+
+// ParseAmount parses user entered amount of the coin, e.g. "12.5"
+func (c *CoinDescription) ParseAmount(s string) (Amount, error) {
+	return ParseAmount(s, c.Decimals)
+}
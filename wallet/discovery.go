@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+)
+
+const (
+	DefaultGapLimit = 20
+)
+
+type DiscoveredAddress struct {
+	CoinId  CoinId `json:"coin_id"`
+	Path    string `json:"path"`
+	Account uint32 `json:"account"`
+	Index   uint32 `json:"index"`
+	Address string `json:"address"`
+}
+
+// DiscoverAccounts derives addresses of the coin from the master key and returns those having
+// transactions. Addresses of every account are scanned until gap limit consecutive unused ones are
+// found, accounts are scanned until an account without used addresses. Templates without {index}
+// have a single address per account, so the gap limit applies to accounts. When template is empty
+// the one configured with WithDerivationTemplate is used.
+func (m *Manager) DiscoverAccounts(coinId CoinId, master *keys.ExtendedKey, template keys.PathTemplate) ([]*DiscoveredAddress, error) {
+	m.mux.RLock()
+	client, ok := m.clients[coinId]
+	if template == "" {
+		template = m.derivationTemplates[coinId]
+	}
+	gapLimit := m.gapLimit
+	m.mux.RUnlock()
+	if !ok {
+		return nil, ErrCoinNotFound
+	}
+	if template == "" {
+		return nil, ErrNoDerivationTemplate
+	}
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+	scanner := &addressScanner{
+		manager:  m,
+		client:   client,
+		coinId:   coinId,
+		master:   master,
+		template: template,
+		gapLimit: gapLimit,
+	}
+	if !template.HasIndex() {
+		return scanner.scan(func(i uint32) (uint32, uint32) { return i, 0 })
+	}
+	var discovered []*DiscoveredAddress
+	for account := uint32(0); ; account++ {
+		used, err := scanner.scan(func(i uint32) (uint32, uint32) { return account, i })
+		if err != nil {
+			return nil, err
+		}
+		discovered = append(discovered, used...)
+		if len(used) == 0 || !template.HasAccount() {
+			return discovered, nil
+		}
+	}
+}
+
+type addressScanner struct {
+	manager  *Manager
+	client   Blockchain
+	coinId   CoinId
+	master   *keys.ExtendedKey
+	template keys.PathTemplate
+	gapLimit int
+}
+
+// scan walks positions 0, 1, ... mapped to (account, index) until gap limit unused addresses in a row
+func (s *addressScanner) scan(position func(i uint32) (account, index uint32)) ([]*DiscoveredAddress, error) {
+	var used []*DiscoveredAddress
+	unused := 0
+	for i := uint32(0); unused < s.gapLimit; i++ {
+		discovered, key, err := s.derive(position(i))
+		if err != nil {
+			return nil, err
+		}
+		transactions, err := s.client.GetAddressTransactions(discovered.Address)
+		if err != nil {
+			return nil, err
+		}
+		if len(transactions) == 0 {
+			unused++
+			continue
+		}
+		unused = 0
+		if s.manager.keyManager != nil && key.HasPrivateKey() {
+			if err = s.manager.keyManager.AddKey(key); err != nil {
+				return nil, err
+			}
+		}
+		if err = s.manager.storage.SaveAddress(discovered); err != nil {
+			return nil, err
+		}
+		used = append(used, discovered)
+	}
+	return used, nil
+}
+
+// derive returns the address of the template position and its key, public-only for extended public keys
+func (s *addressScanner) derive(account, index uint32) (*DiscoveredAddress, *keys.Key, error) {
+	path, err := s.template.Path(account, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	extendedKey, err := s.master.DerivePath(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("derive %s: %w", path, err)
+	}
+	key, err := extendedKey.Key()
+	if err != nil {
+		return nil, nil, err
+	}
+	address, err := s.client.CreateNewAddress(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &DiscoveredAddress{
+		CoinId:  s.coinId,
+		Path:    path.String(),
+		Account: account,
+		Index:   index,
+		Address: address,
+	}, key, nil
+}
@@ -0,0 +1,105 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/mcmx73/easytron/keys"
+	"strings"
+	"testing"
+)
+
+type discoveryTestClient struct {
+	derived map[string]bool
+	used    map[string]bool
+}
+
+func (c *discoveryTestClient) GetCoins() []*CoinDescription {
+	return []*CoinDescription{{Id: "eth", Title: "Ethereum", Symbol: "ETH", Decimals: 18, Coin: true}}
+}
+
+func (c *discoveryTestClient) CreateNewAddress(key *keys.Key) (string, error) {
+	address, err := key.EthereumAddress()
+	c.derived[address] = true
+	return address, err
+}
+
+func (c *discoveryTestClient) GetAddressBalance(address string) (map[string]Amount, error) {
+	return nil, nil
+}
+
+func (c *discoveryTestClient) GetAddressTransactions(address string) ([]*Transaction, error) {
+	if c.used[address] {
+		return []*Transaction{{To: address}}, nil
+	}
+	return nil, nil
+}
+
+func (c *discoveryTestClient) PrepareTransfer(request *TransferRequest) (*PreparedTransfer, error) {
+	return nil, errTransferNotSupported
+}
+
+func (c *discoveryTestClient) SignTransfer(prepared *PreparedTransfer, key *keys.Key) (*SignedTransfer, error) {
+	return nil, errTransferNotSupported
+}
+
+func (c *discoveryTestClient) BroadcastTransfer(signed *SignedTransfer) (string, error) {
+	return "", errTransferNotSupported
+}
+
+func TestDiscoverAccounts(t *testing.T) {
+	master, err := keys.NewMasterKeyFromMnemonic(strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressAt := func(account, index uint32) string {
+		path, _ := keys.EthereumBIP44PathTemplate.Path(account, index)
+		extendedKey, _ := master.DerivePath(path)
+		key, _ := extendedKey.Key()
+		address, _ := key.EthereumAddress()
+		return address
+	}
+	client := &discoveryTestClient{
+		derived: make(map[string]bool),
+		used: map[string]bool{
+			addressAt(0, 0): true,
+			addressAt(0, 4): true,
+			addressAt(1, 2): true,
+			// beyond the gap of account 1
+			addressAt(1, 9): true,
+		},
+	}
+	keyManager := keys.NewManager()
+	m := NewManager(
+		WithKeyManager(keyManager),
+		WithGapLimit(5),
+		WithDerivationTemplate("eth", keys.EthereumBIP44PathTemplate),
+	)
+	m.AddCoin(client)
+
+	discovered, err := m.DiscoverAccounts("eth", master, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"m/44'/60'/0'/0/0", "m/44'/60'/0'/0/4", "m/44'/60'/1'/0/2"}
+	if len(discovered) != len(expected) {
+		t.Fatalf("discovered %d addresses, expected %d", len(discovered), len(expected))
+	}
+	for i, address := range discovered {
+		if address.Path != expected[i] {
+			t.Errorf("address %d: path %s, expected %s", i, address.Path, expected[i])
+		}
+		if _, err = m.GetKey(address.Address); err != nil {
+			t.Errorf("key of %s is not added: %v", address.Address, err)
+		}
+	}
+	// account 2 is scanned up to the gap limit and stops discovery
+	if !client.derived[addressAt(2, 4)] || client.derived[addressAt(2, 5)] || client.derived[addressAt(3, 0)] {
+		t.Error("unexpected scan range")
+	}
+	if _, err = m.DiscoverAccounts("trx", master, ""); err != ErrCoinNotFound {
+		t.Errorf("expected ErrCoinNotFound, got %v", err)
+	}
+	// the used address of a constant template would be scanned forever
+	if _, err = m.DiscoverAccounts("eth", master, "m/44'/60'/0'/0/0"); !errors.Is(err, keys.ErrInvalidDerivationPath) {
+		t.Errorf("expected ErrInvalidDerivationPath, got %v", err)
+	}
+}
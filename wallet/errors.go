@@ -0,0 +1,32 @@
+package wallet
+
+import "errors"
+
+var (
+	ErrKeyNotFound          = errors.New("key not found")
+	ErrNoKeyManager         = errors.New("key manager is not configured")
+	ErrCoinNotFound         = errors.New("coin not found")
+	ErrNoDerivationTemplate = errors.New("derivation path template is not configured")
+	ErrWatchOnly            = errors.New("address is watch-only, signing is not possible without its private key")
+	ErrPrivateExtendedKey   = errors.New("watch-only account requires extended public key, not private")
+	ErrAccountInTemplate    = errors.New("extended public key template can not contain {account}")
+	ErrInvalidAmount        = errors.New("invalid amount")
+	ErrNegativeAmount       = errors.New("amount can not be negative")
+	ErrAmountPrecision      = errors.New("amount precision exceeds coin decimals")
+	ErrDecimalsMismatch     = errors.New("amounts have different decimals")
+	ErrDivisionByZero       = errors.New("division by zero")
+	ErrQuoteNotFound        = errors.New("send quote not found")
+	ErrQuoteExpired         = errors.New("send quote expired, prepare the transfer again")
+	ErrUnknownChain         = errors.New("unknown chain")
+	ErrContactName          = errors.New("contact name is required")
+	ErrContactNotFound      = errors.New("contact not found")
+	ErrPoisoningRecipient   = errors.New("recipient comes from suspected address poisoning transaction, confirm it to send")
+	ErrPolicyViolation      = errors.New("transfer violates spending policy")
+	ErrPolicyPassword       = errors.New("invalid wallet password for spending policy")
+	ErrPolicyLocked         = errors.New("spending policy is locked, unlock a key with the wallet password")
+	ErrInvalidPolicy        = errors.New("invalid spending policy")
+	ErrPolicyMissing        = errors.New("spending policy file is missing or replaced, set the policy with the wallet password")
+	ErrNoTransferHash       = errors.New("chain client did not return hash of the signed transfer")
+	ErrTransferRejected     = errors.New("transfer is rejected by the chain")
+	ErrNotImplemented       = errors.New("operation is not supported by the chain adapter yet")
+)
@@ -1,19 +1,39 @@
 package wallet
 
 import (
+	"errors"
+	"fmt"
+	"github.com/mcmx73/easytron/common/secret"
 	"github.com/mcmx73/easytron/keys"
 	"sync"
+	"time"
 )
 
 type WithOption func(*Manager)
 
 func NewManager(options ...WithOption) *Manager {
 	m := &Manager{
-		clients: make(map[CoinId]Blockchain),
+		clients:      make(map[CoinId]Blockchain),
+		coinsById:    make(map[CoinId]*CoinDescription),
+		coinsByTitle: make(map[string]*CoinDescription),
+
+		derivationTemplates: make(map[CoinId]keys.PathTemplate),
+		gapLimit:            DefaultGapLimit,
+		watchOnly:           make(map[string]*Account),
+		labels:              make(map[string]string),
+		contacts:            make(map[string]*Contact),
+		quotes:              make(map[string]*SendQuote),
+		sendLocks:           make(map[CoinId]*sync.Mutex),
+		quoteTTL:            DefaultQuoteTTL,
+		pendingTTL:          DefaultPendingTTL,
+		dustThresholds:      make(map[CoinId]Amount),
 	}
 	for _, opt := range options {
 		opt(m)
 	}
+	if m.storage == nil {
+		m.storage = NewMemoryStorage()
+	}
 	return m
 }
 
@@ -23,6 +43,61 @@ type Manager struct {
 	clients      map[CoinId]Blockchain
 	coinsById    map[CoinId]*CoinDescription
 	coinsByTitle map[string]*CoinDescription
+
+	derivationTemplates map[CoinId]keys.PathTemplate
+	gapLimit            int
+	watchOnly           map[string]*Account
+	labels              map[string]string
+	contacts            map[string]*Contact
+	storage             Storage
+	quotes              map[string]*SendQuote
+	quoteTTL            time.Duration
+	pendingTTL          time.Duration
+	sendLocks           map[CoinId]*sync.Mutex
+	dustThresholds      map[CoinId]Amount
+	policyPath          string
+	policy              *Policy
+	// lockedPolicy is the stored policy until its MAC is checked with the wallet password
+	lockedPolicy *policyFile
+	// policyMissing is set when the storage knows a policy file Load did not find
+	policyMissing bool
+}
+
+// Load reads watch-only accounts, labels and contacts from the storage and the spending policy,
+// cached transactions are read on request, so the wallet is usable before the first network refresh
+func (m *Manager) Load() error {
+	if err := m.loadPolicy(); err != nil {
+		return err
+	}
+	accounts, err := m.storage.Accounts()
+	if err != nil {
+		return err
+	}
+	labels, err := m.storage.Labels()
+	if err != nil {
+		return err
+	}
+	contacts, err := m.storage.Contacts()
+	if err != nil {
+		return err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for _, account := range accounts {
+		if account.WatchOnly {
+			m.watchOnly[normalizeAddress(account.Address)] = account
+		}
+	}
+	m.labels = labels
+	for _, contact := range contacts {
+		m.contacts[contact.Id] = contact
+	}
+	return nil
+}
+
+// Close closes the storage
+func (m *Manager) Close() error {
+	return m.storage.Close()
 }
 
 func (m *Manager) AddCoin(client Blockchain) {
@@ -35,3 +110,60 @@ func (m *Manager) AddCoin(client Blockchain) {
 		m.clients[coin.Id] = client
 	}
 }
+
+// GetKey returns the signing key of the address, ErrWatchOnly for watched addresses without key
+func (m *Manager) GetKey(address string) (*keys.Key, error) {
+	var key *keys.Key
+	found := false
+	if m.keyManager != nil {
+		key, found = m.keyManager.GetKey(address)
+	}
+	if found && key.HasPrivateKey() {
+		return key, nil
+	}
+	if m.IsWatchOnly(address) {
+		return nil, fmt.Errorf("%w: %s", ErrWatchOnly, address)
+	}
+	return nil, ErrKeyNotFound
+}
+
+// ImportKey imports private key in hex, WIF, keystore JSON or mnemonic form, stores it encrypted
+// with newPassword and unlocks it
+func (m *Manager) ImportKey(data, newPassword string, options ...keys.WithImportOption) (*keys.Key, error) {
+	if m.keyManager == nil {
+		return nil, ErrNoKeyManager
+	}
+	return m.keyManager.ImportKey(data, newPassword, options...)
+}
+
+// UnlockKey decrypts stored key of the address with its password, e.g. after restart, and
+// unlocks the spending policy with the same password
+func (m *Manager) UnlockKey(address, password string) (*keys.Key, error) {
+	if m.keyManager == nil {
+		return nil, ErrNoKeyManager
+	}
+	key, err := m.keyManager.Unlock(address, password)
+	if err != nil {
+		return nil, err
+	}
+	// keys of other passwords leave the policy locked
+	if err = m.UnlockPolicy(password); err != nil && !errors.Is(err, ErrPolicyPassword) {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LockKey forgets the decrypted key of the address, the key file is kept
+func (m *Manager) LockKey(address string) {
+	if m.keyManager != nil {
+		m.keyManager.Lock(address)
+	}
+}
+
+// ExportKey exports stored key of the address after checking its password
+func (m *Manager) ExportKey(address, password string, format keys.KeyFormat, exportPassword string) (*secret.Buffer, error) {
+	if m.keyManager == nil {
+		return nil, ErrNoKeyManager
+	}
+	return m.keyManager.ExportKey(address, password, format, exportPassword)
+}
@@ -0,0 +1,224 @@
+package wallet
+
+import "sync"
+
+// NewMemoryStorage returns Storage keeping data until the process exits, used when
+// no persistent storage is configured
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		accounts:     make(map[storageKey]*Account),
+		addresses:    make(map[storageKey]*DiscoveredAddress),
+		labels:       make(map[string]string),
+		contacts:     make(map[string]*Contact),
+		transactions: make(map[storageKey]map[string]*Transaction),
+		cursors:      make(map[storageKey]string),
+		balances:     make(map[storageKey]map[string]Amount),
+	}
+}
+
+type storageKey struct {
+	coinId  CoinId
+	address string
+}
+
+type MemoryStorage struct {
+	mux          sync.RWMutex
+	accounts     map[storageKey]*Account
+	addresses    map[storageKey]*DiscoveredAddress
+	labels       map[string]string
+	contacts     map[string]*Contact
+	transactions map[storageKey]map[string]*Transaction
+	cursors      map[storageKey]string
+	balances     map[storageKey]map[string]Amount
+	policyMac    []byte
+}
+
+func (s *MemoryStorage) SaveAccount(account *Account) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	stored := *account
+	s.accounts[storageKey{account.CoinId, account.Address}] = &stored
+	return nil
+}
+
+func (s *MemoryStorage) DeleteAccount(coinId CoinId, address string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.accounts, storageKey{coinId, address})
+	return nil
+}
+
+func (s *MemoryStorage) Accounts() ([]*Account, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	accounts := make([]*Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		stored := *account
+		accounts = append(accounts, &stored)
+	}
+	return accounts, nil
+}
+
+func (s *MemoryStorage) SaveAddress(address *DiscoveredAddress) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	stored := *address
+	s.addresses[storageKey{address.CoinId, address.Address}] = &stored
+	return nil
+}
+
+// Addresses returns derived addresses of the coin ordered by account and index
+func (s *MemoryStorage) Addresses(coinId CoinId) ([]*DiscoveredAddress, error) {
+	s.mux.RLock()
+	var addresses []*DiscoveredAddress
+	for key, address := range s.addresses {
+		if key.coinId == coinId {
+			stored := *address
+			addresses = append(addresses, &stored)
+		}
+	}
+	s.mux.RUnlock()
+	SortAddresses(addresses)
+	return addresses, nil
+}
+
+func (s *MemoryStorage) SetLabel(address, label string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if label == "" {
+		delete(s.labels, address)
+	} else {
+		s.labels[address] = label
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Labels() (map[string]string, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	labels := make(map[string]string, len(s.labels))
+	for address, label := range s.labels {
+		labels[address] = label
+	}
+	return labels, nil
+}
+
+func (s *MemoryStorage) SaveContact(contact *Contact) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	stored := *contact
+	s.contacts[contact.Id] = &stored
+	return nil
+}
+
+func (s *MemoryStorage) DeleteContact(id string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.contacts, id)
+	return nil
+}
+
+func (s *MemoryStorage) Contacts() ([]*Contact, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	contacts := make([]*Contact, 0, len(s.contacts))
+	for _, contact := range s.contacts {
+		stored := *contact
+		contacts = append(contacts, &stored)
+	}
+	return contacts, nil
+}
+
+func (s *MemoryStorage) SaveTransactions(coinId CoinId, address string, transactions []*Transaction) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := storageKey{coinId, address}
+	cached, ok := s.transactions[key]
+	if !ok {
+		cached = make(map[string]*Transaction)
+		s.transactions[key] = cached
+	}
+	for _, transaction := range transactions {
+		stored := *transaction
+		cached[transaction.Hash] = &stored
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Transactions(coinId CoinId, address string) ([]*Transaction, error) {
+	s.mux.RLock()
+	cached := s.transactions[storageKey{coinId, address}]
+	transactions := make([]*Transaction, 0, len(cached))
+	for _, transaction := range cached {
+		stored := *transaction
+		transactions = append(transactions, &stored)
+	}
+	s.mux.RUnlock()
+	SortTransactions(transactions)
+	return transactions, nil
+}
+
+func (s *MemoryStorage) DeleteTransaction(coinId CoinId, address, hash string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.transactions[storageKey{coinId, address}], hash)
+	return nil
+}
+
+func (s *MemoryStorage) SetSyncCursor(coinId CoinId, address, cursor string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.cursors[storageKey{coinId, address}] = cursor
+	return nil
+}
+
+func (s *MemoryStorage) SyncCursor(coinId CoinId, address string) (string, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.cursors[storageKey{coinId, address}], nil
+}
+
+func (s *MemoryStorage) SetBalances(coinId CoinId, address string, balances map[string]Amount) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.balances[storageKey{coinId, address}] = copyBalances(balances)
+	return nil
+}
+
+func (s *MemoryStorage) Balances(coinId CoinId, address string) (map[string]Amount, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	balances, found := s.balances[storageKey{coinId, address}]
+	if !found {
+		return nil, nil
+	}
+	return copyBalances(balances), nil
+}
+
+func copyBalances(balances map[string]Amount) map[string]Amount {
+	copied := make(map[string]Amount, len(balances))
+	for token, amount := range balances {
+		copied[token] = amount
+	}
+	return copied
+}
+
+func (s *MemoryStorage) SetPolicyMac(mac []byte) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.policyMac = append([]byte(nil), mac...)
+	return nil
+}
+
+func (s *MemoryStorage) PolicyMac() ([]byte, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	if len(s.policyMac) == 0 {
+		return nil, nil
+	}
+	return append([]byte(nil), s.policyMac...), nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
@@ -1,9 +1,65 @@
 package wallet
 
-import "github.com/mcmx73/easytron/keys"
+import (
+	"github.com/mcmx73/easytron/keys"
+	"time"
+)
 
 func WithKeyManager(m *keys.Manager) WithOption {
 	return func(w *Manager) {
 		w.keyManager = m
 	}
 }
+
+// WithDerivationTemplate sets derivation path template used for account discovery of the coin
+func WithDerivationTemplate(coinId CoinId, template keys.PathTemplate) WithOption {
+	return func(w *Manager) {
+		w.derivationTemplates[coinId] = template
+	}
+}
+
+// WithGapLimit sets number of consecutive unused addresses after which discovery stops
+func WithGapLimit(gapLimit int) WithOption {
+	return func(w *Manager) {
+		w.gapLimit = gapLimit
+	}
+}
+
+// WithStorage persists accounts, addresses, labels and transaction cache, see Manager.Load.
+// Without it the data is kept in memory.
+func WithStorage(storage Storage) WithOption {
+	return func(w *Manager) {
+		w.storage = storage
+	}
+}
+
+// WithDustThreshold flags incoming transfers of the coin smaller than threshold from unknown
+// addresses as address poisoning, without it only zero transfers are flagged
+func WithDustThreshold(coinId CoinId, threshold Amount) WithOption {
+	return func(w *Manager) {
+		w.dustThresholds[coinId] = threshold
+	}
+}
+
+// WithPolicyFile sets JSON file of the spending policy, Load reads it and SetPolicy writes it.
+// Without it the policy is kept in memory.
+func WithPolicyFile(path string) WithOption {
+	return func(w *Manager) {
+		w.policyPath = path
+	}
+}
+
+// WithQuoteTTL sets how long prepared transfers wait for ConfirmSend
+func WithQuoteTTL(ttl time.Duration) WithOption {
+	return func(w *Manager) {
+		w.quoteTTL = ttl
+	}
+}
+
+// WithPendingTTL sets how long broadcast transfers count as spent before the chain history
+// returns them, later they are dropped as never mined
+func WithPendingTTL(ttl time.Duration) WithOption {
+	return func(w *Manager) {
+		w.pendingTTL = ttl
+	}
+}
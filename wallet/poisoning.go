@@ -0,0 +1,155 @@
+package wallet
+
+import "sort"
+
+// PoisoningReason tells why the transaction is suspected to be address poisoning. Attackers send
+// zero or dust transfers from vanity addresses resembling real counterparties, or call TRC20
+// transferFrom of zero tokens from the victim, so the look-alike address appears in the history
+// to be copied as the recipient of the next transfer.
+type PoisoningReason string
+
+const (
+	PoisoningTransferFrom PoisoningReason = "transfer_from"
+	PoisoningLookAlike    PoisoningReason = "look_alike"
+	PoisoningZeroValue    PoisoningReason = "zero_value"
+	PoisoningDust         PoisoningReason = "dust"
+)
+
+// WithoutPoisoning returns transactions not flagged as address poisoning, for history views
+// hiding the spam
+func WithoutPoisoning(transactions []*Transaction) []*Transaction {
+	result := make([]*Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.Poisoning == "" {
+			result = append(result, transaction)
+		}
+	}
+	return result
+}
+
+// detectPoisoning sets Poisoning of the address history. Transactions are checked oldest first
+// against own addresses, contacts and counterparties of earlier clean transactions, cached history
+// of the address is taken into account.
+func (m *Manager) detectPoisoning(coinId CoinId, address string, transactions []*Transaction) error {
+	own, err := m.TrackedAddresses(coinId)
+	if err != nil {
+		return err
+	}
+	own = append(own, normalizeAddress(address))
+	cached, err := m.storage.Transactions(coinId, normalizeAddress(address))
+	if err != nil {
+		return err
+	}
+	byHash := make(map[string]*Transaction, len(cached)+len(transactions))
+	for _, transaction := range cached {
+		byHash[transaction.Hash] = transaction
+	}
+	for _, transaction := range transactions {
+		byHash[transaction.Hash] = transaction
+	}
+	history := make([]*Transaction, 0, len(byHash))
+	for _, transaction := range byHash {
+		history = append(history, transaction)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].CreatedAt.Before(history[j].CreatedAt)
+	})
+	trusted := newAddressSet(own...)
+	for _, contact := range m.Contacts("") {
+		for _, contactAddress := range contact.Addresses {
+			trusted.add(contactAddress.Address)
+		}
+	}
+	m.mux.RLock()
+	dust := m.dustThresholds[coinId]
+	m.mux.RUnlock()
+	ownSet := newAddressSet(own...)
+	for _, transaction := range history {
+		transaction.Poisoning = poisoningOf(transaction, ownSet, trusted, dust)
+		if transaction.Poisoning == "" {
+			trusted.add(transaction.Counterparty())
+		}
+	}
+	return nil
+}
+
+func poisoningOf(transaction *Transaction, own, trusted *addressSet, dust Amount) PoisoningReason {
+	if transaction.Outgoing && transaction.Initiator != "" && !own.contains(transaction.Initiator) {
+		return PoisoningTransferFrom
+	}
+	counterparty := transaction.Counterparty()
+	if counterparty == "" || trusted.contains(counterparty) {
+		return ""
+	}
+	for _, address := range trusted.addresses {
+		if lookAlike(address, counterparty) {
+			return PoisoningLookAlike
+		}
+	}
+	if transaction.Amount.IsZero() {
+		return PoisoningZeroValue
+	}
+	if transaction.Incoming && transaction.Amount.LessThan(dust) {
+		return PoisoningDust
+	}
+	return ""
+}
+
+// poisoningWarning warns when the address is counterparty of suspected address poisoning
+// transaction in history of own addresses
+func (m *Manager) poisoningWarning(coinId CoinId, own []string, address string) (*RecipientWarning, error) {
+	poisoning, err := m.poisonedBy(coinId, own, address)
+	if err != nil || poisoning == nil {
+		return nil, err
+	}
+	return &RecipientWarning{
+		Type:        RecipientWarningPoisoning,
+		Message:     "address comes from suspected address poisoning transaction, check it against the real recipient",
+		Transaction: poisoning,
+	}, nil
+}
+
+// poisonedBy returns flagged transaction of own addresses history having the address as
+// counterparty, nil when there is none
+func (m *Manager) poisonedBy(coinId CoinId, own []string, address string) (*Transaction, error) {
+	address = normalizeAddress(address)
+	for _, ownAddress := range own {
+		transactions, err := m.storage.Transactions(coinId, ownAddress)
+		if err != nil {
+			return nil, err
+		}
+		for _, transaction := range transactions {
+			if transaction.Poisoning != "" && normalizeAddress(transaction.Counterparty()) == address {
+				return transaction, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// addressSet keeps normalized addresses in insertion order
+type addressSet struct {
+	addresses []string
+	index     map[string]bool
+}
+
+func newAddressSet(addresses ...string) *addressSet {
+	s := &addressSet{index: make(map[string]bool)}
+	for _, address := range addresses {
+		s.add(address)
+	}
+	return s
+}
+
+func (s *addressSet) add(address string) {
+	address = normalizeAddress(address)
+	if address == "" || s.index[address] {
+		return
+	}
+	s.index[address] = true
+	s.addresses = append(s.addresses, address)
+}
+
+func (s *addressSet) contains(address string) bool {
+	return s.index[normalizeAddress(address)]
+}
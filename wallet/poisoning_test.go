@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDetectPoisoning(t *testing.T) {
+	const (
+		own       = "TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY"
+		partner   = "TJRabPrwbZy45sbavfcjinPJC18kjpRTv8"
+		lookAlike = "TJRaxxxxxxxxxxxxxxxxxxxxxxxxxxRTv8"
+		stranger  = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	)
+	m := NewManager(WithDustThreshold("trx", NewAmountFromUint64(1, 0)))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	transfer := func(hash string, outgoing bool, counterparty string, sun uint64) *Transaction {
+		transaction := &Transaction{
+			Hash:      hash,
+			CreatedAt: start.Add(time.Duration(len(hash)) * time.Minute),
+			Incoming:  !outgoing,
+			Outgoing:  outgoing,
+			From:      counterparty,
+			To:        own,
+			Amount:    NewAmountFromUint64(sun, 6),
+		}
+		if outgoing {
+			transaction.From, transaction.To = own, counterparty
+		}
+		return transaction
+	}
+	spamFrom := transfer("spamFrom", true, lookAlike, 0)
+	spamFrom.Initiator = lookAlike
+	transactions := []*Transaction{
+		transfer("p", true, partner, 5000000),
+		transfer("sp", false, lookAlike, 0),
+		transfer("dus", false, lookAlike, 10),
+		transfer("refu", false, partner, 1000000),
+		transfer("zero0", false, stranger, 0),
+		transfer("dust00", false, stranger, 100),
+		transfer("payment", false, stranger, 2000000),
+		spamFrom,
+	}
+	if err := m.detectPoisoning("trx", own, transactions); err != nil {
+		t.Fatal(err)
+	}
+	want := []PoisoningReason{"", PoisoningLookAlike, PoisoningLookAlike, "", PoisoningZeroValue, PoisoningDust, "", PoisoningTransferFrom}
+	for i, transaction := range transactions {
+		if transaction.Poisoning != want[i] {
+			t.Errorf("%s: poisoning %q, want %q", transaction.Hash, transaction.Poisoning, want[i])
+		}
+	}
+	if clean := WithoutPoisoning(transactions); len(clean) != 3 {
+		t.Errorf("clean history %v", clean)
+	}
+}
+
+func TestSendToPoisoningRecipient(t *testing.T) {
+	m, client, from := newSendTest(t)
+	client.transactions[from] = []*Transaction{
+		{Hash: "spam", Incoming: true, From: syncTestAddress, To: from, Amount: NewAmountFromUint64(0, 6)},
+	}
+	transactions, err := m.GetAddressTransactions("trx", from)
+	if err != nil || transactions[0].Poisoning != PoisoningZeroValue {
+		t.Fatalf("transactions %v, %v", transactions, err)
+	}
+	amount := NewAmountFromUint64(1, 0)
+	if _, err = m.PrepareSend("trx", from, syncTestAddress, amount); !errors.Is(err, ErrPoisoningRecipient) {
+		t.Fatalf("err %v, want %v", err, ErrPoisoningRecipient)
+	}
+	quote, err := m.PrepareSend("trx", from, syncTestAddress, amount, WithSendPoisoningConfirmed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quote.Warnings) != 2 || quote.Warnings[1].Type != RecipientWarningPoisoning || quote.Warnings[1].Transaction.Hash != "spam" {
+		t.Errorf("warnings %+v", quote.Warnings)
+	}
+}
@@ -0,0 +1,556 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mcmx73/easytron/common"
+	"github.com/mcmx73/easytron/common/secret"
+	"github.com/mcmx73/easytron/keys"
+	"golang.org/x/crypto/scrypt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// dailyLimitWindow is the period DailyLimit counts outgoing transfers in
+	dailyLimitWindow = 24 * time.Hour
+
+	policyScryptR     = 8
+	policyScryptDKLen = 32
+)
+
+// PolicyRule names the rule of the spending policy a transfer violates
+type PolicyRule string
+
+const (
+	PolicyRuleMaxPerTransaction PolicyRule = "max_per_transaction"
+	PolicyRuleDailyLimit        PolicyRule = "daily_limit"
+	PolicyRuleAllowlist         PolicyRule = "allowlist"
+	PolicyRuleRecipientCooldown PolicyRule = "recipient_cooldown"
+	PolicyRuleContractCall      PolicyRule = "contract_call"
+)
+
+// Policy is the set of spending rules checked before the wallet signs outgoing transfers.
+// Coins without CoinPolicy are not restricted.
+type Policy struct {
+	Coins map[CoinId]*CoinPolicy `json:"coins"`
+}
+
+// CoinPolicy restricts transfers of the coin, tokens have their own coin ids. Empty fields
+// disable their rules.
+type CoinPolicy struct {
+	MaxPerTransaction *Amount `json:"max_per_transaction,omitempty"`
+	// DailyLimit caps the sum of outgoing transfers of tracked addresses in the last 24 hours
+	DailyLimit *Amount `json:"daily_limit,omitempty"`
+	// Allowlist lists the only recipients allowed, allowlisted recipients skip the cooldown
+	Allowlist []string `json:"allowlist,omitempty"`
+	// RecipientCooldown is how long a recipient must be known, as a contact or by earlier transfer,
+	// before funds can be sent to it
+	RecipientCooldown PolicyDuration `json:"recipient_cooldown,omitempty"`
+	// RestrictContracts refuses transfers to contract addresses except AllowedContracts
+	RestrictContracts bool     `json:"restrict_contracts,omitempty"`
+	AllowedContracts  []string `json:"allowed_contracts,omitempty"`
+}
+
+// PolicyDuration is time.Duration encoded in JSON as string like "24h"
+type PolicyDuration time.Duration
+
+func (d PolicyDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *PolicyDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: duration %s", ErrInvalidPolicy, data)
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil || duration < 0 {
+		return fmt.Errorf("%w: duration %q", ErrInvalidPolicy, s)
+	}
+	*d = PolicyDuration(duration)
+	return nil
+}
+
+// PolicyViolation is the error of the transfer refused by the policy, it matches ErrPolicyViolation
+type PolicyViolation struct {
+	Rule      PolicyRule `json:"rule"`
+	CoinId    CoinId     `json:"coin_id"`
+	Recipient string     `json:"recipient,omitempty"`
+	// Limit and Spent are set by the amount rules, Spent is the sum of the daily window
+	Limit *Amount `json:"limit,omitempty"`
+	Spent *Amount `json:"spent,omitempty"`
+	// AllowedAt is when the recipient cooldown ends
+	AllowedAt *time.Time `json:"allowed_at,omitempty"`
+}
+
+func (v *PolicyViolation) Error() string {
+	switch v.Rule {
+	case PolicyRuleMaxPerTransaction:
+		return fmt.Sprintf("%v: %s transfer exceeds limit of %s", ErrPolicyViolation, v.CoinId, v.Limit)
+	case PolicyRuleDailyLimit:
+		return fmt.Sprintf("%v: %s transfers exceed daily limit of %s, %s sent already", ErrPolicyViolation, v.CoinId, v.Limit, v.Spent)
+	case PolicyRuleAllowlist:
+		return fmt.Sprintf("%v: recipient %s is not allowlisted", ErrPolicyViolation, v.Recipient)
+	case PolicyRuleRecipientCooldown:
+		return fmt.Sprintf("%v: recipient %s is new, transfers are allowed from %s", ErrPolicyViolation, v.Recipient, v.AllowedAt.Format(time.RFC3339))
+	case PolicyRuleContractCall:
+		return fmt.Sprintf("%v: transfers to contract %s are not allowed", ErrPolicyViolation, v.Recipient)
+	}
+	return fmt.Sprintf("%v: %s", ErrPolicyViolation, v.Rule)
+}
+
+func (v *PolicyViolation) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// policyFile is the stored policy with HMAC keyed by scrypt of the wallet password, the MAC
+// covers compact JSON of the policy as written. Scrypt costs as much as for key files of the
+// keystore, so the file is no cheaper password oracle than the keystore itself.
+type policyFile struct {
+	Policy json.RawMessage `json:"policy"`
+	Mac    *policyMac      `json:"mac"`
+}
+
+type policyMac struct {
+	N    int    `json:"n"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+	Mac  []byte `json:"mac"`
+}
+
+func newPolicyFile(policy *Policy, password string, n, p int) (*policyFile, error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	file := &policyFile{Policy: data, Mac: &policyMac{N: n, P: p, Salt: make([]byte, 32)}}
+	if _, err = rand.Read(file.Mac.Salt); err != nil {
+		return nil, err
+	}
+	if file.Mac.Mac, err = file.mac(password); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *policyFile) mac(password string) ([]byte, error) {
+	key, err := scrypt.Key([]byte(password), f.Mac.Salt, f.Mac.N, policyScryptR, f.Mac.P, policyScryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(key)
+	policy := &bytes.Buffer{}
+	if err = json.Compact(policy, f.Policy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(policy.Bytes())
+	return mac.Sum(nil), nil
+}
+
+// verify returns the policy when its MAC matches the password, ErrPolicyPassword otherwise
+func (f *policyFile) verify(password string, n, p int) (*Policy, error) {
+	if err := f.checkScryptParams(n, p); err != nil {
+		return nil, err
+	}
+	mac, err := f.mac(password)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, f.Mac.Mac) {
+		return nil, ErrPolicyPassword
+	}
+	return f.decode()
+}
+
+// checkScryptParams refuses parameters other than n and p the wallet writes, so a crafted file
+// can not make the MAC check exhaust memory
+func (f *policyFile) checkScryptParams(n, p int) error {
+	if f.Mac.N != n || f.Mac.P != p {
+		return fmt.Errorf("%w: unexpected scrypt parameters n=%d p=%d", ErrInvalidPolicy, f.Mac.N, f.Mac.P)
+	}
+	return nil
+}
+
+func (f *policyFile) decode() (*Policy, error) {
+	policy := &Policy{}
+	if err := json.Unmarshal(f.Policy, policy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Policy returns copy of the spending policy, nil when there is none or it is locked
+func (m *Manager) Policy() *Policy {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	if m.policy == nil {
+		return nil
+	}
+	return m.policy.clone()
+}
+
+// PolicyLocked reports whether the stored policy waits for UnlockPolicy, transfers are refused
+// meanwhile
+func (m *Manager) PolicyLocked() bool {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.lockedPolicy != nil
+}
+
+// PolicyMissing reports whether the policy file was deleted or replaced, transfers are refused
+// until SetPolicy
+func (m *Manager) PolicyMissing() bool {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.policyMissing
+}
+
+// SetPolicy replaces the spending policy and writes it to the policy file. The password must be
+// the wallet password, which decrypts a key file of the keystore. Empty policy removes all rules,
+// it is written as well, so removing the policy requires the password too.
+func (m *Manager) SetPolicy(policy *Policy, password string) error {
+	if err := policy.validate(); err != nil {
+		return err
+	}
+	if err := m.checkWalletPassword(password); err != nil {
+		return err
+	}
+	n, p, err := m.policyScryptParams()
+	if err != nil {
+		return err
+	}
+	policy = policy.clone()
+	file, err := newPolicyFile(policy, password, n, p)
+	if err != nil {
+		return err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if err = m.savePolicy(file); err != nil {
+		return err
+	}
+	m.policy, m.lockedPolicy, m.policyMissing = policy, nil, false
+	return nil
+}
+
+// UnlockPolicy activates the stored policy after checking its MAC with the wallet password.
+// Keys unlocked with the wallet password unlock the policy as well.
+func (m *Manager) UnlockPolicy(password string) error {
+	m.mux.RLock()
+	file := m.lockedPolicy
+	m.mux.RUnlock()
+	if file == nil {
+		return nil
+	}
+	n, p, err := m.policyScryptParams()
+	if err != nil {
+		return err
+	}
+	policy, err := file.verify(password, n, p)
+	if err != nil {
+		return err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.lockedPolicy == file {
+		m.policy, m.lockedPolicy = policy, nil
+	}
+	return nil
+}
+
+// policyScryptParams returns scrypt N and P of the keystore, the policy MAC uses the same cost
+func (m *Manager) policyScryptParams() (n, p int, err error) {
+	if m.keyManager == nil || m.keyManager.KeyStore() == nil {
+		return 0, 0, keys.ErrNoKeyStore
+	}
+	n, p = m.keyManager.KeyStore().ScryptParams()
+	return n, p, nil
+}
+
+// checkWalletPassword returns ErrPolicyPassword unless the password decrypts a key file
+func (m *Manager) checkWalletPassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("%w: password is required", ErrPolicyPassword)
+	}
+	if m.keyManager == nil || m.keyManager.KeyStore() == nil {
+		return keys.ErrNoKeyStore
+	}
+	store := m.keyManager.KeyStore()
+	addresses, err := store.Addresses()
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		key, err := store.LoadKey(address, password)
+		if err == nil {
+			key.Destroy()
+			return nil
+		}
+		if !errors.Is(err, keys.ErrDecrypt) {
+			return err
+		}
+	}
+	return ErrPolicyPassword
+}
+
+// loadPolicy reads the policy file. The MAC needs the wallet password, so the policy stays locked
+// and refuses transfers until UnlockPolicy. The storage keeps MAC of the written file, a deleted
+// or replaced file refuses transfers with ErrPolicyMissing until SetPolicy.
+func (m *Manager) loadPolicy() error {
+	if m.policyPath == "" {
+		return nil
+	}
+	storedMac, err := m.storage.PolicyMac()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(m.policyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		m.mux.Lock()
+		defer m.mux.Unlock()
+		m.policy, m.lockedPolicy, m.policyMissing = nil, nil, storedMac != nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	file := &policyFile{}
+	if err = json.Unmarshal(data, file); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if len(file.Policy) == 0 || file.Mac == nil || len(file.Mac.Mac) == 0 {
+		return fmt.Errorf("%w: policy and mac are required", ErrInvalidPolicy)
+	}
+	if n, p, err := m.policyScryptParams(); err == nil {
+		if err = file.checkScryptParams(n, p); err != nil {
+			return err
+		}
+	}
+	if _, err = file.decode(); err != nil {
+		return err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	// policy files written before the storage kept their MAC have none stored
+	if storedMac != nil && !hmac.Equal(storedMac, file.Mac.Mac) {
+		m.policy, m.lockedPolicy, m.policyMissing = nil, nil, true
+		return nil
+	}
+	m.policy, m.lockedPolicy, m.policyMissing = nil, file, false
+	return nil
+}
+
+// savePolicy writes the policy file, then its MAC to the storage
+func (m *Manager) savePolicy(file *policyFile) error {
+	if m.policyPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(m.policyPath), 0700); err != nil {
+		return err
+	}
+	if err = common.WriteFileAtomic(m.policyPath, data); err != nil {
+		return err
+	}
+	return m.storage.SetPolicyMac(file.Mac.Mac)
+}
+
+// checkPolicy refuses the transfer violating the spending policy with *PolicyViolation
+func (m *Manager) checkPolicy(request *TransferRequest) error {
+	m.mux.RLock()
+	locked, missing := m.lockedPolicy != nil, m.policyMissing
+	var rules *CoinPolicy
+	if m.policy != nil {
+		rules = m.policy.Coins[request.CoinId]
+	}
+	m.mux.RUnlock()
+	if missing {
+		return ErrPolicyMissing
+	}
+	if locked {
+		return ErrPolicyLocked
+	}
+	if rules == nil {
+		return nil
+	}
+	violation := func(rule PolicyRule) *PolicyViolation {
+		return &PolicyViolation{Rule: rule, CoinId: request.CoinId, Recipient: request.To}
+	}
+	decimals := request.Amount.Decimals()
+	if rules.MaxPerTransaction != nil {
+		limit, err := limitOf(rules.MaxPerTransaction, decimals)
+		if err != nil {
+			return err
+		}
+		if request.Amount.GreaterThan(limit) {
+			v := violation(PolicyRuleMaxPerTransaction)
+			v.Limit = &limit
+			return v
+		}
+	}
+	if rules.DailyLimit != nil {
+		limit, err := limitOf(rules.DailyLimit, decimals)
+		if err != nil {
+			return err
+		}
+		spent, err := m.spentSince(request.CoinId, request.From, decimals, time.Now().Add(-dailyLimitWindow))
+		if err != nil {
+			return err
+		}
+		total, err := spent.Add(request.Amount)
+		if err != nil {
+			return err
+		}
+		if total.GreaterThan(limit) {
+			v := violation(PolicyRuleDailyLimit)
+			v.Limit, v.Spent = &limit, &spent
+			return v
+		}
+	}
+	allowlisted := containsAddress(rules.Allowlist, request.To)
+	if len(rules.Allowlist) > 0 && !allowlisted {
+		return violation(PolicyRuleAllowlist)
+	}
+	if rules.RecipientCooldown > 0 && !allowlisted {
+		knownSince, err := m.knownSince(request.CoinId, request.From, request.To)
+		if err != nil {
+			return err
+		}
+		allowedAt := knownSince.Add(time.Duration(rules.RecipientCooldown))
+		if knownSince.IsZero() || time.Now().Before(allowedAt) {
+			v := violation(PolicyRuleRecipientCooldown)
+			if knownSince.IsZero() {
+				allowedAt = time.Now().Add(time.Duration(rules.RecipientCooldown))
+			}
+			allowedAt = allowedAt.UTC()
+			v.AllowedAt = &allowedAt
+			return v
+		}
+	}
+	if rules.RestrictContracts && !containsAddress(rules.AllowedContracts, request.To) {
+		contractOf, err := m.contractOf(request.CoinId, request.To)
+		if err != nil {
+			return err
+		}
+		if contractOf != "" {
+			return violation(PolicyRuleContractCall)
+		}
+	}
+	return nil
+}
+
+// limitOf returns the limit with decimals of the coin, limits set by users like "100" have fewer
+func limitOf(limit *Amount, decimals int) (Amount, error) {
+	rescaled, err := limit.Rescale(decimals)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	return rescaled, nil
+}
+
+// spentSince sums outgoing transfers of tracked addresses and the sender cached since the time,
+// suspected address poisoning is not spending
+func (m *Manager) spentSince(coinId CoinId, from string, decimals int, since time.Time) (Amount, error) {
+	spent := NewAmountFromUint64(0, decimals)
+	err := m.ownHistory(coinId, from, func(transaction *Transaction) error {
+		if !transaction.Outgoing || transaction.Poisoning != "" || transaction.CreatedAt.Before(since) {
+			return nil
+		}
+		amount, err := transaction.Amount.Rescale(decimals)
+		if err != nil {
+			return err
+		}
+		spent, err = spent.Add(amount)
+		return err
+	})
+	return spent, err
+}
+
+// knownSince returns when the recipient became known, as a contact or by the first outgoing
+// transfer, zero time for new recipients
+func (m *Manager) knownSince(coinId CoinId, from, to string) (time.Time, error) {
+	var since time.Time
+	if contact, found := m.FindContact(to); found {
+		since = contact.CreatedAt
+	}
+	to = normalizeAddress(to)
+	err := m.ownHistory(coinId, from, func(transaction *Transaction) error {
+		if transaction.Outgoing && transaction.Poisoning == "" && normalizeAddress(transaction.To) == to &&
+			(since.IsZero() || transaction.CreatedAt.Before(since)) {
+			since = transaction.CreatedAt
+		}
+		return nil
+	})
+	return since, err
+}
+
+// ownHistory calls fn for cached transactions of tracked addresses and the sender, transfers
+// between own addresses are seen once per address. Expired pending transfers are skipped.
+func (m *Manager) ownHistory(coinId CoinId, from string, fn func(*Transaction) error) error {
+	own, err := m.TrackedAddresses(coinId)
+	if err != nil {
+		return err
+	}
+	addresses := newAddressSet(append(own, from)...)
+	seen := make(map[string]bool)
+	now := time.Now()
+	for _, address := range addresses.addresses {
+		transactions, err := m.storage.Transactions(coinId, address)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range transactions {
+			if seen[transaction.Hash] || m.pendingExpired(transaction, now) {
+				continue
+			}
+			seen[transaction.Hash] = true
+			if err = fn(transaction); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Policy) validate() error {
+	if p == nil {
+		return fmt.Errorf("%w: policy is empty", ErrInvalidPolicy)
+	}
+	for coinId, rules := range p.Coins {
+		if rules == nil {
+			return fmt.Errorf("%w: %s rules are empty", ErrInvalidPolicy, coinId)
+		}
+		for _, address := range append(append([]string(nil), rules.Allowlist...), rules.AllowedContracts...) {
+			if err := keys.ValidateAddress(address); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, address, err)
+			}
+		}
+	}
+	return nil
+}
+
+// clone copies the rules, amounts are immutable and shared
+func (p *Policy) clone() *Policy {
+	clone := &Policy{Coins: make(map[CoinId]*CoinPolicy, len(p.Coins))}
+	for coinId, rules := range p.Coins {
+		copied := *rules
+		copied.Allowlist = append([]string(nil), rules.Allowlist...)
+		copied.AllowedContracts = append([]string(nil), rules.AllowedContracts...)
+		clone.Coins[coinId] = &copied
+	}
+	return clone
+}
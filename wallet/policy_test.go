@@ -0,0 +1,264 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	m, client, from := newSendTest(t, WithPolicyFile(path))
+	limit := NewAmountFromUint64(10, 0)
+	policy := &Policy{Coins: map[CoinId]*CoinPolicy{"trx": {MaxPerTransaction: &limit, RecipientCooldown: PolicyDuration(time.Hour)}}}
+	for _, password := range []string{"", "wrong"} {
+		if err := m.SetPolicy(policy, password); !errors.Is(err, ErrPolicyPassword) {
+			t.Errorf("err %v, want %v", err, ErrPolicyPassword)
+		}
+	}
+	invalid := &Policy{Coins: map[CoinId]*CoinPolicy{"trx": {Allowlist: []string{"TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZy"}}}}
+	if err := m.SetPolicy(invalid, sendTestPassword); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("err %v, want %v", err, ErrInvalidPolicy)
+	}
+	if err := NewManager().SetPolicy(policy, sendTestPassword); !errors.Is(err, keys.ErrNoKeyStore) {
+		t.Errorf("err %v, want %v", err, keys.ErrNoKeyStore)
+	}
+	if err := m.SetPolicy(policy, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	restart := func() *Manager {
+		t.Helper()
+		restarted := NewManager(WithPolicyFile(path), WithStorage(m.storage),
+			WithKeyManager(keys.NewManager(keys.WithKeyStore(m.keyManager.KeyStore()))))
+		restarted.AddCoin(client)
+		if err := restarted.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return restarted
+	}
+	// the policy is stored with MAC of the wallet password, transfers wait until it is checked
+	restarted := restart()
+	if !restarted.PolicyLocked() || restarted.Policy() != nil {
+		t.Fatal("stored policy is not locked")
+	}
+	if _, err := restarted.UnlockKey(from, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	rules := restarted.Policy().Coins["trx"]
+	if restarted.PolicyLocked() || rules == nil || !rules.MaxPerTransaction.Equal(limit) || rules.RecipientCooldown != PolicyDuration(time.Hour) {
+		t.Fatalf("policy %+v", rules)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"max_per_transaction": "10"`, `"max_per_transaction": "10000"`, 1)
+	if tampered == string(data) {
+		t.Fatalf("policy file %s", data)
+	}
+	if err = os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	restarted = restart()
+	if _, err = restarted.UnlockKey(from, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	if err = restarted.UnlockPolicy(sendTestPassword); !errors.Is(err, ErrPolicyPassword) {
+		t.Errorf("err %v, tampered policy is accepted", err)
+	}
+	if _, err = restarted.PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(1, 0)); !errors.Is(err, ErrPolicyLocked) {
+		t.Errorf("err %v, want %v", err, ErrPolicyLocked)
+	}
+	if err = restarted.SetPolicy(&Policy{}, sendTestPassword); err != nil || restarted.PolicyLocked() {
+		t.Errorf("policy is not replaced: %v", err)
+	}
+
+	// scrypt parameters come from the keystore, not from the file
+	if data, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	crafted := strings.Replace(string(data), fmt.Sprintf(`"n": %d`, keys.LightScryptN), `"n": 1073741824`, 1)
+	if crafted == string(data) {
+		t.Fatalf("policy file %s", data)
+	}
+	if err = os.WriteFile(path, []byte(crafted), 0600); err != nil {
+		t.Fatal(err)
+	}
+	restarted = NewManager(WithPolicyFile(path), WithStorage(m.storage), WithKeyManager(keys.NewManager(keys.WithKeyStore(m.keyManager.KeyStore()))))
+	if err = restarted.Load(); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("err %v, want %v", err, ErrInvalidPolicy)
+	}
+}
+
+func TestPolicyFileRemoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	m, client, from := newSendTest(t, WithPolicyFile(path))
+	limit := NewAmountFromUint64(10, 0)
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": {MaxPerTransaction: &limit}}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	restart := func() *Manager {
+		t.Helper()
+		restarted := NewManager(WithPolicyFile(path), WithStorage(m.storage),
+			WithKeyManager(keys.NewManager(keys.WithKeyStore(m.keyManager.KeyStore()))))
+		restarted.AddCoin(client)
+		if err := restarted.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := restarted.UnlockKey(from, sendTestPassword); err != nil {
+			t.Fatal(err)
+		}
+		return restarted
+	}
+	older, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	restarted := restart()
+	if _, err = restarted.PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(100, 0)); !errors.Is(err, ErrPolicyMissing) || !restarted.PolicyMissing() {
+		t.Errorf("err %v, want %v", err, ErrPolicyMissing)
+	}
+	// removing the policy requires the wallet password as any other change
+	if err = restarted.SetPolicy(&Policy{}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = restart().PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(100, 0)); err != nil {
+		t.Errorf("removed policy refuses transfer: %v", err)
+	}
+
+	// a file written earlier with the same password does not replace the current one
+	if err = os.WriteFile(path, older, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = restart().PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(1, 0)); !errors.Is(err, ErrPolicyMissing) {
+		t.Errorf("err %v, want %v", err, ErrPolicyMissing)
+	}
+}
+
+func TestSpendingPolicy(t *testing.T) {
+	m, _, from := newSendTest(t)
+	maxPerTransaction, dailyLimit := NewAmountFromUint64(10, 0), NewAmountFromUint64(15, 0)
+	rules := &CoinPolicy{MaxPerTransaction: &maxPerTransaction, DailyLimit: &dailyLimit}
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": rules}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	check := func(to string, trx uint64, rule PolicyRule) *PolicyViolation {
+		t.Helper()
+		quote, err := m.PrepareSend("trx", from, to, NewAmountFromUint64(trx, 0))
+		if rule == "" {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = m.ConfirmSend(quote.Id); err != nil {
+				t.Fatal(err)
+			}
+			return nil
+		}
+		var violation *PolicyViolation
+		if !errors.As(err, &violation) || violation.Rule != rule || !errors.Is(err, ErrPolicyViolation) {
+			t.Fatalf("err %v, want %s violation", err, rule)
+		}
+		return violation
+	}
+	check(syncTestAddress, 11, PolicyRuleMaxPerTransaction)
+	check(syncTestAddress, 10, "")
+	if violation := check(syncTestAddress, 6, PolicyRuleDailyLimit); violation.Spent.String() != "10" {
+		t.Errorf("spent %s", violation.Spent)
+	}
+	check(syncTestAddress, 5, "")
+
+	dailyLimit = NewAmountFromUint64(100, 0)
+	rules.Allowlist = []string{syncTestAddress}
+	rules.RecipientCooldown = PolicyDuration(time.Hour)
+	rules.RestrictContracts = true
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": rules}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	check(syncTestOther, 1, PolicyRuleAllowlist)
+	check(syncTestAddress, 1, "")
+
+	rules.Allowlist = nil
+	rules.AllowedContracts = []string{"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"}
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": rules}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	// recipient must be known longer than the cooldown
+	if violation := check(syncTestAddress, 1, PolicyRuleRecipientCooldown); violation.AllowedAt.Before(time.Now().Add(50 * time.Minute)) {
+		t.Errorf("allowed at %s", violation.AllowedAt)
+	}
+	check(syncTestOther, 1, PolicyRuleRecipientCooldown)
+	earlier := &Transaction{Hash: "earlier", Outgoing: true, CreatedAt: time.Now().Add(-2 * time.Hour), From: from, To: syncTestOther, Amount: NewAmountFromUint64(1, 0)}
+	if err := m.storage.SaveTransactions("trx", from, []*Transaction{earlier}); err != nil {
+		t.Fatal(err)
+	}
+	check(syncTestOther, 1, "")
+	rules.RecipientCooldown = 0
+	rules.AllowedContracts = nil
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": rules}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	check("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", 1, PolicyRuleContractCall)
+}
+
+// failingStorage refuses to cache transactions
+type failingStorage struct {
+	Storage
+}
+
+func (s *failingStorage) SaveTransactions(CoinId, string, []*Transaction) error {
+	return errors.New("disk full")
+}
+
+func TestConfirmSendRecordsSpend(t *testing.T) {
+	m, client, from := newSendTest(t)
+	client.signDelay = 20 * time.Millisecond
+	dailyLimit := NewAmountFromUint64(15, 0)
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": {DailyLimit: &dailyLimit}}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	var quotes []*SendQuote
+	for i := 0; i < 2; i++ {
+		quote, err := m.PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(10, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		quotes = append(quotes, quote)
+	}
+	errs := make(chan error, len(quotes))
+	for _, quote := range quotes {
+		go func(quote *SendQuote) {
+			_, err := m.ConfirmSend(quote.Id)
+			errs <- err
+		}(quote)
+	}
+	violations := 0
+	for range quotes {
+		if err := <-errs; errors.Is(err, ErrPolicyViolation) {
+			violations++
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if violations != 1 || len(client.broadcast) != 1 {
+		t.Errorf("%d violations, %d broadcast, concurrent confirmations passed the daily limit", violations, len(client.broadcast))
+	}
+
+	m.storage = &failingStorage{Storage: m.storage}
+	quote, err := m.PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.ConfirmSend(quote.Id); err == nil || len(client.broadcast) != 1 {
+		t.Errorf("err %v, transfer is broadcast without recorded spend", err)
+	}
+}
@@ -0,0 +1,319 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultQuoteTTL   = 2 * time.Minute
+	DefaultPendingTTL = time.Hour
+)
+
+// TransferRequest describes funds transfer of the coin, tokens have their own coin ids
+type TransferRequest struct {
+	CoinId CoinId `json:"coin_id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount Amount `json:"amount"`
+	Memo   string `json:"memo,omitempty"`
+	// FeeLimit caps fee the transaction may burn, e.g. energy of TRC20 transfers, zero means
+	// the chain default
+	FeeLimit Amount `json:"fee_limit"`
+
+	poisoningConfirmed bool
+}
+
+// FeeQuote is the fee estimate of the prepared transfer in CoinId, the native coin of the chain
+type FeeQuote struct {
+	CoinId CoinId `json:"coin_id"`
+	Fee    Amount `json:"fee"`
+	MaxFee Amount `json:"max_fee"`
+}
+
+// PreparedTransfer is unsigned chain specific transaction with its fee quote
+type PreparedTransfer struct {
+	Request *TransferRequest
+	Fee     FeeQuote
+	// Summary is human-readable description shown to the user before signing
+	Summary string
+	Payload []byte
+}
+
+// SignedTransfer is ready to broadcast chain specific transaction
+type SignedTransfer struct {
+	Prepared *PreparedTransfer
+	// Hash is the transaction hash, the spend is recorded under it before broadcast
+	Hash    string
+	Payload []byte
+}
+
+type WithSendOption func(*TransferRequest)
+
+func WithSendMemo(memo string) WithSendOption {
+	return func(r *TransferRequest) {
+		r.Memo = memo
+	}
+}
+
+func WithSendFeeLimit(feeLimit Amount) WithSendOption {
+	return func(r *TransferRequest) {
+		r.FeeLimit = feeLimit
+	}
+}
+
+// WithSendPoisoningConfirmed allows the recipient coming from suspected address poisoning
+// transaction after the user confirmed it
+func WithSendPoisoningConfirmed() WithSendOption {
+	return func(r *TransferRequest) {
+		r.poisoningConfirmed = true
+	}
+}
+
+// SendQuote is a prepared transfer waiting for confirmation by Id until ExpiresAt
+type SendQuote struct {
+	Id        string    `json:"id"`
+	CoinId    CoinId    `json:"coin_id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    Amount    `json:"amount"`
+	Fee       FeeQuote  `json:"fee"`
+	Summary   string    `json:"summary"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Warnings about the recipient, see CheckRecipient
+	Warnings []*RecipientWarning `json:"warnings,omitempty"`
+
+	prepared *PreparedTransfer
+}
+
+// ParseAmount parses user entered amount with decimals of the coin
+func (m *Manager) ParseAmount(coinId CoinId, amount string) (Amount, error) {
+	coin, err := m.coin(coinId)
+	if err != nil {
+		return Amount{}, err
+	}
+	return coin.ParseAmount(amount)
+}
+
+// PrepareSend validates the transfer, asks the chain for unsigned transaction and fee quote and
+// keeps it for ConfirmSend until the quote expires. Nothing is signed yet. Recipients copied from
+// suspected address poisoning transactions are refused unless WithSendPoisoningConfirmed.
+func (m *Manager) PrepareSend(coinId CoinId, from, to string, amount Amount, options ...WithSendOption) (*SendQuote, error) {
+	coin, err := m.coin(coinId)
+	if err != nil {
+		return nil, err
+	}
+	client, err := m.client(coinId)
+	if err != nil {
+		return nil, err
+	}
+	request := &TransferRequest{CoinId: coinId, From: from, To: to}
+	for _, opt := range options {
+		opt(request)
+	}
+	warnings, err := m.CheckRecipient(coinId, to)
+	if err != nil {
+		return nil, err
+	}
+	if _, isContact := m.FindContact(to); !isContact && !hasWarning(warnings, RecipientWarningPoisoning) {
+		// the sender may be a key which is not tracked, e.g. imported without discovery
+		warning, err := m.poisoningWarning(coinId, []string{normalizeAddress(from)}, to)
+		if err != nil {
+			return nil, err
+		}
+		if warning != nil {
+			warnings = append(warnings, warning)
+		}
+	}
+	if hasWarning(warnings, RecipientWarningPoisoning) && !request.poisoningConfirmed {
+		return nil, fmt.Errorf("%w: %s", ErrPoisoningRecipient, to)
+	}
+	if amount, err = amount.Rescale(coin.Decimals); err != nil {
+		return nil, err
+	}
+	if amount.IsZero() {
+		return nil, fmt.Errorf("%w: zero transfer", ErrInvalidAmount)
+	}
+	// watch-only and unknown senders are refused before the user reviews the quote
+	if _, err = m.GetKey(from); err != nil {
+		return nil, err
+	}
+	request.Amount = amount
+	if err = m.checkPolicy(request); err != nil {
+		return nil, err
+	}
+	prepared, err := client.PrepareTransfer(request)
+	if err != nil {
+		return nil, err
+	}
+	if prepared.Summary == "" {
+		prepared.Summary = fmt.Sprintf("Send %s %s from %s to %s, network fee up to %s %s",
+			amount, coin.Symbol, from, to, prepared.Fee.MaxFee, prepared.Fee.CoinId)
+	}
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	quote := &SendQuote{
+		Id:        hex.EncodeToString(id),
+		CoinId:    coinId,
+		From:      from,
+		To:        to,
+		Amount:    amount,
+		Fee:       prepared.Fee,
+		Summary:   prepared.Summary,
+		ExpiresAt: now.Add(m.quoteTTL),
+		Warnings:  warnings,
+		prepared:  prepared,
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for quoteId, pending := range m.quotes {
+		if now.After(pending.ExpiresAt) {
+			delete(m.quotes, quoteId)
+		}
+	}
+	m.quotes[quote.Id] = quote
+	return quote, nil
+}
+
+// ConfirmSend signs and broadcasts the quoted transfer and returns transaction hash. Quotes are
+// single use, expired quotes must be prepared again to get a fresh fee. The spending policy is
+// checked again, other transfers may have used the daily limit since the quote.
+func (m *Manager) ConfirmSend(quoteId string) (string, error) {
+	m.mux.Lock()
+	quote, found := m.quotes[quoteId]
+	delete(m.quotes, quoteId)
+	m.mux.Unlock()
+	if !found {
+		return "", ErrQuoteNotFound
+	}
+	if time.Now().After(quote.ExpiresAt) {
+		return "", ErrQuoteExpired
+	}
+	// transfers of the coin are checked, signed and recorded one by one, so concurrent
+	// confirmations can not pass the daily limit together
+	lock := m.sendLock(quote.CoinId)
+	lock.Lock()
+	defer lock.Unlock()
+	err := m.checkPolicy(&TransferRequest{CoinId: quote.CoinId, From: quote.From, To: quote.To, Amount: quote.Amount})
+	if err != nil {
+		return "", err
+	}
+	client, err := m.client(quote.CoinId)
+	if err != nil {
+		return "", err
+	}
+	key, err := m.GetKey(quote.From)
+	if err != nil {
+		return "", err
+	}
+	signed, err := client.SignTransfer(quote.prepared, key)
+	if err != nil {
+		return "", err
+	}
+	if signed.Hash == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoTransferHash, quote.CoinId)
+	}
+	pending := &Transaction{
+		Hash:      signed.Hash,
+		Outgoing:  true,
+		CreatedAt: time.Now(),
+		From:      quote.From,
+		To:        quote.To,
+		Amount:    quote.Amount,
+		Fee:       quote.Fee.Fee,
+		Pending:   true,
+	}
+	// the spend is recorded before broadcast and kept when the broadcast fails, the transaction
+	// may have reached the network. The sync replaces it with confirmed one of the same hash or
+	// drops it after the pending TTL.
+	from := normalizeAddress(quote.From)
+	if err = m.storage.SaveTransactions(quote.CoinId, from, []*Transaction{pending}); err != nil {
+		return "", err
+	}
+	hash, err := client.BroadcastTransfer(signed)
+	if errors.Is(err, ErrTransferRejected) || errors.Is(err, ErrNotImplemented) {
+		if deleteErr := m.storage.DeleteTransaction(quote.CoinId, from, signed.Hash); deleteErr != nil {
+			return "", fmt.Errorf("%w, pending record is kept: %v", err, deleteErr)
+		}
+	}
+	return hash, err
+}
+
+// dropExpiredPending deletes pending transfers of the address missing in the fetched history
+// after the pending TTL, they never reached the chain
+func (m *Manager) dropExpiredPending(coinId CoinId, address string, fetched []*Transaction, now time.Time) error {
+	cached, err := m.storage.Transactions(coinId, address)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(fetched))
+	for _, transaction := range fetched {
+		found[transaction.Hash] = true
+	}
+	for _, transaction := range cached {
+		if m.pendingExpired(transaction, now) && !found[transaction.Hash] {
+			if err = m.storage.DeleteTransaction(coinId, address, transaction.Hash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) pendingExpired(transaction *Transaction, now time.Time) bool {
+	return transaction.Pending && now.Sub(transaction.CreatedAt) > m.pendingTTL
+}
+
+// CancelSend drops the quote
+func (m *Manager) CancelSend(quoteId string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.quotes, quoteId)
+}
+
+// Send prepares, signs and broadcasts the transfer at once, for callers without confirmation step
+func (m *Manager) Send(coinId CoinId, from, to string, amount Amount, options ...WithSendOption) (string, error) {
+	quote, err := m.PrepareSend(coinId, from, to, amount, options...)
+	if err != nil {
+		return "", err
+	}
+	return m.ConfirmSend(quote.Id)
+}
+
+func hasWarning(warnings []*RecipientWarning, warningType RecipientWarningType) bool {
+	for _, warning := range warnings {
+		if warning.Type == warningType {
+			return true
+		}
+	}
+	return false
+}
+
+// sendLock returns the lock serializing confirmations of the coin
+func (m *Manager) sendLock(coinId CoinId) *sync.Mutex {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	lock, found := m.sendLocks[coinId]
+	if !found {
+		lock = &sync.Mutex{}
+		m.sendLocks[coinId] = lock
+	}
+	return lock
+}
+
+func (m *Manager) coin(coinId CoinId) (*CoinDescription, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	coin, found := m.coinsById[coinId]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrCoinNotFound, coinId)
+	}
+	return coin, nil
+}
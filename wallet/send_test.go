@@ -0,0 +1,183 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTransferNotSupported = errors.New("transfer is not supported")
+
+type sendTestClient struct {
+	*syncTestClient
+	mux       sync.Mutex
+	signed    int
+	signDelay time.Duration
+	broadcast []*SignedTransfer
+	// broadcastErr fails broadcasts when set
+	broadcastErr error
+}
+
+func (c *sendTestClient) PrepareTransfer(request *TransferRequest) (*PreparedTransfer, error) {
+	return &PreparedTransfer{
+		Request: request,
+		Fee:     FeeQuote{CoinId: "trx", Fee: NewAmountFromUint64(0, 6), MaxFee: NewAmountFromUint64(1100000, 6)},
+		Payload: []byte(request.To),
+	}, nil
+}
+
+func (c *sendTestClient) SignTransfer(prepared *PreparedTransfer, key *keys.Key) (*SignedTransfer, error) {
+	signature, err := key.SignTronMessage(prepared.Payload)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(c.signDelay)
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.signed++
+	return &SignedTransfer{Prepared: prepared, Hash: fmt.Sprintf("hash%d", c.signed), Payload: signature}, nil
+}
+
+func (c *sendTestClient) BroadcastTransfer(signed *SignedTransfer) (string, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.broadcastErr != nil {
+		return "", c.broadcastErr
+	}
+	c.broadcast = append(c.broadcast, signed)
+	return signed.Hash, nil
+}
+
+// sendTestPassword encrypts the key of the sender, it is the wallet password of spending policies
+const sendTestPassword = "secret"
+
+func newSendTest(t *testing.T, options ...WithOption) (*Manager, *sendTestClient, string) {
+	keyStore := keys.NewKeyStore(t.TempDir(), keys.WithScryptParams(keys.LightScryptN, keys.LightScryptP))
+	keyManager := keys.NewManager(keys.WithKeyStore(keyStore))
+	key := keys.NewKey(keys.WithPrivateKeyHex("0000000000000000000000000000000000000000000000000000000000000001"))
+	if err := keyManager.StoreKey(key, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	from, err := key.TronAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &sendTestClient{syncTestClient: newSyncTestClient()}
+	m := NewManager(append([]WithOption{WithKeyManager(keyManager)}, options...)...)
+	m.AddCoin(client)
+	return m, client, from
+}
+
+func TestPrepareConfirmSend(t *testing.T) {
+	m, client, from := newSendTest(t)
+	amount, err := m.ParseAmount("trx", "12.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote, err := m.PrepareSend("trx", from, syncTestAddress, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Send 12.5 TRX from " + from + " to " + syncTestAddress + ", network fee up to 1.1 trx"
+	if quote.Summary != want || quote.Fee.MaxFee.String() != "1.1" || len(client.broadcast) != 0 {
+		t.Errorf("quote %+v", quote)
+	}
+	hash, err := m.ConfirmSend(quote.Id)
+	if err != nil || hash != "hash1" || len(client.broadcast) != 1 {
+		t.Fatalf("hash %q, %v", hash, err)
+	}
+	if _, err = m.ConfirmSend(quote.Id); !errors.Is(err, ErrQuoteNotFound) {
+		t.Errorf("err %v, quotes must be single use", err)
+	}
+	if cached, _ := m.CachedTransactions("trx", from); len(cached) != 1 || !cached[0].Outgoing {
+		t.Errorf("pending transaction is not cached: %v", cached)
+	}
+}
+
+func TestConfirmSendPending(t *testing.T) {
+	m, client, from := newSendTest(t)
+	dailyLimit := NewAmountFromUint64(15, 0)
+	if err := m.SetPolicy(&Policy{Coins: map[CoinId]*CoinPolicy{"trx": {DailyLimit: &dailyLimit}}}, sendTestPassword); err != nil {
+		t.Fatal(err)
+	}
+	send := func() error {
+		t.Helper()
+		quote, err := m.PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(10, 0))
+		if err != nil {
+			return err
+		}
+		_, err = m.ConfirmSend(quote.Id)
+		return err
+	}
+	// rejected transfer never reached the network, it is neither history nor spent
+	client.broadcastErr = fmt.Errorf("%w: contract validate error", ErrTransferRejected)
+	if err := send(); !errors.Is(err, ErrTransferRejected) {
+		t.Fatalf("err %v, want %v", err, ErrTransferRejected)
+	}
+	if cached, _ := m.CachedTransactions("trx", from); len(cached) != 0 {
+		t.Errorf("rejected transfer is cached: %v", cached)
+	}
+	// the outcome of failed broadcast is unknown, the spend is kept until it expires
+	client.broadcastErr = errors.New("connection reset")
+	if err := send(); err == nil {
+		t.Fatal("broadcast error is lost")
+	}
+	cached, _ := m.CachedTransactions("trx", from)
+	if len(cached) != 1 || !cached[0].Pending {
+		t.Fatalf("pending transfer is not cached: %v", cached)
+	}
+	client.broadcastErr = nil
+	if err := send(); !errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("err %v, pending transfer is not spent", err)
+	}
+	cached[0].CreatedAt = time.Now().Add(-DefaultPendingTTL - time.Minute)
+	if err := m.storage.SaveTransactions("trx", from, cached); err != nil {
+		t.Fatal(err)
+	}
+	if expired, _ := m.CachedTransactions("trx", from); len(expired) != 0 {
+		t.Errorf("expired pending transfer is cached: %v", expired)
+	}
+	if err := send(); err != nil {
+		t.Errorf("expired pending transfer is spent: %v", err)
+	}
+	// the chain history does not have the expired transfer, refresh drops it
+	if _, err := m.GetAddressTransactions("trx", from); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := m.storage.Transactions("trx", from)
+	for _, transaction := range stored {
+		if transaction.Hash == cached[0].Hash {
+			t.Errorf("expired pending transfer %s is stored", transaction.Hash)
+		}
+	}
+}
+
+func TestPrepareSendErrors(t *testing.T) {
+	m, _, from := newSendTest(t, WithQuoteTTL(-time.Second))
+	amount := NewAmountFromUint64(1, 6)
+	if _, err := m.PrepareSend("trx", from, "T123", amount); !errors.Is(err, keys.ErrInvalidAddress) {
+		t.Errorf("err %v, want %v", err, keys.ErrInvalidAddress)
+	}
+	if _, err := m.PrepareSend("trx", from, syncTestAddress, NewAmountFromUint64(1, 7)); !errors.Is(err, ErrAmountPrecision) {
+		t.Errorf("err %v, want %v", err, ErrAmountPrecision)
+	}
+	if _, err := m.PrepareSend("trx", from, syncTestAddress, Amount{}); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("err %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err := m.AddWatchOnlyAddress("trx", syncTestOther, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.PrepareSend("trx", syncTestOther, syncTestAddress, amount); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("err %v, want %v", err, ErrWatchOnly)
+	}
+	quote, err := m.PrepareSend("trx", from, syncTestAddress, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.ConfirmSend(quote.Id); !errors.Is(err, ErrQuoteExpired) {
+		t.Errorf("err %v, want %v", err, ErrQuoteExpired)
+	}
+}
@@ -0,0 +1,64 @@
+package wallet
+
+import "sort"
+
+// Storage keeps wallet data between restarts: accounts, derived addresses, labels, address book,
+// cached transactions and sync cursors. Addresses are passed normalized by Manager.
+type Storage interface {
+	SaveAccount(account *Account) error
+	DeleteAccount(coinId CoinId, address string) error
+	Accounts() ([]*Account, error)
+
+	SaveAddress(address *DiscoveredAddress) error
+	Addresses(coinId CoinId) ([]*DiscoveredAddress, error)
+
+	SetLabel(address, label string) error
+	Labels() (map[string]string, error)
+
+	SaveContact(contact *Contact) error
+	DeleteContact(id string) error
+	Contacts() ([]*Contact, error)
+
+	// SaveTransactions adds or replaces transactions of the address by hash
+	SaveTransactions(coinId CoinId, address string, transactions []*Transaction) error
+	// Transactions returns cached transactions of the address, newest first
+	Transactions(coinId CoinId, address string) ([]*Transaction, error)
+	DeleteTransaction(coinId CoinId, address, hash string) error
+
+	// SetSyncCursor stores position the next sync of the address continues from, e.g. block number
+	SetSyncCursor(coinId CoinId, address, cursor string) error
+	SyncCursor(coinId CoinId, address string) (string, error)
+
+	// SetBalances stores balances of the address from the last refresh, so they are shown
+	// before the network answers after restart
+	SetBalances(coinId CoinId, address string, balances map[string]Amount) error
+	// Balances returns stored balances of the address, nil when it was never refreshed
+	Balances(coinId CoinId, address string) (map[string]Amount, error)
+
+	// SetPolicyMac stores MAC of the written policy file, Load refuses transfers when the file is
+	// missing or another one. Nil MAC means there is no policy file.
+	SetPolicyMac(mac []byte) error
+	PolicyMac() ([]byte, error)
+
+	Close() error
+}
+
+// SortTransactions orders transactions newest first, transactions created at the same time by hash
+func SortTransactions(transactions []*Transaction) {
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].CreatedAt.Equal(transactions[j].CreatedAt) {
+			return transactions[i].CreatedAt.After(transactions[j].CreatedAt)
+		}
+		return transactions[i].Hash < transactions[j].Hash
+	})
+}
+
+// SortAddresses orders derived addresses by account and index
+func SortAddresses(addresses []*DiscoveredAddress) {
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].Account != addresses[j].Account {
+			return addresses[i].Account < addresses[j].Account
+		}
+		return addresses[i].Index < addresses[j].Index
+	})
+}
@@ -0,0 +1,452 @@
+package wallet
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSyncInterval     = 5 * time.Second
+	DefaultIdleSyncFactor   = 12
+	DefaultRecentViewWindow = 5 * time.Minute
+	DefaultMaxSyncBackoff   = 5 * time.Minute
+	DefaultSyncEventBuffer  = 256
+)
+
+type SyncEventType string
+
+const (
+	SyncEventBalance     SyncEventType = "balance"
+	SyncEventTransaction SyncEventType = "transaction"
+	SyncEventError       SyncEventType = "error"
+)
+
+// SyncEvent reports changed balances, a new transaction of the address or a failed refresh
+type SyncEvent struct {
+	Type        SyncEventType     `json:"type"`
+	CoinId      CoinId            `json:"coin_id"`
+	Address     string            `json:"address,omitempty"`
+	Balances    map[string]Amount `json:"balances,omitempty"`
+	Transaction *Transaction      `json:"transaction,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+type WithSyncOption func(*Syncer)
+
+// WithSyncInterval sets how often recently viewed addresses of the coin are refreshed, other
+// addresses are refreshed idle factor times less often
+func WithSyncInterval(coinId CoinId, interval time.Duration) WithSyncOption {
+	return func(s *Syncer) {
+		s.intervals[coinId] = interval
+	}
+}
+
+func WithDefaultSyncInterval(interval time.Duration) WithSyncOption {
+	return func(s *Syncer) {
+		s.defaultInterval = interval
+	}
+}
+
+func WithIdleSyncFactor(factor int) WithSyncOption {
+	return func(s *Syncer) {
+		s.idleFactor = factor
+	}
+}
+
+// WithRecentViewWindow sets how long an address stays prioritized after MarkViewed
+func WithRecentViewWindow(window time.Duration) WithSyncOption {
+	return func(s *Syncer) {
+		s.recentWindow = window
+	}
+}
+
+// WithMaxSyncBackoff limits the delay of exponential backoff after failed refreshes of a coin
+func WithMaxSyncBackoff(backoff time.Duration) WithSyncOption {
+	return func(s *Syncer) {
+		s.maxBackoff = backoff
+	}
+}
+
+func WithSyncEventBuffer(size int) WithSyncOption {
+	return func(s *Syncer) {
+		s.events = make(chan SyncEvent, size)
+	}
+}
+
+// NewSyncer creates scheduler refreshing balances and transactions of tracked addresses of the
+// manager: watch-only accounts, discovered addresses and addresses added with Track
+func NewSyncer(manager *Manager, options ...WithSyncOption) *Syncer {
+	s := &Syncer{
+		manager:         manager,
+		intervals:       make(map[CoinId]time.Duration),
+		defaultInterval: DefaultSyncInterval,
+		idleFactor:      DefaultIdleSyncFactor,
+		recentWindow:    DefaultRecentViewWindow,
+		maxBackoff:      DefaultMaxSyncBackoff,
+		addresses:       make(map[storageKey]*addressSyncState),
+		chains:          make(map[CoinId]*chainSyncState),
+		tracked:         make(map[storageKey]bool),
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	if s.events == nil {
+		s.events = make(chan SyncEvent, DefaultSyncEventBuffer)
+	}
+	return s
+}
+
+type Syncer struct {
+	mux             sync.Mutex
+	manager         *Manager
+	intervals       map[CoinId]time.Duration
+	defaultInterval time.Duration
+	idleFactor      int
+	recentWindow    time.Duration
+	maxBackoff      time.Duration
+	events          chan SyncEvent
+	dropped         uint64
+
+	addresses map[storageKey]*addressSyncState
+	chains    map[CoinId]*chainSyncState
+	tracked   map[storageKey]bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type addressSyncState struct {
+	viewedAt time.Time
+	nextSync time.Time
+	synced   bool
+	balances map[string]Amount
+}
+
+type chainSyncState struct {
+	failures int
+	retryAt  time.Time
+	wake     chan struct{}
+}
+
+// Start runs one sync loop per coin registered in the manager until Stop or ctx cancellation.
+// Coins added to the manager later are synced after restart.
+func (s *Syncer) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.manager.mux.RLock()
+	coinIds := make([]CoinId, 0, len(s.manager.clients))
+	for coinId := range s.manager.clients {
+		coinIds = append(coinIds, coinId)
+	}
+	s.manager.mux.RUnlock()
+	s.mux.Lock()
+	s.cancel = cancel
+	for _, coinId := range coinIds {
+		s.chain(coinId)
+	}
+	s.mux.Unlock()
+	for _, coinId := range coinIds {
+		s.wg.Add(1)
+		go s.run(ctx, coinId)
+	}
+}
+
+// Stop stops sync loops and waits for running refreshes
+func (s *Syncer) Stop() {
+	s.mux.Lock()
+	cancel := s.cancel
+	s.mux.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// Events delivers sync events. Events are dropped when the buffer is full, balances and history
+// are always available from the manager.
+func (s *Syncer) Events() <-chan SyncEvent {
+	return s.events
+}
+
+// Dropped returns number of events dropped because nobody read them
+func (s *Syncer) Dropped() uint64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.dropped
+}
+
+// Track adds address without key or watch-only account to sync, e.g. a key imported by the user
+func (s *Syncer) Track(coinId CoinId, address string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.tracked[storageKey{coinId, normalizeAddress(address)}] = true
+}
+
+// MarkViewed prioritizes the address shown to the user: it is refreshed at once and then with
+// the coin interval during the recent view window
+func (s *Syncer) MarkViewed(coinId CoinId, address string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	key := storageKey{coinId, normalizeAddress(address)}
+	s.tracked[key] = true
+	state := s.address(key)
+	state.viewedAt = time.Now()
+	state.nextSync = time.Time{}
+	select {
+	case s.chain(coinId).wake <- struct{}{}:
+	default:
+	}
+}
+
+// Balances returns balances of the address known from the last sync, stored ones before the
+// first sync after restart
+func (s *Syncer) Balances(coinId CoinId, address string) (map[string]Amount, bool) {
+	s.mux.Lock()
+	state, found := s.addresses[storageKey{coinId, normalizeAddress(address)}]
+	if found && state.synced {
+		defer s.mux.Unlock()
+		return state.balances, true
+	}
+	s.mux.Unlock()
+	balances, err := s.manager.CachedBalances(coinId, address)
+	if err != nil || balances == nil {
+		return nil, false
+	}
+	return balances, true
+}
+
+func (s *Syncer) run(ctx context.Context, coinId CoinId) {
+	defer s.wg.Done()
+	s.mux.Lock()
+	wake := s.chain(coinId).wake
+	s.mux.Unlock()
+	ticker := time.NewTicker(s.interval(coinId))
+	defer ticker.Stop()
+	for {
+		s.syncDue(ctx, coinId, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// syncDue refreshes addresses of the coin due at now, recently viewed ones first. The first
+// failure stops the round and delays the next one with exponential backoff.
+func (s *Syncer) syncDue(ctx context.Context, coinId CoinId, now time.Time) {
+	client, err := s.manager.client(coinId)
+	if err != nil {
+		return
+	}
+	s.mux.Lock()
+	chain := s.chain(coinId)
+	if now.Before(chain.retryAt) {
+		s.mux.Unlock()
+		return
+	}
+	s.mux.Unlock()
+	due, err := s.dueAddresses(coinId, now)
+	if err != nil {
+		s.emit(SyncEvent{Type: SyncEventError, CoinId: coinId, Error: err.Error()})
+		return
+	}
+	for _, address := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		if err = s.syncAddress(client, coinId, address, now); err != nil {
+			s.mux.Lock()
+			chain.failures++
+			chain.retryAt = now.Add(s.backoff(coinId, chain.failures))
+			s.mux.Unlock()
+			s.emit(SyncEvent{Type: SyncEventError, CoinId: coinId, Address: address, Error: err.Error()})
+			return
+		}
+		s.mux.Lock()
+		chain.failures = 0
+		state := s.address(storageKey{coinId, address})
+		if now.Sub(state.viewedAt) < s.recentWindow {
+			state.nextSync = now.Add(s.interval(coinId))
+		} else {
+			state.nextSync = now.Add(s.interval(coinId) * time.Duration(s.idleFactor))
+		}
+		s.mux.Unlock()
+	}
+}
+
+func (s *Syncer) dueAddresses(coinId CoinId, now time.Time) ([]string, error) {
+	addresses, err := s.manager.TrackedAddresses(coinId)
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for key := range s.tracked {
+		if key.coinId == coinId {
+			addresses = append(addresses, key.address)
+		}
+	}
+	seen := make(map[string]bool, len(addresses))
+	var due []string
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		state := s.address(storageKey{coinId, address})
+		if state.nextSync.IsZero() {
+			state.nextSync = s.restoredNextSync(coinId, address)
+		}
+		if !now.Before(state.nextSync) {
+			due = append(due, address)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := s.addresses[storageKey{coinId, due[i]}], s.addresses[storageKey{coinId, due[j]}]
+		if !a.viewedAt.Equal(b.viewedAt) {
+			return a.viewedAt.After(b.viewedAt)
+		}
+		return a.nextSync.Before(b.nextSync)
+	})
+	return due, nil
+}
+
+// restoredNextSync schedules address synced before restart by its stored cursor, so restarts
+// don't refresh every address at once. Viewed addresses are always due.
+func (s *Syncer) restoredNextSync(coinId CoinId, address string) time.Time {
+	state := s.address(storageKey{coinId, address})
+	if !state.viewedAt.IsZero() {
+		return time.Time{}
+	}
+	cursor, err := s.manager.storage.SyncCursor(coinId, address)
+	if err != nil || cursor == "" {
+		return time.Time{}
+	}
+	syncedAt, err := time.Parse(time.RFC3339, cursor)
+	if err != nil {
+		return time.Time{}
+	}
+	return syncedAt.Add(s.interval(coinId) * time.Duration(s.idleFactor))
+}
+
+// syncAddress fetches balances and history, stores history and emits changes. Transactions of
+// addresses never synced before are stored silently, they are not new payments.
+func (s *Syncer) syncAddress(client Blockchain, coinId CoinId, address string, now time.Time) error {
+	balances, err := client.GetAddressBalance(address)
+	if err != nil {
+		return err
+	}
+	transactions, err := client.GetAddressTransactions(address)
+	if err != nil {
+		return err
+	}
+	storage := s.manager.storage
+	cursor, err := storage.SyncCursor(coinId, address)
+	if err != nil {
+		return err
+	}
+	cached, err := storage.Transactions(coinId, address)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(cached))
+	for _, transaction := range cached {
+		known[transaction.Hash] = true
+	}
+	if err = s.manager.detectPoisoning(coinId, address, transactions); err != nil {
+		return err
+	}
+	if err = s.manager.dropExpiredPending(coinId, address, transactions, now); err != nil {
+		return err
+	}
+	if err = storage.SaveTransactions(coinId, address, transactions); err != nil {
+		return err
+	}
+	if err = storage.SetBalances(coinId, address, balances); err != nil {
+		return err
+	}
+	if err = storage.SetSyncCursor(coinId, address, now.UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if cursor != "" {
+		for _, transaction := range transactions {
+			// poisoning spam is not announced as a payment
+			if !known[transaction.Hash] && transaction.Poisoning == "" {
+				s.emit(SyncEvent{Type: SyncEventTransaction, CoinId: coinId, Address: address, Transaction: transaction})
+			}
+		}
+	}
+	s.mux.Lock()
+	state := s.address(storageKey{coinId, address})
+	changed := !state.synced || !equalBalances(state.balances, balances)
+	state.synced = true
+	state.balances = balances
+	s.mux.Unlock()
+	if changed {
+		s.emit(SyncEvent{Type: SyncEventBalance, CoinId: coinId, Address: address, Balances: balances})
+	}
+	return nil
+}
+
+func (s *Syncer) emit(event SyncEvent) {
+	select {
+	case s.events <- event:
+	default:
+		s.mux.Lock()
+		s.dropped++
+		s.mux.Unlock()
+	}
+}
+
+func (s *Syncer) interval(coinId CoinId) time.Duration {
+	if interval, ok := s.intervals[coinId]; ok && interval > 0 {
+		return interval
+	}
+	return s.defaultInterval
+}
+
+// backoff doubles the coin interval with every failure in a row up to max backoff
+func (s *Syncer) backoff(coinId CoinId, failures int) time.Duration {
+	backoff := s.interval(coinId)
+	for i := 1; i < failures && backoff < s.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.maxBackoff {
+		return s.maxBackoff
+	}
+	return backoff
+}
+
+func (s *Syncer) address(key storageKey) *addressSyncState {
+	state, found := s.addresses[key]
+	if !found {
+		state = &addressSyncState{}
+		s.addresses[key] = state
+	}
+	return state
+}
+
+func (s *Syncer) chain(coinId CoinId) *chainSyncState {
+	chain, found := s.chains[coinId]
+	if !found {
+		chain = &chainSyncState{wake: make(chan struct{}, 1)}
+		s.chains[coinId] = chain
+	}
+	return chain
+}
+
+func equalBalances(a, b map[string]Amount) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for token, amount := range a {
+		other, found := b[token]
+		if !found || !amount.Equal(other) {
+			return false
+		}
+	}
+	return true
+}
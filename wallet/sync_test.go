@@ -0,0 +1,194 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/mcmx73/easytron/keys"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncTestClient struct {
+	mux          sync.Mutex
+	calls        int
+	err          error
+	balances     map[string]map[string]Amount
+	transactions map[string][]*Transaction
+}
+
+func newSyncTestClient() *syncTestClient {
+	return &syncTestClient{
+		balances:     make(map[string]map[string]Amount),
+		transactions: make(map[string][]*Transaction),
+	}
+}
+
+func (c *syncTestClient) GetCoins() []*CoinDescription {
+	return []*CoinDescription{{Id: "trx", Title: "Tron", Symbol: "TRX", Decimals: 6, Coin: true}}
+}
+
+func (c *syncTestClient) CreateNewAddress(key *keys.Key) (string, error) {
+	return key.TronAddress()
+}
+
+func (c *syncTestClient) GetAddressBalance(address string) (map[string]Amount, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.calls++
+	return c.balances[address], c.err
+}
+
+func (c *syncTestClient) GetAddressTransactions(address string) ([]*Transaction, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.transactions[address], c.err
+}
+
+func (c *syncTestClient) PrepareTransfer(request *TransferRequest) (*PreparedTransfer, error) {
+	return nil, errTransferNotSupported
+}
+
+func (c *syncTestClient) SignTransfer(prepared *PreparedTransfer, key *keys.Key) (*SignedTransfer, error) {
+	return nil, errTransferNotSupported
+}
+
+func (c *syncTestClient) BroadcastTransfer(signed *SignedTransfer) (string, error) {
+	return "", errTransferNotSupported
+}
+
+func (c *syncTestClient) receive(address, hash string, sun uint64) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.transactions[address] = append(c.transactions[address], &Transaction{Hash: hash, To: address, Incoming: true, Amount: NewAmountFromUint64(sun, 6)})
+	c.balances[address] = map[string]Amount{"trx": NewAmountFromUint64(sun, 6)}
+}
+
+const (
+	syncTestAddress = "TPL66VK2gCXNCD7EJg9pgJRfqcRazjhUZY"
+	syncTestOther   = "TJRabPrwbZy45sbavfcjinPJC18kjpRTv8"
+)
+
+func newSyncTest(t *testing.T, options ...WithSyncOption) (*Syncer, *syncTestClient) {
+	client := newSyncTestClient()
+	m := NewManager()
+	m.AddCoin(client)
+	for _, address := range []string{syncTestAddress, syncTestOther} {
+		if _, err := m.AddWatchOnlyAddress("trx", address, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewSyncer(m, options...), client
+}
+
+func drainEvents(s *Syncer) []SyncEvent {
+	var events []SyncEvent
+	for {
+		select {
+		case event := <-s.Events():
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestSyncNewTransactions(t *testing.T) {
+	s, client := newSyncTest(t, WithDefaultSyncInterval(time.Second), WithIdleSyncFactor(10))
+	client.receive(syncTestAddress, "history", 1000000)
+	now := time.Now()
+	s.syncDue(context.Background(), "trx", now)
+	for _, event := range drainEvents(s) {
+		if event.Type == SyncEventTransaction {
+			t.Errorf("history of never synced address reported as new: %+v", event)
+		}
+	}
+	client.receive(syncTestAddress, "payment", 2500000)
+	s.syncDue(context.Background(), "trx", now.Add(time.Second))
+	if events := drainEvents(s); len(events) != 0 {
+		t.Errorf("events %+v before idle interval", events)
+	}
+	s.syncDue(context.Background(), "trx", now.Add(10*time.Second))
+	events := drainEvents(s)
+	if len(events) != 2 || events[0].Transaction == nil || events[0].Transaction.Hash != "payment" ||
+		events[1].Type != SyncEventBalance || events[1].Balances["trx"].String() != "2.5" {
+		t.Fatalf("events %+v", events)
+	}
+	if balances, ok := s.Balances("trx", syncTestAddress); !ok || balances["trx"].String() != "2.5" {
+		t.Errorf("balances %v", balances)
+	}
+	if cached, _ := s.manager.CachedTransactions("trx", syncTestAddress); len(cached) != 2 {
+		t.Errorf("cached transactions %v", cached)
+	}
+	// after restart stored balances are served before the first sync
+	restarted := NewSyncer(s.manager)
+	if balances, ok := restarted.Balances("trx", syncTestAddress); !ok || balances["trx"].String() != "2.5" {
+		t.Errorf("balances %v after restart", balances)
+	}
+	if balances, ok := restarted.Balances("trx", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"); ok {
+		t.Errorf("balances %v of never synced address", balances)
+	}
+}
+
+func TestSyncBackoff(t *testing.T) {
+	s, client := newSyncTest(t, WithDefaultSyncInterval(time.Second), WithMaxSyncBackoff(3*time.Second))
+	client.err = errors.New("429 too many requests")
+	now := time.Now()
+	s.syncDue(context.Background(), "trx", now)
+	if client.calls != 1 {
+		t.Fatalf("%d calls, the round must stop at the first failure", client.calls)
+	}
+	if events := drainEvents(s); len(events) != 1 || events[0].Type != SyncEventError {
+		t.Errorf("events %+v", events)
+	}
+	s.syncDue(context.Background(), "trx", now.Add(500*time.Millisecond))
+	if client.calls != 1 {
+		t.Errorf("%d calls during backoff", client.calls)
+	}
+	s.syncDue(context.Background(), "trx", now.Add(time.Second))
+	if client.calls != 2 || s.chains["trx"].retryAt != now.Add(3*time.Second) {
+		t.Errorf("%d calls, retry at %v", client.calls, s.chains["trx"].retryAt.Sub(now))
+	}
+	client.err = nil
+	s.syncDue(context.Background(), "trx", now.Add(3*time.Second))
+	if client.calls != 4 || s.chains["trx"].failures != 0 {
+		t.Errorf("%d calls, %d failures after recovery", client.calls, s.chains["trx"].failures)
+	}
+}
+
+func TestSyncViewedFirst(t *testing.T) {
+	s, client := newSyncTest(t, WithDefaultSyncInterval(time.Second), WithIdleSyncFactor(10))
+	now := time.Now()
+	s.syncDue(context.Background(), "trx", now)
+	s.MarkViewed("trx", syncTestOther)
+	due, err := s.dueAddresses("trx", now.Add(time.Second))
+	if err != nil || len(due) != 1 || due[0] != syncTestOther {
+		t.Fatalf("due %v, %v", due, err)
+	}
+	s.syncDue(context.Background(), "trx", now.Add(time.Second))
+	client.calls = 0
+	s.syncDue(context.Background(), "trx", now.Add(2*time.Second))
+	if client.calls != 1 {
+		t.Errorf("%d calls, viewed address must be refreshed with the coin interval", client.calls)
+	}
+}
+
+func TestSyncerRun(t *testing.T) {
+	s, client := newSyncTest(t, WithDefaultSyncInterval(10*time.Millisecond), WithIdleSyncFactor(1))
+	s.Start(context.Background())
+	defer s.Stop()
+	time.Sleep(30 * time.Millisecond)
+	client.receive(syncTestOther, "payment", 1)
+	s.MarkViewed("trx", syncTestOther)
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-s.Events():
+			if event.Type == SyncEventTransaction && event.Transaction.Hash == "payment" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("payment is not reported")
+		}
+	}
+}
@@ -14,4 +14,24 @@ type Transaction struct {
 	To            string    `json:"to"`
 	Amount        Amount    `json:"amount"`
 	Fee           Amount    `json:"fee"`
+	// Initiator is the address which signed the transaction when it is not From, e.g. caller
+	// of TRC20 transferFrom, empty when the chain client does not tell
+	Initiator string `json:"initiator,omitempty"`
+	// Poisoning is set when the transaction looks like address poisoning spam, see WithoutPoisoning
+	Poisoning PoisoningReason `json:"poisoning,omitempty"`
+	// Pending is set on transfers recorded by ConfirmSend until the chain history returns them
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Counterparty returns the other side of the transfer, empty for transfers between own addresses
+func (t *Transaction) Counterparty() string {
+	switch {
+	case t.Incoming && t.Outgoing:
+		return ""
+	case t.Incoming:
+		return t.From
+	case t.Outgoing:
+		return t.To
+	}
+	return ""
 }
@@ -0,0 +1,211 @@
+package wallet
+
+import (
+	"fmt"
+	"github.com/mcmx73/easytron/keys"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Account is an address of the wallet. Watch-only accounts have no private key on this machine:
+// balances and history are available, signing is refused with ErrWatchOnly.
+type Account struct {
+	CoinId    CoinId `json:"coin_id"`
+	Address   string `json:"address"`
+	Label     string `json:"label,omitempty"`
+	WatchOnly bool   `json:"watch_only"`
+	// ExtendedKey is xpub the address is derived from and Path is derivation path below it
+	ExtendedKey string `json:"extended_key,omitempty"`
+	Path        string `json:"path,omitempty"`
+}
+
+// AddWatchOnlyAddress starts watching bare Tron or Ethereum address of the coin
+func (m *Manager) AddWatchOnlyAddress(coinId CoinId, address, label string) (*Account, error) {
+	if err := keys.ValidateAddress(address); err != nil {
+		return nil, err
+	}
+	if _, err := m.client(coinId); err != nil {
+		return nil, err
+	}
+	account := &Account{CoinId: coinId, Address: address, Label: label, WatchOnly: true}
+	if err := m.addWatchOnly(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// AddWatchOnlyExtendedKey watches addresses derived from extended public key with the template,
+// AccountExternalPathTemplate when it is empty. Used addresses are found with the gap limit like
+// DiscoverAccounts does, the first address is watched even when no address is used yet.
+func (m *Manager) AddWatchOnlyExtendedKey(coinId CoinId, extendedKey string, template keys.PathTemplate, label string) ([]*Account, error) {
+	xpub, err := keys.ParseExtendedKey(extendedKey)
+	if err != nil {
+		return nil, err
+	}
+	if xpub.IsPrivate() {
+		return nil, ErrPrivateExtendedKey
+	}
+	client, err := m.client(coinId)
+	if err != nil {
+		return nil, err
+	}
+	if template == "" {
+		template = keys.AccountExternalPathTemplate
+	}
+	if err = template.Validate(); err != nil {
+		return nil, err
+	}
+	if template.HasAccount() {
+		return nil, fmt.Errorf("%w: %s", ErrAccountInTemplate, template)
+	}
+	m.mux.RLock()
+	gapLimit := m.gapLimit
+	m.mux.RUnlock()
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+	scanner := &addressScanner{
+		manager:  m,
+		client:   client,
+		coinId:   coinId,
+		master:   xpub,
+		template: template,
+		gapLimit: gapLimit,
+	}
+	used, err := scanner.scan(func(i uint32) (uint32, uint32) { return 0, i })
+	if err != nil {
+		return nil, err
+	}
+	if len(used) == 0 {
+		first, _, err := scanner.derive(0, 0)
+		if err != nil {
+			return nil, err
+		}
+		used = append(used, first)
+	}
+	accounts := make([]*Account, 0, len(used))
+	for _, discovered := range used {
+		account := &Account{
+			CoinId:      coinId,
+			Address:     discovered.Address,
+			Label:       label,
+			WatchOnly:   true,
+			ExtendedKey: extendedKey,
+			Path:        discovered.Path,
+		}
+		if err = m.addWatchOnly(account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// RemoveWatchOnly stops watching the address
+func (m *Manager) RemoveWatchOnly(address string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	account, found := m.watchOnly[normalizeAddress(address)]
+	if !found {
+		return nil
+	}
+	if err := m.storage.DeleteAccount(account.CoinId, account.Address); err != nil {
+		return err
+	}
+	delete(m.watchOnly, normalizeAddress(address))
+	return nil
+}
+
+// IsWatchOnly reports whether the address is watched without a key
+func (m *Manager) IsWatchOnly(address string) bool {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	_, found := m.watchOnly[normalizeAddress(address)]
+	return found
+}
+
+// WatchOnlyAccounts returns watched addresses sorted by coin and address
+func (m *Manager) WatchOnlyAccounts() []*Account {
+	m.mux.RLock()
+	accounts := make([]*Account, 0, len(m.watchOnly))
+	for _, account := range m.watchOnly {
+		accounts = append(accounts, account)
+	}
+	m.mux.RUnlock()
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].CoinId != accounts[j].CoinId {
+			return accounts[i].CoinId < accounts[j].CoinId
+		}
+		return accounts[i].Address < accounts[j].Address
+	})
+	return accounts
+}
+
+// GetAddressBalance fetches balances of any address of the coin, including watch-only ones,
+// and updates the balance cache
+func (m *Manager) GetAddressBalance(coinId CoinId, address string) (map[string]Amount, error) {
+	client, err := m.client(coinId)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := client.GetAddressBalance(address)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.storage.SetBalances(coinId, normalizeAddress(address), balances); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// GetAddressTransactions fetches history of any address of the coin, including watch-only ones,
+// and updates the transaction cache
+func (m *Manager) GetAddressTransactions(coinId CoinId, address string) ([]*Transaction, error) {
+	client, err := m.client(coinId)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := client.GetAddressTransactions(address)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.detectPoisoning(coinId, address, transactions); err != nil {
+		return nil, err
+	}
+	if err = m.dropExpiredPending(coinId, normalizeAddress(address), transactions, time.Now()); err != nil {
+		return nil, err
+	}
+	if err = m.storage.SaveTransactions(coinId, normalizeAddress(address), transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (m *Manager) addWatchOnly(account *Account) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if err := m.storage.SaveAccount(account); err != nil {
+		return err
+	}
+	m.watchOnly[normalizeAddress(account.Address)] = account
+	return nil
+}
+
+func (m *Manager) client(coinId CoinId) (Blockchain, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	client, ok := m.clients[coinId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCoinNotFound, coinId)
+	}
+	return client, nil
+}
+
+// normalizeAddress lowercases hex addresses, base58 addresses are case sensitive
+func normalizeAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/mcmx73/easytron/keys"
+	"strings"
+	"testing"
+)
+
+func TestWatchOnlyExtendedKey(t *testing.T) {
+	master, err := keys.NewMasterKeyFromMnemonic(strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := master.Derive(keys.HardenedKeyStart+44, keys.HardenedKeyStart+60, keys.HardenedKeyStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressAt := func(index uint32) string {
+		path, _ := keys.EthereumBIP44PathTemplate.Path(0, index)
+		extendedKey, _ := master.DerivePath(path)
+		key, _ := extendedKey.Key()
+		address, _ := key.EthereumAddress()
+		return address
+	}
+	client := &discoveryTestClient{
+		derived: make(map[string]bool),
+		used:    map[string]bool{addressAt(1): true, addressAt(3): true},
+	}
+	m := NewManager(WithKeyManager(keys.NewManager()), WithGapLimit(5))
+	m.AddCoin(client)
+
+	if _, err = m.AddWatchOnlyExtendedKey("eth", account.String(), "", "cold"); !errors.Is(err, ErrPrivateExtendedKey) {
+		t.Errorf("expected ErrPrivateExtendedKey, got %v", err)
+	}
+	watched, err := m.AddWatchOnlyExtendedKey("eth", account.Neuter().String(), "", "cold")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watched) != 2 || watched[0].Path != "m/0/1" || watched[1].Address != addressAt(3) || !watched[1].WatchOnly {
+		t.Fatalf("watched %+v", watched)
+	}
+	if _, err = m.GetKey(addressAt(1)); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("expected ErrWatchOnly, got %v", err)
+	}
+	if _, err = m.GetKey(strings.ToLower(addressAt(3))); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("expected ErrWatchOnly for lowercase address, got %v", err)
+	}
+	if _, err = m.GetKey(addressAt(0)); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	if transactions, err := m.GetAddressTransactions("eth", addressAt(3)); err != nil || len(transactions) != 1 {
+		t.Errorf("transactions %v, %v", transactions, err)
+	}
+}
+
+func TestWatchOnlyAddress(t *testing.T) {
+	m := NewManager()
+	m.AddCoin(&discoveryTestClient{})
+	if _, err := m.AddWatchOnlyAddress("eth", "0x1234", ""); !errors.Is(err, keys.ErrInvalidAddress) {
+		t.Errorf("expected ErrInvalidAddress, got %v", err)
+	}
+	if _, err := m.AddWatchOnlyAddress("trx", "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", ""); !errors.Is(err, ErrCoinNotFound) {
+		t.Errorf("expected ErrCoinNotFound, got %v", err)
+	}
+	if _, err := m.AddWatchOnlyAddress("eth", "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", "treasury"); err != nil {
+		t.Fatal(err)
+	}
+	accounts := m.WatchOnlyAccounts()
+	if len(accounts) != 1 || accounts[0].Label != "treasury" || !accounts[0].WatchOnly {
+		t.Errorf("accounts %+v", accounts)
+	}
+	if _, err := m.GetKey("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"); !errors.Is(err, ErrWatchOnly) {
+		t.Errorf("expected ErrWatchOnly, got %v", err)
+	}
+	m.RemoveWatchOnly("0x9858efFD232B4033E47d90003D41EC34EcaEda94")
+	if m.IsWatchOnly("0x9858EfFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Error("address is still watched")
+	}
+}
+
+func TestWatchOnlyPersisted(t *testing.T) {
+	storage := NewMemoryStorage()
+	m := NewManager(WithStorage(storage))
+	m.AddCoin(&discoveryTestClient{})
+	if _, err := m.AddWatchOnlyAddress("eth", "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", "cold"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetLabel("0x9858EfFD232B4033E47d90003D41EC34EcaEda94", "savings"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewManager(WithStorage(storage))
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.IsWatchOnly("0x9858efFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Error("watch-only address is not restored")
+	}
+	if label := restarted.Label("0x9858efFD232B4033E47d90003D41EC34EcaEda94"); label != "savings" {
+		t.Errorf("label %q", label)
+	}
+	if err := restarted.RemoveWatchOnly("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"); err != nil {
+		t.Fatal(err)
+	}
+	if accounts, _ := storage.Accounts(); len(accounts) != 0 {
+		t.Errorf("removed account is still stored: %v", accounts)
+	}
+}